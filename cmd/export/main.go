@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"go-kafka-postgres/internal/config"
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+)
+
+func main() {
+	output := flag.String("o", "-", "output file path (\"-\" for stdout)")
+	flag.Parse()
+
+	if err := logger.Init(os.Getenv("LOG_LEVEL")); err != nil {
+		panic("Failed to init logger: " + err.Error())
+	}
+	defer logger.Sync()
+
+	connString := config.GetString("POSTGRES_CONN_STRING", config.DefaultConnString)
+	database, err := db.New(connString)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	defer database.Close()
+
+	orders, err := database.GetAllOrders(context.Background())
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logger.Fatalf("Error creating output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	w := bufio.NewWriter(out)
+	if err := writeOrders(w, orders); err != nil {
+		logger.Fatalf("Error writing orders: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		logger.Fatalf("Error writing orders: %v", err)
+	}
+
+	logger.Infof("Exported %d orders", len(orders))
+}
+
+// writeOrders пишет заказы JSON-массивом, кодируя каждый заказ отдельно
+func writeOrders(w io.Writer, orders []*model.Order) error {
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return err
+	}
+
+	for i, order := range orders {
+		data, err := json.MarshalIndent(order, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "  "); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		sep := ",\n"
+		if i == len(orders)-1 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"go-kafka-postgres/internal/config"
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/validator"
+)
+
+// importResult итог загрузки заказов
+type importResult struct {
+	// Inserted заказы, записанные в БД
+	Inserted int
+	// Existing заказы, которые уже были в БД и остались без изменений
+	Existing int
+	// Skipped заказы, которые не удалось вставить
+	Skipped int
+	// Invalid заказы, не прошедшие валидацию
+	Invalid int
+}
+
+func main() {
+	input := flag.String("i", "orders.json", "input file with a JSON array of orders")
+	batchSize := flag.Int("batch", 100, "number of orders inserted in one transaction")
+	flag.Parse()
+
+	if err := logger.Init(os.Getenv("LOG_LEVEL")); err != nil {
+		panic("Failed to init logger: " + err.Error())
+	}
+	defer logger.Sync()
+
+	orders, err := loadOrders(*input)
+	if err != nil {
+		logger.Fatalf("Error loading orders: %v", err)
+	}
+
+	connString := config.GetString("POSTGRES_CONN_STRING", config.DefaultConnString)
+	validatorOpts, err := config.ValidationOptions()
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	database, err := db.New(connString)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	result := importOrders(database, validator.New(validatorOpts), orders, *batchSize)
+	database.Close()
+
+	logger.Infof("Import finished: inserted %d, already present %d, skipped %d, invalid %d",
+		result.Inserted, result.Existing, result.Skipped, result.Invalid)
+
+	if result.Invalid > 0 {
+		logger.Sync()
+		os.Exit(1)
+	}
+}
+
+// loadOrders читает JSON-массив заказов из файла; элемент null считается ошибкой
+func loadOrders(path string) ([]*model.Order, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var orders []*model.Order
+	if err := json.NewDecoder(file).Decode(&orders); err != nil {
+		return nil, err
+	}
+	for i, order := range orders {
+		if order == nil {
+			return nil, fmt.Errorf("order #%d is null", i+1)
+		}
+	}
+	return orders, nil
+}
+
+// importOrders валидирует заказы и вставляет валидные пачками по batchSize,
+// пропуская заказы, которые не удалось вставить
+func importOrders(database db.DatabaseInterface, v *validator.Validator, orders []*model.Order, batchSize int) importResult {
+	var result importResult
+
+	valid := make([]*model.Order, 0, len(orders))
+	for i, order := range orders {
+		if err := v.Validate(order); err != nil {
+			logger.Errorf("Invalid order #%d (%s): %v", i+1, order.OrderUID, err)
+			result.Invalid++
+			continue
+		}
+		valid = append(valid, order)
+	}
+
+	inserted, err := database.InsertOrders(context.Background(), valid, db.BatchOptions{Size: batchSize, SkipFailed: true})
+	var batchErr *db.BatchError
+	switch {
+	case err == nil:
+	case errors.As(err, &batchErr):
+		for uid, orderErr := range batchErr.Failed {
+			logger.Errorf("Failed to insert order %s: %v", uid, orderErr)
+		}
+		result.Skipped = len(batchErr.Failed)
+	default:
+		// Зафиксированы только пачки до ошибки; остальные заказы считаются пропущенными
+		logger.Errorf("Failed to insert orders: %v", err)
+		result.Inserted = inserted
+		result.Skipped = len(valid) - inserted
+		return result
+	}
+
+	result.Inserted = inserted
+	result.Existing = len(valid) - result.Skipped - inserted
+	return result
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+	"go-kafka-postgres/internal/validator"
+)
+
+// fakeDB принимает пачки заказов; заказы из fail пропускаются с ошибкой,
+// заказы из existing уже есть в БД и не записываются
+type fakeDB struct {
+	db.DatabaseInterface
+	fail     map[string]bool
+	existing map[string]bool
+	inserted []string
+	opts     db.BatchOptions
+}
+
+func (f *fakeDB) InsertOrders(_ context.Context, orders []*model.Order, opts db.BatchOptions) (int, error) {
+	f.opts = opts
+	failed := make(map[string]error)
+	written := 0
+	for _, order := range orders {
+		switch {
+		case f.fail[order.OrderUID]:
+			failed[order.OrderUID] = errors.New("insert order error")
+		case !f.existing[order.OrderUID]:
+			f.inserted = append(f.inserted, order.OrderUID)
+			written++
+		}
+	}
+	if len(failed) > 0 {
+		return written, &db.BatchError{Failed: failed}
+	}
+	return written, nil
+}
+
+// writeFixture записывает заказы JSON-массивом во временный файл
+func writeFixture(t *testing.T, orders ...*model.Order) string {
+	t.Helper()
+	data, err := json.Marshal(orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "orders.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportFixture(t *testing.T) {
+	invalid := testutil.Order("invalid")
+	invalid.TrackNumber = ""
+	path := writeFixture(t, testutil.Order("first"), invalid, testutil.Order("broken"), testutil.Order("present"),
+		testutil.Order("second"))
+
+	orders, err := loadOrders(path)
+	if err != nil {
+		t.Fatalf("loadOrders: %v", err)
+	}
+	if len(orders) != 5 {
+		t.Fatalf("loaded %d orders, want 5", len(orders))
+	}
+
+	database := &fakeDB{fail: map[string]bool{"broken": true}, existing: map[string]bool{"present": true}}
+	result := importOrders(database, validator.New(validator.Options{}), orders, 2)
+
+	want := importResult{Inserted: 2, Existing: 1, Skipped: 1, Invalid: 1}
+	if result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+	if !slices.Equal(database.inserted, []string{"first", "second"}) {
+		t.Errorf("inserted = %v, want [first second]", database.inserted)
+	}
+	if database.opts != (db.BatchOptions{Size: 2, SkipFailed: true}) {
+		t.Errorf("batch options = %+v, want size 2 with SkipFailed", database.opts)
+	}
+}
+
+func TestLoadOrdersErrors(t *testing.T) {
+	if _, err := loadOrders(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loadOrders of a missing file succeeded, want error")
+	}
+
+	path := filepath.Join(t.TempDir(), "orders.json")
+	if err := os.WriteFile(path, []byte(`{"order_uid": "not an array"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadOrders(path); err == nil {
+		t.Error("loadOrders of a non-array succeeded, want error")
+	}
+}
+
+func TestLoadOrdersRejectsNull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	if err := os.WriteFile(path, []byte(`[{"order_uid": "first"}, null]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadOrders(path)
+	if err == nil {
+		t.Fatal("loadOrders with a null entry succeeded, want error")
+	}
+	if !strings.Contains(err.Error(), "#2") {
+		t.Errorf("error = %q, want it to name order #2", err)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go-kafka-postgres/internal/config"
+	"go-kafka-postgres/internal/consumer"
+	"go-kafka-postgres/internal/kafka"
+	"go-kafka-postgres/internal/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// Способы выбора нового смещения
+const (
+	targetOldest    = "oldest"
+	targetNewest    = "newest"
+	targetOffset    = "offset"
+	targetTimestamp = "timestamp"
+)
+
+// partitionBounds границы партиции, по которым выбирается новое смещение
+type partitionBounds struct {
+	// Oldest смещение первого доступного сообщения
+	Oldest int64
+	// Newest смещение, которое получит следующее сообщение
+	Newest int64
+	// AtTime смещение первого сообщения не раньше заданного времени;
+	// -1, если таких сообщений нет
+	AtTime int64
+}
+
+func main() {
+	brokersFlag := flag.String("brokers", "", "comma-separated Kafka broker addresses (overrides KAFKA_BROKERS)")
+	topicFlag := flag.String("topic", "", "Kafka topic (overrides KAFKA_TOPIC)")
+	group := flag.String("group", consumer.GroupID, "consumer group whose offsets are reset")
+	to := flag.String("to", "", "new position: oldest, newest, offset or timestamp")
+	offset := flag.Int64("offset", -1, "offset for -to offset, applied to every partition")
+	timestamp := flag.String("timestamp", "", "RFC3339 time for -to timestamp")
+	dryRun := flag.Bool("dry-run", false, "print the planned offsets without committing them")
+	flag.Parse()
+
+	if err := logger.Init(os.Getenv("LOG_LEVEL")); err != nil {
+		panic("Failed to init logger: " + err.Error())
+	}
+	defer logger.Sync()
+
+	var at time.Time
+	switch *to {
+	case targetOldest, targetNewest:
+	case targetOffset:
+		if *offset < 0 {
+			logger.Fatalf("Invalid -offset: %d", *offset)
+		}
+	case targetTimestamp:
+		var err error
+		at, err = time.Parse(time.RFC3339, *timestamp)
+		if err != nil {
+			logger.Fatalf("Invalid -timestamp: %v", err)
+		}
+	default:
+		logger.Fatalf("Invalid -to %q: expected oldest, newest, offset or timestamp", *to)
+	}
+
+	brokers := config.GetStringSlice("KAFKA_BROKERS", config.DefaultBrokers)
+	if *brokersFlag != "" {
+		brokers = config.SplitList(*brokersFlag)
+	}
+	topic := *topicFlag
+	if topic == "" {
+		topic = config.GetString("KAFKA_TOPIC", "orders")
+	}
+
+	saramaConfig, err := kafka.NewConfig(config.KafkaSecurity())
+	if err != nil {
+		logger.Fatalf("Error creating Kafka config: %v", err)
+	}
+	// Смещения фиксируются явно через Commit
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = false
+
+	client, err := sarama.NewClient(brokers, saramaConfig)
+	if err != nil {
+		logger.Fatalf("Error connecting to Kafka: %v", err)
+	}
+	defer client.Close()
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		logger.Fatalf("Error creating cluster admin: %v", err)
+	}
+
+	// Смещения активной группы перезапишутся ее участниками при следующем коммите,
+	// поэтому сброс допускается только для остановленной группы
+	if err := ensureGroupEmpty(admin, *group); err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	partitions, err := client.Partitions(topic)
+	if err != nil {
+		logger.Fatalf("Error listing partitions of %s: %v", topic, err)
+	}
+	committed, err := admin.ListConsumerGroupOffsets(*group, map[string][]int32{topic: partitions})
+	if err != nil {
+		logger.Fatalf("Error fetching committed offsets: %v", err)
+	}
+
+	targets := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		bounds, err := fetchBounds(client, topic, partition, *to, at)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		target, err := resolveTarget(*to, *offset, bounds)
+		if err != nil {
+			logger.Fatalf("Partition %d: %v", partition, err)
+		}
+		targets[partition] = target
+
+		current := int64(-1)
+		if block := committed.GetBlock(topic, partition); block != nil {
+			current = block.Offset
+		}
+		fmt.Printf("%s/%d: committed %s -> %d (available %d..%d)\n",
+			topic, partition, formatOffset(current), target, bounds.Oldest, bounds.Newest)
+	}
+
+	if *dryRun {
+		logger.Infof("Dry run: offsets of group %s were not changed", *group)
+		return
+	}
+
+	if err := commitOffsets(client, *group, topic, targets); err != nil {
+		logger.Fatal(err.Error())
+	}
+	// Commit не возвращает ошибок, поэтому результат проверяется повторным чтением
+	if err := verifyOffsets(admin, *group, topic, targets); err != nil {
+		logger.Fatal(err.Error())
+	}
+	logger.Infof("Reset offsets of group %s for %d partitions of %s", *group, len(targets), topic)
+}
+
+// ensureGroupEmpty проверяет, что у группы нет активных участников
+func ensureGroupEmpty(admin sarama.ClusterAdmin, group string) error {
+	groups, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return fmt.Errorf("describe consumer group error: %w", err)
+	}
+	if len(groups) != 1 {
+		return fmt.Errorf("describe consumer group: unexpected response for %s", group)
+	}
+	description := groups[0]
+	if !errors.Is(description.Err, sarama.ErrNoError) {
+		return fmt.Errorf("describe consumer group error: %w", description.Err)
+	}
+	// Dead — группа без участников и сохраненного состояния (например, еще не создана)
+	if len(description.Members) > 0 || (description.State != "Empty" && description.State != "Dead") {
+		return fmt.Errorf("consumer group %s is %s with %d active members: stop the consumers first",
+			group, description.State, len(description.Members))
+	}
+	return nil
+}
+
+// fetchBounds запрашивает у брокера границы партиции, нужные для выбора смещения
+func fetchBounds(client sarama.Client, topic string, partition int32, to string, at time.Time) (partitionBounds, error) {
+	bounds := partitionBounds{AtTime: -1}
+	var err error
+	if bounds.Oldest, err = client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+		return bounds, fmt.Errorf("get oldest offset of partition %d error: %w", partition, err)
+	}
+	if bounds.Newest, err = client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+		return bounds, fmt.Errorf("get newest offset of partition %d error: %w", partition, err)
+	}
+	if to == targetTimestamp {
+		if bounds.AtTime, err = client.GetOffset(topic, partition, at.UnixMilli()); err != nil {
+			return bounds, fmt.Errorf("get offset by time of partition %d error: %w", partition, err)
+		}
+	}
+	return bounds, nil
+}
+
+// resolveTarget выбирает новое смещение партиции. Явное смещение должно лежать
+// в пределах доступных сообщений; если после заданного времени сообщений нет,
+// смещение ставится в конец партиции.
+func resolveTarget(to string, offset int64, bounds partitionBounds) (int64, error) {
+	switch to {
+	case targetOldest:
+		return bounds.Oldest, nil
+	case targetNewest:
+		return bounds.Newest, nil
+	case targetOffset:
+		if offset < bounds.Oldest || offset > bounds.Newest {
+			return 0, fmt.Errorf("offset %d is outside available range %d..%d", offset, bounds.Oldest, bounds.Newest)
+		}
+		return offset, nil
+	case targetTimestamp:
+		if bounds.AtTime < 0 {
+			return bounds.Newest, nil
+		}
+		return bounds.AtTime, nil
+	default:
+		return 0, fmt.Errorf("unknown target %q", to)
+	}
+}
+
+// commitOffsets записывает новые смещения группы. ResetOffset, в отличие от
+// MarkOffset, позволяет сдвинуть смещение назад.
+func commitOffsets(client sarama.Client, group, topic string, targets map[int32]int64) error {
+	manager, err := sarama.NewOffsetManagerFromClient(group, client)
+	if err != nil {
+		return fmt.Errorf("create offset manager error: %w", err)
+	}
+
+	var managed []sarama.PartitionOffsetManager
+	for partition, target := range targets {
+		pom, err := manager.ManagePartition(topic, partition)
+		if err != nil {
+			closeManaged(managed)
+			manager.Close()
+			return fmt.Errorf("manage partition %d error: %w", partition, err)
+		}
+		pom.ResetOffset(target, "")
+		managed = append(managed, pom)
+	}
+
+	manager.Commit()
+	closeManaged(managed)
+	return manager.Close()
+}
+
+// verifyOffsets сверяет зафиксированные смещения группы с ожидаемыми
+func verifyOffsets(admin sarama.ClusterAdmin, group, topic string, targets map[int32]int64) error {
+	partitions := make([]int32, 0, len(targets))
+	for partition := range targets {
+		partitions = append(partitions, partition)
+	}
+	committed, err := admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+	if err != nil {
+		return fmt.Errorf("fetch committed offsets error: %w", err)
+	}
+	for partition, target := range targets {
+		block := committed.GetBlock(topic, partition)
+		if block == nil || block.Offset != target {
+			return fmt.Errorf("offset of partition %d was not committed: expected %d", partition, target)
+		}
+	}
+	return nil
+}
+
+// closeManaged закрывает менеджеры смещений партиций
+func closeManaged(managed []sarama.PartitionOffsetManager) {
+	for _, pom := range managed {
+		if err := pom.Close(); err != nil {
+			logger.Errorf("Offset manager close error: %v", err)
+		}
+	}
+}
+
+// formatOffset выводит смещение; -1 означает, что группа еще ничего не фиксировала
+func formatOffset(offset int64) string {
+	if offset < 0 {
+		return "none"
+	}
+	return fmt.Sprint(offset)
+}
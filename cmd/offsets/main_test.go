@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func TestResolveTarget(t *testing.T) {
+	bounds := partitionBounds{Oldest: 10, Newest: 50, AtTime: 30}
+
+	tests := []struct {
+		name    string
+		to      string
+		offset  int64
+		bounds  partitionBounds
+		want    int64
+		wantErr bool
+	}{
+		{name: "oldest", to: targetOldest, bounds: bounds, want: 10},
+		{name: "newest", to: targetNewest, bounds: bounds, want: 50},
+		{name: "offset inside range", to: targetOffset, offset: 20, bounds: bounds, want: 20},
+		{name: "offset at oldest", to: targetOffset, offset: 10, bounds: bounds, want: 10},
+		{name: "offset at newest", to: targetOffset, offset: 50, bounds: bounds, want: 50},
+		{name: "offset before oldest", to: targetOffset, offset: 9, bounds: bounds, wantErr: true},
+		{name: "offset after newest", to: targetOffset, offset: 51, bounds: bounds, wantErr: true},
+		{name: "timestamp", to: targetTimestamp, bounds: bounds, want: 30},
+		{name: "timestamp after last message", to: targetTimestamp,
+			bounds: partitionBounds{Oldest: 10, Newest: 50, AtTime: -1}, want: 50},
+		{name: "empty partition", to: targetOldest, bounds: partitionBounds{AtTime: -1}, want: 0},
+		{name: "unknown target", to: "latest", bounds: bounds, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTarget(tt.to, tt.offset, tt.bounds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTarget error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("resolveTarget = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeClient отдает заданные границы партиции; остальные методы Client не вызываются
+type fakeClient struct {
+	sarama.Client
+
+	oldest, newest int64
+	// atTime смещение, которое возвращается для запроса по времени
+	atTime int64
+	// times моменты в миллисекундах, по которым запрашивались смещения
+	times []int64
+}
+
+func (c *fakeClient) GetOffset(_ string, _ int32, at int64) (int64, error) {
+	switch at {
+	case sarama.OffsetOldest:
+		return c.oldest, nil
+	case sarama.OffsetNewest:
+		return c.newest, nil
+	default:
+		c.times = append(c.times, at)
+		return c.atTime, nil
+	}
+}
+
+func TestFetchBounds(t *testing.T) {
+	client := &fakeClient{oldest: 5, newest: 40, atTime: 25}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	bounds, err := fetchBounds(client, "orders", 0, targetTimestamp, at)
+	if err != nil {
+		t.Fatalf("fetchBounds: %v", err)
+	}
+	if want := (partitionBounds{Oldest: 5, Newest: 40, AtTime: 25}); bounds != want {
+		t.Errorf("bounds = %+v, want %+v", bounds, want)
+	}
+	if len(client.times) != 1 || client.times[0] != at.UnixMilli() {
+		t.Errorf("offset by time requested for %v, want %d ms", client.times, at.UnixMilli())
+	}
+
+	client.times = nil
+	bounds, err = fetchBounds(client, "orders", 0, targetOldest, at)
+	if err != nil {
+		t.Fatalf("fetchBounds: %v", err)
+	}
+	if bounds.AtTime != -1 || len(client.times) != 0 {
+		t.Errorf("offset by time = %d, requested %v; want no request for -to oldest", bounds.AtTime, client.times)
+	}
+}
+
+// fakeAdmin описывает группу и отдает ее смещения; остальные методы ClusterAdmin не вызываются
+type fakeAdmin struct {
+	sarama.ClusterAdmin
+
+	group         *sarama.GroupDescription
+	describeErr   error
+	committed     map[int32]int64
+	listOffsetErr error
+}
+
+func (a *fakeAdmin) DescribeConsumerGroups([]string) ([]*sarama.GroupDescription, error) {
+	if a.describeErr != nil {
+		return nil, a.describeErr
+	}
+	return []*sarama.GroupDescription{a.group}, nil
+}
+
+func (a *fakeAdmin) ListConsumerGroupOffsets(_ string, partitions map[string][]int32) (*sarama.OffsetFetchResponse, error) {
+	if a.listOffsetErr != nil {
+		return nil, a.listOffsetErr
+	}
+	response := &sarama.OffsetFetchResponse{}
+	for topic, list := range partitions {
+		for _, partition := range list {
+			if offset, ok := a.committed[partition]; ok {
+				response.AddBlock(topic, partition, &sarama.OffsetFetchResponseBlock{Offset: offset})
+			}
+		}
+	}
+	return response, nil
+}
+
+func TestEnsureGroupEmpty(t *testing.T) {
+	member := map[string]*sarama.GroupMemberDescription{"consumer-1": {ClientId: "consumer-1"}}
+
+	tests := []struct {
+		name    string
+		group   *sarama.GroupDescription
+		err     error
+		wantErr bool
+	}{
+		{name: "empty", group: &sarama.GroupDescription{State: "Empty"}},
+		{name: "never created", group: &sarama.GroupDescription{State: "Dead"}},
+		{name: "stable with members", group: &sarama.GroupDescription{State: "Stable", Members: member}, wantErr: true},
+		{name: "rebalancing", group: &sarama.GroupDescription{State: "PreparingRebalance"}, wantErr: true},
+		{name: "group error", group: &sarama.GroupDescription{Err: sarama.ErrGroupAuthorizationFailed}, wantErr: true},
+		{name: "describe error", err: errors.New("broker unavailable"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := &fakeAdmin{group: tt.group, describeErr: tt.err}
+			if err := ensureGroupEmpty(admin, "orders-group"); (err != nil) != tt.wantErr {
+				t.Errorf("ensureGroupEmpty = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyOffsets(t *testing.T) {
+	targets := map[int32]int64{0: 10, 1: 20}
+
+	tests := []struct {
+		name      string
+		committed map[int32]int64
+		wantErr   bool
+	}{
+		{name: "committed", committed: map[int32]int64{0: 10, 1: 20}},
+		{name: "stale partition", committed: map[int32]int64{0: 10, 1: 35}, wantErr: true},
+		{name: "missing partition", committed: map[int32]int64{0: 10}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := &fakeAdmin{committed: tt.committed}
+			if err := verifyOffsets(admin, "orders-group", "orders", targets); (err != nil) != tt.wantErr {
+				t.Errorf("verifyOffsets = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	if got := formatOffset(-1); got != "none" {
+		t.Errorf("formatOffset(-1) = %q, want none", got)
+	}
+	if got := formatOffset(42); got != "42" {
+		t.Errorf("formatOffset(42) = %q, want 42", got)
+	}
+}
@@ -1,86 +1,312 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
-	"time"
+	"path/filepath"
+	"slices"
 
+	"go-kafka-postgres/internal/config"
+	"go-kafka-postgres/internal/kafka"
 	"go-kafka-postgres/internal/logger"
 	"go-kafka-postgres/internal/model"
 
 	"github.com/IBM/sarama"
+	"golang.org/x/time/rate"
 )
 
 func main() {
+	brokersFlag := flag.String("brokers", "", "comma-separated Kafka broker addresses (overrides KAFKA_BROKERS)")
+	topicFlag := flag.String("topic", "", "Kafka topic (overrides KAFKA_TOPIC)")
+	dataFlag := flag.String("data", "", "JSON file or directory with orders (overrides PRODUCER_DATA)")
+	compression := flag.String("compression", "none", "message compression codec: none, gzip, snappy, lz4, zstd")
+	rateFlag := flag.Float64("rate", 2, "messages per second, 0 means unlimited")
+	acks := flag.String("acks", "all", "required acks: none, local, all")
+	retries := flag.Int("retries", 5, "max producer retries per message")
+	checkTopic := flag.Bool("check-topic", false, "verify the topic exists before sending")
+	createTopic := flag.Bool("create-topic", false, "create the topic if it is missing (implies -check-topic)")
+	partitions := flag.Int("partitions", 1, "number of partitions for a topic created with -create-topic")
+	replication := flag.Int("replication", 1, "replication factor for a topic created with -create-topic")
+	maxMessageBytes := flag.Int("max-message-bytes", 1000000, "refuse to send orders whose JSON exceeds this size")
+	dedup := flag.Bool("dedup", true, "drop orders with duplicate order_uid, keeping the last occurrence")
+	flag.Parse()
+
 	if err := logger.Init(os.Getenv("LOG_LEVEL")); err != nil {
 		panic("Failed to init logger: " + err.Error())
 	}
 	defer logger.Sync()
 
-	config := sarama.NewConfig()
-	config.Producer.Return.Successes = true
-	config.Producer.Retry.Max = 5
-	config.Producer.RequiredAcks = sarama.WaitForAll
+	saramaConfig, err := kafka.NewConfig(config.KafkaSecurity())
+	if err != nil {
+		logger.Fatalf("Error creating Kafka config: %v", err)
+	}
+	saramaConfig.Producer.Return.Successes = true
 
-	brokers := []string{"localhost:9092"}
-	if envBrokers := os.Getenv("KAFKA_BROKERS"); envBrokers != "" {
-		brokers = []string{envBrokers}
+	requiredAcks, err := parseAcks(*acks)
+	if err != nil {
+		logger.Fatalf("Invalid -acks: %v", err)
+	}
+	if *retries < 0 {
+		logger.Fatalf("Invalid -retries: %d", *retries)
+	}
+	saramaConfig.Producer.RequiredAcks = requiredAcks
+	saramaConfig.Producer.Retry.Max = *retries
+	if *maxMessageBytes < 1 {
+		logger.Fatalf("Invalid -max-message-bytes: %d", *maxMessageBytes)
 	}
+	saramaConfig.Producer.MaxMessageBytes = *maxMessageBytes
 
-	producer, err := sarama.NewSyncProducer(brokers, config)
+	// Потребитель распаковывает сообщения прозрачно средствами sarama
+	codec, err := parseCompression(*compression)
 	if err != nil {
-		logger.Fatalf("Error creating producer: %v", err)
+		logger.Fatalf("Invalid -compression: %v", err)
 	}
-	defer producer.Close()
+	saramaConfig.Producer.Compression = codec
+	if codec == sarama.CompressionZSTD {
+		// zstd поддерживается брокером начиная с Kafka 2.1
+		saramaConfig.Version = sarama.V2_1_0_0
+	}
+
+	logger.Infof("Producer settings: acks=%s, retries=%d, compression=%s", *acks, *retries, *compression)
+
+	brokers := config.GetStringSlice("KAFKA_BROKERS", config.DefaultBrokers)
+	if *brokersFlag != "" {
+		brokers = config.SplitList(*brokersFlag)
+	}
+	topic := resolve(*topicFlag, "KAFKA_TOPIC", "orders")
 
-	topic := "orders"
-	if envTopic := os.Getenv("KAFKA_TOPIC"); envTopic != "" {
-		topic = envTopic
+	if *checkTopic || *createTopic {
+		if *partitions < 1 || *replication < 1 {
+			logger.Fatalf("Invalid -partitions %d or -replication %d", *partitions, *replication)
+		}
+		admin, err := sarama.NewClusterAdmin(brokers, saramaConfig)
+		if err != nil {
+			logger.Fatalf("Error creating cluster admin: %v", err)
+		}
+		err = ensureTopic(admin, topic, *createTopic, int32(*partitions), int16(*replication))
+		admin.Close()
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
 	}
 
-	orders, err := loadTestData()
+	producer, err := sarama.NewSyncProducer(brokers, saramaConfig)
+	if err != nil {
+		logger.Fatalf("Error creating producer: %v", err)
+	}
+	defer producer.Close()
+
+	orders, err := loadTestData(resolve(*dataFlag, "PRODUCER_DATA", "model.json"))
 	if err != nil {
 		logger.Fatalf("Error loading test data: %v", err)
 	}
+	if *rateFlag < 0 {
+		logger.Fatalf("Invalid -rate: %v", *rateFlag)
+	}
+	limiter := newLimiter(*rateFlag)
 
-	for i, order := range orders {
-		messageJSON, err := json.Marshal(order)
-		if err != nil {
-			logger.Errorf("Error marshaling order %d: %v", i, err)
-			continue
+	if *dedup {
+		var dropped int
+		orders, dropped = dedupOrders(orders)
+		if dropped > 0 {
+			logger.Infof("Dropped %d orders with duplicate order_uid", dropped)
 		}
+	}
 
-		msg := &sarama.ProducerMessage{
-			Topic: topic,
-			Key:   sarama.StringEncoder(order.OrderUID),
-			Value: sarama.ByteEncoder(messageJSON),
+	for i, order := range orders {
+		if err := limiter.Wait(context.Background()); err != nil {
+			logger.Fatalf("Rate limiter error: %v", err)
 		}
 
-		partition, offset, err := producer.SendMessage(msg)
-		if err != nil {
-			logger.Errorf("Error sending message %d: %v", i, err)
-		} else {
-			logger.Infof("Message %d sent successfully. Partition: %d, Offset: %d, OrderUID: %s",
-				i, partition, offset, order.OrderUID)
+		sendOrder(producer, topic, i, order, *maxMessageBytes)
+	}
+
+	logger.Info("All messages sent successfully")
+}
+
+// sendOrder отправляет заказ с порядковым номером i в топик. Заказы, JSON
+// которых больше maxMessageBytes, не отправляются: брокер их все равно отклонит.
+func sendOrder(producer sarama.SyncProducer, topic string, i int, order model.Order, maxMessageBytes int) {
+	messageJSON, err := json.Marshal(order)
+	if err != nil {
+		logger.Errorf("Error marshaling order %d: %v", i, err)
+		return
+	}
+	if len(messageJSON) > maxMessageBytes {
+		logger.Errorf("Order %d (%s) is %d bytes, exceeding -max-message-bytes %d; not sending",
+			i, order.OrderUID, len(messageJSON), maxMessageBytes)
+		return
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(order.OrderUID),
+		Value: sarama.ByteEncoder(messageJSON),
+	}
+
+	partition, offset, err := producer.SendMessage(msg)
+	if err != nil {
+		logger.Errorf("Error sending message %d: %v", i, err)
+	} else {
+		logger.Infof("Message %d sent successfully. Partition: %d, Offset: %d, OrderUID: %s",
+			i, partition, offset, order.OrderUID)
+	}
+}
+
+// newLimiter возвращает ограничитель на perSecond сообщений в секунду
+// без накопления запаса; 0 — без ограничения
+func newLimiter(perSecond float64) *rate.Limiter {
+	limit := rate.Limit(perSecond)
+	if perSecond == 0 {
+		limit = rate.Inf
+	}
+	return rate.NewLimiter(limit, 1)
+}
+
+// ensureTopic проверяет, что топик существует, и при create создает отсутствующий
+// топик с заданным числом партиций и фактором репликации
+func ensureTopic(admin sarama.ClusterAdmin, topic string, create bool, partitions int32, replication int16) error {
+	topics, err := admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("list topics error: %w", err)
+	}
+	if _, ok := topics[topic]; ok {
+		logger.Infof("Topic %s exists", topic)
+		return nil
+	}
+
+	if !create {
+		return fmt.Errorf("topic %q does not exist and broker auto-creation may be disabled; "+
+			"create it or rerun with -create-topic", topic)
+	}
+
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     partitions,
+		ReplicationFactor: replication,
+	}, false)
+	switch {
+	case errors.Is(err, sarama.ErrTopicAlreadyExists):
+		// Топик мог создать другой процесс между ListTopics и CreateTopic
+		logger.Infof("Topic %s already exists", topic)
+	case err != nil:
+		return fmt.Errorf("create topic %q error: %w", topic, err)
+	default:
+		logger.Infof("Created topic %s with %d partitions, replication factor %d", topic, partitions, replication)
+	}
+	return nil
+}
+
+// dedupOrders удаляет заказы с повторяющимся order_uid, оставляя последнее
+// вхождение на его месте, и возвращает число отброшенных заказов
+func dedupOrders(orders []model.Order) ([]model.Order, int) {
+	seen := make(map[string]struct{}, len(orders))
+	unique := make([]model.Order, 0, len(orders))
+	for i := len(orders) - 1; i >= 0; i-- {
+		if _, ok := seen[orders[i].OrderUID]; ok {
+			continue
 		}
+		seen[orders[i].OrderUID] = struct{}{}
+		unique = append(unique, orders[i])
+	}
+	slices.Reverse(unique)
+	return unique, len(orders) - len(unique)
+}
 
-		time.Sleep(500 * time.Millisecond)
+// parseCompression преобразует название кодека в константу sarama
+func parseCompression(name string) (sarama.CompressionCodec, error) {
+	switch name {
+	case "", "none":
+		return sarama.CompressionNone, nil
+	case "gzip":
+		return sarama.CompressionGZIP, nil
+	case "snappy":
+		return sarama.CompressionSnappy, nil
+	case "lz4":
+		return sarama.CompressionLZ4, nil
+	case "zstd":
+		return sarama.CompressionZSTD, nil
+	default:
+		return sarama.CompressionNone, fmt.Errorf("unknown compression codec %q", name)
 	}
+}
 
-	logger.Info("All messages sent successfully")
+// parseAcks преобразует уровень подтверждения записи в константу sarama
+func parseAcks(name string) (sarama.RequiredAcks, error) {
+	switch name {
+	case "none":
+		return sarama.NoResponse, nil
+	case "local":
+		return sarama.WaitForLocal, nil
+	case "", "all":
+		return sarama.WaitForAll, nil
+	default:
+		return sarama.WaitForAll, fmt.Errorf("unknown acks value %q", name)
+	}
+}
+
+// resolve выбирает значение параметра: флаг, затем переменная окружения, затем значение по умолчанию
+func resolve(flagValue, envKey, defaultValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return config.GetString(envKey, defaultValue)
 }
 
-func loadTestData() ([]model.Order, error) {
-	if fileData, err := os.ReadFile("model.json"); err == nil {
-		var order model.Order
-		if err := json.Unmarshal(fileData, &order); err == nil {
-			return []model.Order{order}, nil
-		} else {
-			logger.Errorf("Invalid JSON in model.json: %v", err)
+// loadTestData загружает заказы из JSON-файла или из всех *.json файлов каталога.
+// Файл может содержать один заказ или массив заказов.
+func loadTestData(path string) ([]model.Order, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		logger.Errorf("Failed to read %s: %v", path, err)
+		return nil, nil
+	}
+
+	if !info.IsDir() {
+		return loadOrdersFile(path)
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []model.Order
+	for _, file := range files {
+		fileOrders, err := loadOrdersFile(file)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, fileOrders...)
+	}
+	return orders, nil
+}
+
+// loadOrdersFile читает один заказ или массив заказов из файла
+func loadOrdersFile(path string) ([]model.Order, error) {
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		logger.Errorf("Failed to read %s: %v", path, err)
+		return nil, nil
+	}
+
+	if trimmed := bytes.TrimSpace(fileData); len(trimmed) > 0 && trimmed[0] == '[' {
+		var orders []model.Order
+		if err := json.Unmarshal(fileData, &orders); err != nil {
+			logger.Errorf("Invalid JSON in %s: %v", path, err)
 			return nil, nil
 		}
-	} else {
-		logger.Errorf("Failed to read model.json: %v", err)
+		return orders, nil
+	}
+
+	var order model.Order
+	if err := json.Unmarshal(fileData, &order); err != nil {
+		logger.Errorf("Invalid JSON in %s: %v", path, err)
 		return nil, nil
 	}
+	return []model.Order{order}, nil
 }
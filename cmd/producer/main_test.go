@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+
+	"github.com/IBM/sarama"
+	"golang.org/x/time/rate"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		name string
+		want sarama.CompressionCodec
+	}{
+		{name: "", want: sarama.CompressionNone},
+		{name: "none", want: sarama.CompressionNone},
+		{name: "gzip", want: sarama.CompressionGZIP},
+		{name: "snappy", want: sarama.CompressionSnappy},
+		{name: "lz4", want: sarama.CompressionLZ4},
+		{name: "zstd", want: sarama.CompressionZSTD},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCompression(tt.name)
+			if err != nil {
+				t.Fatalf("parseCompression(%q): %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseCompression(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"GZIP", "brotli"} {
+		if _, err := parseCompression(name); err == nil {
+			t.Errorf("parseCompression(%q) succeeded, want error", name)
+		}
+	}
+}
+
+func TestParseAcks(t *testing.T) {
+	tests := []struct {
+		name string
+		want sarama.RequiredAcks
+	}{
+		{name: "", want: sarama.WaitForAll},
+		{name: "all", want: sarama.WaitForAll},
+		{name: "local", want: sarama.WaitForLocal},
+		{name: "none", want: sarama.NoResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAcks(tt.name)
+			if err != nil {
+				t.Fatalf("parseAcks(%q): %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseAcks(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	for _, name := range []string{"ALL", "1", "quorum"} {
+		if _, err := parseAcks(name); err == nil {
+			t.Errorf("parseAcks(%q) succeeded, want error", name)
+		}
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	const key = "PRODUCER_TEST_TOPIC"
+
+	t.Setenv(key, "")
+	if got := resolve("", key, "orders"); got != "orders" {
+		t.Errorf("without flag and env = %q, want the default", got)
+	}
+
+	t.Setenv(key, "from-env")
+	if got := resolve("", key, "orders"); got != "from-env" {
+		t.Errorf("with env = %q, want the env value", got)
+	}
+	if got := resolve("from-flag", key, "orders"); got != "from-flag" {
+		t.Errorf("with flag and env = %q, want the flag value", got)
+	}
+}
+
+func TestDedupOrders(t *testing.T) {
+	order := func(uid, version string) model.Order {
+		return model.Order{OrderUID: uid, TrackNumber: version}
+	}
+	orders := []model.Order{
+		order("a", "a1"), order("b", "b1"), order("a", "a2"), order("c", "c1"), order("b", "b2"), order("a", "a3"),
+	}
+
+	unique, dropped := dedupOrders(orders)
+
+	if dropped != 3 {
+		t.Errorf("dropped = %d, want 3", dropped)
+	}
+	var got []string
+	for _, o := range unique {
+		got = append(got, o.TrackNumber)
+	}
+	// Остается последнее вхождение каждого UID, порядок последних вхождений сохраняется
+	if want := []string{"c1", "b2", "a3"}; !slices.Equal(got, want) {
+		t.Errorf("unique orders = %v, want %v", got, want)
+	}
+}
+
+func TestDedupOrdersWithoutDuplicates(t *testing.T) {
+	orders := []model.Order{{OrderUID: "a"}, {OrderUID: "b"}}
+	unique, dropped := dedupOrders(orders)
+	if dropped != 0 || len(unique) != 2 || unique[0].OrderUID != "a" || unique[1].OrderUID != "b" {
+		t.Errorf("dedupOrders = %v, %d; want the input unchanged", unique, dropped)
+	}
+}
+
+// waitAll ждет n разрешений ограничителя и возвращает затраченное время
+func waitAll(t *testing.T, limiter *rate.Limiter, n int) time.Duration {
+	t.Helper()
+	start := time.Now()
+	for range n {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	return time.Since(start)
+}
+
+func TestNewLimiterPacesSends(t *testing.T) {
+	// Первое сообщение уходит сразу, следующие 10 — с интервалом 10ms
+	elapsed := waitAll(t, newLimiter(100), 11)
+	if elapsed < 90*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("11 sends at 100/s took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestNewLimiterUnlimited(t *testing.T) {
+	if elapsed := waitAll(t, newLimiter(0), 1000); elapsed > 100*time.Millisecond {
+		t.Errorf("1000 unlimited sends took %v", elapsed)
+	}
+}
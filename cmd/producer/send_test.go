@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+// fakeSyncProducer запоминает отправленные сообщения
+type fakeSyncProducer struct {
+	sarama.SyncProducer
+
+	sent []*sarama.ProducerMessage
+}
+
+func (p *fakeSyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	p.sent = append(p.sent, msg)
+	return 0, int64(len(p.sent) - 1), nil
+}
+
+func TestSendOrderSizeLimit(t *testing.T) {
+	order := *testutil.Order("b563feb7b2b84b6test")
+	body, err := json.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		wantSent bool
+	}{
+		{name: "below limit", maxBytes: len(body) + 1, wantSent: true},
+		{name: "at limit", maxBytes: len(body), wantSent: true},
+		{name: "above limit", maxBytes: len(body) - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := observeLogs(t)
+			producer := &fakeSyncProducer{}
+
+			sendOrder(producer, "orders", 0, order, tt.maxBytes)
+
+			if sent := len(producer.sent) == 1; sent != tt.wantSent {
+				t.Fatalf("order sent = %v, want %v", sent, tt.wantSent)
+			}
+			refused := logs.FilterMessageSnippet("exceeding -max-message-bytes").Len()
+			if tt.wantSent && refused != 0 {
+				t.Errorf("logged %d size errors for an order within the limit", refused)
+			}
+			if !tt.wantSent && refused != 1 {
+				t.Errorf("logged %d size errors, want 1", refused)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"go-kafka-postgres/internal/logger"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeClusterAdmin хранит топики в памяти; остальные методы ClusterAdmin не вызываются
+type fakeClusterAdmin struct {
+	sarama.ClusterAdmin
+
+	topics    map[string]sarama.TopicDetail
+	listErr   error
+	createErr error
+	// created параметры вызовов CreateTopic по имени топика
+	created map[string]*sarama.TopicDetail
+}
+
+func newFakeClusterAdmin(topics ...string) *fakeClusterAdmin {
+	admin := &fakeClusterAdmin{
+		topics:  make(map[string]sarama.TopicDetail),
+		created: make(map[string]*sarama.TopicDetail),
+	}
+	for _, topic := range topics {
+		admin.topics[topic] = sarama.TopicDetail{NumPartitions: 1, ReplicationFactor: 1}
+	}
+	return admin
+}
+
+func (a *fakeClusterAdmin) ListTopics() (map[string]sarama.TopicDetail, error) {
+	return a.topics, a.listErr
+}
+
+func (a *fakeClusterAdmin) CreateTopic(topic string, detail *sarama.TopicDetail, _ bool) error {
+	a.created[topic] = detail
+	return a.createErr
+}
+
+// observeLogs перенаправляет логгер в память до конца теста
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.InfoLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+	return logs
+}
+
+func TestEnsureTopicExists(t *testing.T) {
+	logs := observeLogs(t)
+	admin := newFakeClusterAdmin("orders")
+
+	if err := ensureTopic(admin, "orders", true, 3, 1); err != nil {
+		t.Fatalf("ensureTopic: %v", err)
+	}
+	if len(admin.created) != 0 {
+		t.Errorf("CreateTopic called for an existing topic: %v", admin.created)
+	}
+	if logs.FilterMessage("Topic orders exists").Len() != 1 {
+		t.Errorf("logs = %v, want the topic reported as existing", logs.All())
+	}
+}
+
+func TestEnsureTopicMissing(t *testing.T) {
+	admin := newFakeClusterAdmin("other")
+
+	if err := ensureTopic(admin, "orders", false, 3, 1); err == nil {
+		t.Fatal("ensureTopic succeeded for a missing topic without -create-topic")
+	}
+	if len(admin.created) != 0 {
+		t.Errorf("CreateTopic called without -create-topic: %v", admin.created)
+	}
+}
+
+func TestEnsureTopicCreates(t *testing.T) {
+	logs := observeLogs(t)
+	admin := newFakeClusterAdmin()
+
+	if err := ensureTopic(admin, "orders", true, 3, 2); err != nil {
+		t.Fatalf("ensureTopic: %v", err)
+	}
+	detail, ok := admin.created["orders"]
+	if !ok {
+		t.Fatal("CreateTopic was not called")
+	}
+	if detail.NumPartitions != 3 || detail.ReplicationFactor != 2 {
+		t.Errorf("topic detail = %+v, want 3 partitions and replication factor 2", detail)
+	}
+	if logs.FilterMessageSnippet("Created topic orders").Len() != 1 {
+		t.Errorf("logs = %v, want the topic reported as created", logs.All())
+	}
+}
+
+func TestEnsureTopicCreatedConcurrently(t *testing.T) {
+	logs := observeLogs(t)
+	admin := newFakeClusterAdmin()
+	admin.createErr = sarama.ErrTopicAlreadyExists
+
+	if err := ensureTopic(admin, "orders", true, 3, 1); err != nil {
+		t.Fatalf("ensureTopic: %v", err)
+	}
+	if logs.FilterMessage("Topic orders already exists").Len() != 1 {
+		t.Errorf("logs = %v, want the topic reported as already existing", logs.All())
+	}
+	if logs.FilterMessageSnippet("Created topic").Len() != 0 {
+		t.Error("topic reported as created although CreateTopic found it existing")
+	}
+}
+
+func TestEnsureTopicErrors(t *testing.T) {
+	broken := errors.New("broker unavailable")
+	tests := []struct {
+		name  string
+		admin *fakeClusterAdmin
+	}{
+		{name: "list topics", admin: &fakeClusterAdmin{listErr: broken}},
+		{name: "create topic", admin: func() *fakeClusterAdmin {
+			admin := newFakeClusterAdmin()
+			admin.createErr = broken
+			return admin
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ensureTopic(tt.admin, "orders", true, 3, 1); !errors.Is(err, broken) {
+				t.Errorf("ensureTopic error = %v, want %v", err, broken)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !embedweb
+
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"go-kafka-postgres/internal/logger"
+)
+
+// staticHandler раздает веб-интерфейс из каталога на диске
+func staticHandler() http.Handler {
+	dir := os.Getenv("WEB_DIR")
+	if dir == "" {
+		dir = "./web"
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		logger.Errorf("Web directory %s does not exist, static files will not be served", dir)
+	} else {
+		logger.Infof("Serving static files from %s", dir)
+	}
+
+	return http.FileServer(http.Dir(dir))
+}
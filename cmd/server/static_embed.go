@@ -0,0 +1,16 @@
+//go:build embedweb
+
+package main
+
+import (
+	"net/http"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/web"
+)
+
+// staticHandler раздает веб-интерфейс, встроенный в бинарник
+func staticHandler() http.Handler {
+	logger.Info("Serving embedded static files")
+	return http.FileServer(http.FS(web.FS))
+}
@@ -1,16 +1,57 @@
 package cache
 
 import (
+	"context"
 	"go-kafka-postgres/internal/model"
 	"sync"
+	"time"
 )
 
 // Cache интерфейс для кэша
 type Cache interface {
+	// GetCtx и SetCtx учитывают отмену и дедлайн контекста; для распределенного
+	// кэша они также несут ошибки обращения к нему. Get и Set — обертки
+	// с context.Background()
+	GetCtx(ctx context.Context, uid string) (*model.Order, bool, error)
+	SetCtx(ctx context.Context, order *model.Order) error
 	Get(uid string) (*model.Order, bool)
+	Peek(uid string) (*model.Order, bool)
 	Set(order *model.Order)
 	Restore(orders []*model.Order)
 	Size() int
+	Stats() Stats
+	Keys() []string
+	Delete(uid string)
+	Clear()
+	Resize(newMax int)
+}
+
+// Stats статистика использования кэша
+type Stats struct {
+	Size      int    `json:"size"`
+	MaxSize   int    `json:"max_size"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// CacheEntry заказ в кэше вместе со сведениями об использовании
+type CacheEntry struct {
+	Order *model.Order
+	// InsertedAt когда заказ попал в кэш; перезапись заказа его не меняет
+	InsertedAt time.Time
+	// LastAccessedAt время последнего попадания через Get; нулевое, если попаданий не было
+	LastAccessedAt time.Time
+	// AccessCount число попаданий через Get с момента InsertedAt
+	AccessCount uint64
+}
+
+// EntryGetter кэш, который хранит сведения об использовании заказов.
+// Реализуется LRU кэшем; распределенный и отключенный кэши их не ведут.
+type EntryGetter interface {
+	// GetEntry возвращает копию записи; как и Peek, не считается обращением
+	// и не меняет позицию заказа в LRU
+	GetEntry(uid string) (*CacheEntry, bool)
 }
 
 // lruNode узел двусвязного списка для LRU
@@ -18,58 +59,148 @@ type lruNode struct {
 	key  string
 	prev *lruNode
 	next *lruNode
+
+	insertedAt   time.Time
+	lastAccessed time.Time
+	accesses     uint64
 }
 
 // OrderCache реализация кэша заказов с LRU инвалидацией
 type OrderCache struct {
-	mu      sync.RWMutex
-	orders  map[string]*model.Order
-	lruHead *lruNode
-	lruTail *lruNode
-	nodeMap map[string]*lruNode // Соответствие ключа узлу LRU
-	maxSize int
+	mu        sync.RWMutex
+	orders    map[string]*model.Order
+	lruHead   *lruNode
+	lruTail   *lruNode
+	nodeMap   map[string]*lruNode // Соответствие ключа узлу LRU
+	maxSize   int
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	onEvict   func(uid string, order *model.Order)
+}
+
+// Options дополнительные настройки кэша
+type Options struct {
+	// OnEvict вызывается для каждого заказа, вытесненного из-за переполнения
+	// (в том числе при Resize). Вызов выполняется вне блокировки кэша,
+	// поэтому из callback можно обращаться к кэшу.
+	OnEvict func(uid string, order *model.Order)
+}
+
+// evictedOrder заказ, вытесненный из кэша
+type evictedOrder struct {
+	uid   string
+	order *model.Order
 }
 
 // New создает новый кэш заказов с ограничением размера
 func New(maxSize int) Cache {
+	return NewWithOptions(maxSize, Options{})
+}
+
+// NewWithOptions создает новый кэш заказов с ограничением размера и дополнительными настройками
+func NewWithOptions(maxSize int, opts Options) Cache {
 	return &OrderCache{
 		orders:  make(map[string]*model.Order),
 		nodeMap: make(map[string]*lruNode),
 		maxSize: maxSize,
+		onEvict: opts.OnEvict,
 	}
 }
 
 // Get возвращает заказ по UID и обновляет его позицию в LRU
 func (c *OrderCache) Get(uid string) (*model.Order, bool) {
+	order, ok, _ := c.GetCtx(context.Background(), uid)
+	return order, ok
+}
+
+// GetCtx возвращает заказ по UID, если контекст еще не отменен
+func (c *OrderCache) GetCtx(ctx context.Context, uid string) (*model.Order, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	order, ok := c.orders[uid]
 	if ok {
+		c.hits++
 		c.updateLRU(uid)
+		if node := c.nodeMap[uid]; node != nil {
+			node.lastAccessed = time.Now()
+			node.accesses++
+		}
+	} else {
+		c.misses++
 	}
+	return order, ok, nil
+}
+
+// Peek возвращает заказ по UID, не меняя его позицию в LRU и счетчики попаданий
+func (c *OrderCache) Peek(uid string) (*model.Order, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	order, ok := c.orders[uid]
 	return order, ok
 }
 
+// GetEntry возвращает заказ со сведениями об использовании
+func (c *OrderCache) GetEntry(uid string) (*CacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	order, ok := c.orders[uid]
+	node := c.nodeMap[uid]
+	if !ok || node == nil {
+		return nil, false
+	}
+	return &CacheEntry{
+		Order:          order,
+		InsertedAt:     node.insertedAt,
+		LastAccessedAt: node.lastAccessed,
+		AccessCount:    node.accesses,
+	}, true
+}
+
 // Set добавляет заказ в кэш
 func (c *OrderCache) Set(order *model.Order) {
+	_ = c.SetCtx(context.Background(), order)
+}
+
+// SetCtx добавляет заказ в кэш, если контекст еще не отменен
+func (c *OrderCache) SetCtx(ctx context.Context, order *model.Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	evicted := c.set(order)
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted)
+	return nil
+}
 
+// set добавляет заказ в кэш и возвращает вытесненные заказы; вызывается под блокировкой
+func (c *OrderCache) set(order *model.Order) []evictedOrder {
 	uid := order.OrderUID
 
 	if _, exists := c.orders[uid]; exists {
 		c.updateLRU(uid)
 		c.orders[uid] = order
-		return
+		return nil
 	}
 
+	var evicted []evictedOrder
 	if len(c.orders) >= c.maxSize {
-		c.evictLRU()
+		evicted = c.evictLRU(evicted)
 	}
 
 	c.orders[uid] = order
 	c.addToLRU(uid)
+	return evicted
 }
 
 // Restore восстанавливает кэш из списка заказов
@@ -77,10 +208,7 @@ func (c *OrderCache) Restore(orders []*model.Order) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.orders = make(map[string]*model.Order)
-	c.nodeMap = make(map[string]*lruNode)
-	c.lruHead = nil
-	c.lruTail = nil
+	c.reset()
 
 	for _, order := range orders {
 		uid := order.OrderUID
@@ -93,6 +221,61 @@ func (c *OrderCache) Restore(orders []*model.Order) {
 	}
 }
 
+// Delete удаляет заказ из кэша
+func (c *OrderCache) Delete(uid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.orders[uid]; !exists {
+		return
+	}
+	delete(c.orders, uid)
+	c.removeFromLRU(uid)
+}
+
+// Clear удаляет все заказы из кэша, сохраняя ограничение размера
+func (c *OrderCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+}
+
+// Resize изменяет максимальный размер кэша. При уменьшении ниже текущего
+// размера вытесняются наименее используемые элементы. Значения меньше 1 приводятся к 1.
+func (c *OrderCache) Resize(newMax int) {
+	if newMax < 1 {
+		newMax = 1
+	}
+
+	c.mu.Lock()
+	c.maxSize = newMax
+	var evicted []evictedOrder
+	for len(c.orders) > c.maxSize {
+		evicted = c.evictLRU(evicted)
+	}
+	c.mu.Unlock()
+
+	c.notifyEvicted(evicted)
+}
+
+// notifyEvicted вызывает OnEvict для вытесненных заказов; вызывается без блокировки
+func (c *OrderCache) notifyEvicted(evicted []evictedOrder) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.onEvict(e.uid, e.order)
+	}
+}
+
+// reset очищает содержимое кэша; вызывается под блокировкой
+func (c *OrderCache) reset() {
+	c.orders = make(map[string]*model.Order)
+	c.nodeMap = make(map[string]*lruNode)
+	c.lruHead = nil
+	c.lruTail = nil
+}
+
 // Size возвращает размер кэша
 func (c *OrderCache) Size() int {
 	c.mu.RLock()
@@ -100,9 +283,34 @@ func (c *OrderCache) Size() int {
 	return len(c.orders)
 }
 
+// Stats возвращает текущую статистику кэша
+func (c *OrderCache) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Stats{
+		Size:      len(c.orders),
+		MaxSize:   c.maxSize,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// Keys возвращает UID закэшированных заказов в порядке LRU (от последнего использованного)
+func (c *OrderCache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.orders))
+	for node := c.lruHead; node != nil; node = node.next {
+		keys = append(keys, node.key)
+	}
+	return keys
+}
+
 // addToLRU добавляет новый элемент в начало LRU списка
 func (c *OrderCache) addToLRU(uid string) {
-	node := &lruNode{key: uid}
+	node := &lruNode{key: uid, insertedAt: time.Now()}
 
 	if c.lruHead == nil {
 		c.lruHead = node
@@ -150,16 +358,44 @@ func (c *OrderCache) updateLRU(uid string) {
 	}
 }
 
-// evictLRU удаляет наименее используемый элемент из кэша
-func (c *OrderCache) evictLRU() {
-	if c.lruTail == nil {
+// removeFromLRU удаляет элемент из LRU списка
+func (c *OrderCache) removeFromLRU(uid string) {
+	node, exists := c.nodeMap[uid]
+	if !exists {
 		return
 	}
 
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		c.lruHead = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		c.lruTail = node.prev
+	}
+
+	delete(c.nodeMap, uid)
+}
+
+// evictLRU удаляет наименее используемый элемент из кэша и добавляет его
+// в evicted для последующего вызова OnEvict
+func (c *OrderCache) evictLRU(evicted []evictedOrder) []evictedOrder {
+	if c.lruTail == nil {
+		return evicted
+	}
+
+	if c.onEvict != nil {
+		evicted = append(evicted, evictedOrder{uid: c.lruTail.key, order: c.orders[c.lruTail.key]})
+	}
 	delete(c.orders, c.lruTail.key)
 
 	delete(c.nodeMap, c.lruTail.key)
 
+	c.evictions++
+	cacheEvictions.Inc()
+
 	if c.lruTail.prev != nil {
 		c.lruTail.prev.next = nil
 		c.lruTail = c.lruTail.prev
@@ -167,4 +403,5 @@ func (c *OrderCache) evictLRU() {
 		c.lruHead = nil
 		c.lruTail = nil
 	}
+	return evicted
 }
@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// setOrders кладет в кэш заказы с указанными UID по порядку
+func setOrders(c Cache, uids ...string) {
+	for _, uid := range uids {
+		c.Set(&model.Order{OrderUID: uid})
+	}
+}
+
+// wantKeys проверяет UID в кэше в порядке LRU
+func wantKeys(t *testing.T, c Cache, want ...string) {
+	t.Helper()
+	if got := c.Keys(); !slices.Equal(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+	if got := c.Size(); got != len(want) {
+		t.Errorf("size = %d, want %d", got, len(want))
+	}
+}
+
+func TestClear(t *testing.T) {
+	c := New(3)
+	setOrders(c, "a", "b", "c")
+
+	c.Clear()
+	wantKeys(t, c)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get after Clear found an order")
+	}
+	if got := c.Stats().MaxSize; got != 3 {
+		t.Errorf("max size after Clear = %d, want 3", got)
+	}
+
+	// LRU после очистки работает как в новом кэше
+	setOrders(c, "d", "e", "f")
+	c.Get("d")
+	setOrders(c, "g")
+	wantKeys(t, c, "g", "d", "f")
+	if _, ok := c.Get("e"); ok {
+		t.Error("least recently used e survived eviction after Clear")
+	}
+}
+
+func TestResize(t *testing.T) {
+	c := New(2)
+	setOrders(c, "a", "b")
+
+	// Рост только поднимает ограничение
+	c.Resize(5)
+	wantKeys(t, c, "b", "a")
+	setOrders(c, "c", "d", "e")
+	wantKeys(t, c, "e", "d", "c", "b", "a")
+
+	c.Get("a")
+	c.Get("c")
+
+	// При уменьшении остаются последние использованные
+	c.Resize(3)
+	wantKeys(t, c, "c", "a", "e")
+	if stats := c.Stats(); stats.MaxSize != 3 || stats.Evictions != 2 {
+		t.Errorf("stats = %+v, want max size 3 and 2 evictions", stats)
+	}
+
+	setOrders(c, "f")
+	wantKeys(t, c, "f", "c", "a")
+
+	c.Resize(0)
+	wantKeys(t, c, "f")
+}
+
+func TestResizeConcurrent(t *testing.T) {
+	c := New(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				uid := strconv.Itoa(j % 150)
+				c.Set(&model.Order{OrderUID: uid})
+				c.Get(uid)
+			}
+		}()
+	}
+	for size := 100; size > 10; size -= 10 {
+		c.Resize(size)
+	}
+	wg.Wait()
+
+	if size := c.Size(); size > 20 {
+		t.Errorf("size = %d, want at most 20", size)
+	}
+	if keys := c.Keys(); len(keys) != c.Size() {
+		t.Errorf("LRU list has %d nodes, map has %d orders", len(keys), c.Size())
+	}
+}
+
+func TestPeekKeepsLRUOrder(t *testing.T) {
+	c := New(3)
+	setOrders(c, "a", "b", "c")
+
+	if order, ok := c.Peek("a"); !ok || order.OrderUID != "a" {
+		t.Fatalf("Peek(a) = %v, %v; want the cached order", order, ok)
+	}
+	if _, ok := c.Peek("missing"); ok {
+		t.Error("Peek found a missing order")
+	}
+	wantKeys(t, c, "c", "b", "a")
+	if stats := c.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("Peek changed hit/miss stats: %+v", stats)
+	}
+
+	// Подсмотренный заказ остается самым старым и вытесняется первым
+	setOrders(c, "d")
+	wantKeys(t, c, "d", "c", "b")
+}
+
+func TestGetPromotesInLRU(t *testing.T) {
+	c := New(3)
+	setOrders(c, "a", "b", "c")
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	wantKeys(t, c, "a", "c", "b")
+
+	setOrders(c, "d")
+	wantKeys(t, c, "d", "a", "c")
+}
+
+// evictionLog запоминает UID, переданные в OnEvict
+type evictionLog struct {
+	mu   sync.Mutex
+	uids []string
+}
+
+func (l *evictionLog) record(uid string, order *model.Order) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if order == nil || order.OrderUID != uid {
+		uid += " (order mismatch)"
+	}
+	l.uids = append(l.uids, uid)
+}
+
+func (l *evictionLog) UIDs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.uids...)
+}
+
+func TestOnEvictOnOverflow(t *testing.T) {
+	var log evictionLog
+	c := NewWithOptions(2, Options{OnEvict: log.record})
+	setOrders(c, "a", "b")
+	c.Get("a")
+	setOrders(c, "c")
+
+	if got := log.UIDs(); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("evicted = %v, want [b]", got)
+	}
+
+	c.Resize(1)
+	if got := log.UIDs(); !slices.Equal(got, []string{"b", "a"}) {
+		t.Errorf("evicted after Resize = %v, want [b a]", got)
+	}
+}
+
+func TestOnEvictNotCalledForDelete(t *testing.T) {
+	var log evictionLog
+	c := NewWithOptions(3, Options{OnEvict: log.record})
+	setOrders(c, "a", "b", "c")
+	setOrders(c, "a") // перезапись не вытесняет
+
+	c.Delete("a")
+	c.Clear()
+	if got := log.UIDs(); len(got) != 0 {
+		t.Errorf("evicted = %v, want none: only overflow evicts", got)
+	}
+}
+
+func TestOnEvictMayUseCache(t *testing.T) {
+	var c Cache
+	var sizes []int
+	c = NewWithOptions(1, Options{OnEvict: func(string, *model.Order) {
+		// Вызов под блокировкой кэша здесь бы завис
+		sizes = append(sizes, c.Size())
+	}})
+	setOrders(c, "a", "b")
+
+	if !slices.Equal(sizes, []int{1}) {
+		t.Errorf("sizes seen from OnEvict = %v, want [1]", sizes)
+	}
+}
+
+func TestGetCtxCanceledReturnsPromptly(t *testing.T) {
+	c := New(10).(*OrderCache)
+	setOrders(c, "a")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Кэш заблокирован другим вызовом: отмененный контекст не должен ждать блокировку
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		_, ok, err := c.GetCtx(ctx, "a")
+		if ok {
+			err = errors.New("GetCtx found the order with a canceled context")
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("GetCtx error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("GetCtx with a canceled context waited for the cache lock")
+	}
+	if c.hits != 0 || c.misses != 0 {
+		t.Errorf("hits/misses = %d/%d, want a canceled read not counted", c.hits, c.misses)
+	}
+}
+
+func TestSetCtxCanceled(t *testing.T) {
+	c := New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.SetCtx(ctx, &model.Order{OrderUID: "a"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("SetCtx error = %v, want context.Canceled", err)
+	}
+	wantKeys(t, c)
+
+	if err := c.SetCtx(context.Background(), &model.Order{OrderUID: "a"}); err != nil {
+		t.Fatalf("SetCtx: %v", err)
+	}
+	if _, ok, err := c.GetCtx(context.Background(), "a"); !ok || err != nil {
+		t.Errorf("GetCtx = %v, %v; want the order", ok, err)
+	}
+}
+
+func TestGetEntryAccessStats(t *testing.T) {
+	c := New(2).(*OrderCache)
+	before := time.Now()
+	setOrders(c, "a")
+
+	entry, ok := c.GetEntry("a")
+	if !ok {
+		t.Fatal("GetEntry(a) missed")
+	}
+	if entry.Order.OrderUID != "a" || entry.AccessCount != 0 || !entry.LastAccessedAt.IsZero() {
+		t.Errorf("fresh entry = %+v, want order a without accesses", entry)
+	}
+	if entry.InsertedAt.Before(before) || entry.InsertedAt.After(time.Now()) {
+		t.Errorf("InsertedAt = %v, want the time of Set", entry.InsertedAt)
+	}
+	insertedAt := entry.InsertedAt
+
+	for range 3 {
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("Get(a) missed")
+		}
+	}
+	// Peek и сам GetEntry обращениями не считаются
+	c.Peek("a")
+	c.GetEntry("a")
+	// Перезапись заказа не сбрасывает сведения об использовании
+	setOrders(c, "a")
+
+	entry, _ = c.GetEntry("a")
+	if entry.AccessCount != 3 {
+		t.Errorf("AccessCount = %d, want 3", entry.AccessCount)
+	}
+	if entry.LastAccessedAt.Before(insertedAt) {
+		t.Errorf("LastAccessedAt = %v, want after InsertedAt %v", entry.LastAccessedAt, insertedAt)
+	}
+	if !entry.InsertedAt.Equal(insertedAt) {
+		t.Errorf("InsertedAt changed on overwrite: %v, want %v", entry.InsertedAt, insertedAt)
+	}
+	wantKeys(t, c, "a")
+
+	if _, ok := c.GetEntry("missing"); ok {
+		t.Error("GetEntry found a missing order")
+	}
+	if stats := c.Stats(); stats.Hits != 3 || stats.Misses != 0 {
+		t.Errorf("stats = %+v, want 3 hits from Get only", stats)
+	}
+
+	// Вытесненный и заново добавленный заказ начинает счет с нуля
+	setOrders(c, "b", "c", "a")
+	if entry, ok := c.GetEntry("a"); !ok || entry.AccessCount != 0 || !entry.LastAccessedAt.IsZero() {
+		t.Errorf("re-added entry = %+v, %v; want no accesses", entry, ok)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Политики кэширования для NewCache
+const (
+	// PolicyLRU кэш с вытеснением давно не использовавшихся заказов
+	PolicyLRU = "lru"
+	// PolicyNoop кэш, который ничего не хранит: все чтения идут в БД
+	PolicyNoop = "noop"
+)
+
+// Option дополнительная настройка кэша, создаваемого NewCache
+type Option func(*options)
+
+type options struct {
+	redisAddr string
+	redisTTL  time.Duration
+}
+
+// WithRedis задает адрес Redis и время жизни записей (0 — без истечения)
+// для политики redis; остальные политики эту настройку не используют
+func WithRedis(addr string, ttl time.Duration) Option {
+	return func(o *options) {
+		o.redisAddr = addr
+		o.redisTTL = ttl
+	}
+}
+
+// NewCache создает кэш заданной политики; пустая политика означает LRU.
+// Для политики redis адрес подключения задается WithRedis.
+func NewCache(kind string, maxSize int, opts ...Option) (Cache, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	switch strings.ToLower(kind) {
+	case "", PolicyLRU:
+		if maxSize < 1 {
+			return nil, fmt.Errorf("invalid cache max size %d", maxSize)
+		}
+		return New(maxSize), nil
+	case PolicyNoop:
+		return NewNoop(), nil
+	case PolicyRedis:
+		if o.redisAddr == "" {
+			return nil, fmt.Errorf("redis cache requires an address, see WithRedis")
+		}
+		return NewRedis(o.redisAddr, o.redisTTL, maxSize)
+	default:
+		return nil, fmt.Errorf("unknown cache policy %q, expected lru, noop or redis", kind)
+	}
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestNewCacheKinds(t *testing.T) {
+	redisServer := miniredis.RunT(t)
+
+	tests := []struct {
+		name string
+		kind string
+		opts []Option
+		want Cache
+	}{
+		{name: "default", kind: "", want: &OrderCache{}},
+		{name: "lru", kind: "lru", want: &OrderCache{}},
+		{name: "upper case", kind: "LRU", want: &OrderCache{}},
+		{name: "noop", kind: "noop", want: NoopCache{}},
+		{name: "redis", kind: "redis", opts: []Option{WithRedis(redisServer.Addr(), time.Minute)}, want: &RedisCache{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCache(tt.kind, 2, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewCache(%q): %v", tt.kind, err)
+			}
+			if closer, ok := c.(io.Closer); ok {
+				t.Cleanup(func() { closer.Close() })
+			}
+			if gotType, wantType := fmt.Sprintf("%T", c), fmt.Sprintf("%T", tt.want); gotType != wantType {
+				t.Fatalf("NewCache(%q) = %s, want %s", tt.kind, gotType, wantType)
+			}
+			if tt.want == (NoopCache{}) {
+				return
+			}
+
+			setOrders(c, "a")
+			if order, ok := c.Get("a"); !ok || order.OrderUID != "a" {
+				t.Errorf("Get(a) = %v, %v; want the stored order", order, ok)
+			}
+			if got := c.Size(); got != 1 {
+				t.Errorf("Size = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestNoopCacheAlwaysMisses(t *testing.T) {
+	c, err := NewCache(PolicyNoop, 0)
+	if err != nil {
+		t.Fatalf("NewCache(noop): %v", err)
+	}
+
+	setOrders(c, "a", "b")
+	c.Restore([]*model.Order{{OrderUID: "c"}, {OrderUID: "d"}})
+	if got := c.Size(); got != 0 {
+		t.Errorf("Size = %d, want 0", got)
+	}
+	for _, uid := range []string{"a", "c"} {
+		if _, ok := c.Get(uid); ok {
+			t.Errorf("Get(%s) hit, want a miss", uid)
+		}
+		if _, ok := c.Peek(uid); ok {
+			t.Errorf("Peek(%s) hit, want a miss", uid)
+		}
+	}
+	if keys := c.Keys(); len(keys) != 0 {
+		t.Errorf("Keys = %v, want none", keys)
+	}
+}
+
+func TestNewCacheErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		maxSize int
+	}{
+		{name: "unknown policy", kind: "lfu", maxSize: 10},
+		{name: "lru without size", kind: "lru"},
+		{name: "redis without address", kind: "redis", maxSize: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCache(tt.kind, tt.maxSize); err == nil {
+				t.Errorf("NewCache(%q, %d) succeeded, want error", tt.kind, tt.maxSize)
+			}
+		})
+	}
+}
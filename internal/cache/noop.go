@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// NoopCache кэш для развертываний без кэширования: ничего не хранит,
+// любое чтение — промах
+type NoopCache struct{}
+
+// NewNoop создает кэш, который ничего не хранит
+func NewNoop() Cache {
+	return NoopCache{}
+}
+
+func (NoopCache) GetCtx(context.Context, string) (*model.Order, bool, error) { return nil, false, nil }
+func (NoopCache) SetCtx(context.Context, *model.Order) error                 { return nil }
+
+func (NoopCache) Get(string) (*model.Order, bool)  { return nil, false }
+func (NoopCache) Peek(string) (*model.Order, bool) { return nil, false }
+func (NoopCache) Set(*model.Order)                 {}
+func (NoopCache) Restore([]*model.Order)           {}
+func (NoopCache) Size() int                        { return 0 }
+func (NoopCache) Stats() Stats                     { return Stats{} }
+func (NoopCache) Keys() []string                   { return nil }
+func (NoopCache) Delete(string)                    {}
+func (NoopCache) Clear()                           {}
+func (NoopCache) Resize(int)                       {}
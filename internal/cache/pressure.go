@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/metrics"
+)
+
+var cacheEvictions = metrics.NewCounterVec(
+	"cache_evictions_total",
+	"Number of orders evicted from the LRU cache",
+)
+
+// MonitorEvictions раз в window сравнивает число вытеснений из кэша с threshold
+// и пишет предупреждение, если кэш слишком мал для рабочего набора заказов.
+// Работает до закрытия stop.
+func MonitorEvictions(c Cache, threshold uint64, window time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	last := c.Stats().Evictions
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			stats := c.Stats()
+			if evicted := stats.Evictions - last; evicted >= threshold {
+				logger.Warnf("Cache eviction pressure: %d evictions in %v with max size %d, consider increasing CACHE_MAX_SIZE",
+					evicted, window, stats.MaxSize)
+			}
+			last = stats.Evictions
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMonitorEvictionsWarnsUnderPressure(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+
+	c := New(3)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		MonitorEvictions(c, 5, 10*time.Millisecond, stop)
+	}()
+
+	before := cacheEvictions.Get()
+	for i := range 10 {
+		setOrders(c, strconv.Itoa(i))
+	}
+	if got := c.Stats().Evictions; got != 7 {
+		t.Errorf("stats evictions = %d, want 7", got)
+	}
+	if got := cacheEvictions.Get() - before; got != 7 {
+		t.Errorf("cache_evictions_total grew by %v, want 7", got)
+	}
+
+	// Монитор запоминает счетчик при запуске, поэтому вытеснения продолжаются до предупреждения
+	deadline := time.Now().Add(time.Second)
+	for i := 10; logs.FilterMessageSnippet("Cache eviction pressure").Len() == 0; i++ {
+		if time.Now().After(deadline) {
+			t.Fatal("no eviction pressure warning while evicting above the threshold")
+		}
+		for j := range 5 {
+			setOrders(c, strconv.Itoa(i)+"-"+strconv.Itoa(j))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("MonitorEvictions did not return after stop was closed")
+	}
+	warning := logs.FilterMessageSnippet("Cache eviction pressure").All()[0].Message
+	if !strings.Contains(warning, "evictions in 10ms") || !strings.Contains(warning, "max size 3") {
+		t.Errorf("warning = %q, want eviction count, window and max size", warning)
+	}
+}
+
+func TestMonitorEvictionsQuietBelowThreshold(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+
+	c := New(3)
+	setOrders(c, "a", "b", "c", "d")
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		MonitorEvictions(c, 5, 5*time.Millisecond, stop)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	close(stop)
+	<-done
+	if n := logs.Len(); n != 0 {
+		t.Errorf("got %d warnings for evictions below the threshold", n)
+	}
+}
@@ -0,0 +1,332 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PolicyRedis общий для реплик кэш в Redis (см. NewRedis)
+const PolicyRedis = "redis"
+
+const (
+	// redisKeyPrefix префикс ключей заказов в Redis
+	redisKeyPrefix = "order:"
+	// redisIndexKey ZSET с UID закэшированных заказов; вес — время записи в миллисекундах
+	redisIndexKey = "orders:index"
+	// redisOpTimeout ограничение времени операций, вызванных без контекста
+	redisOpTimeout = time.Second
+	// redisBatchSize число заказов в одном pipeline
+	redisBatchSize = 1000
+)
+
+// RedisCache кэш заказов в Redis, общий для всех реплик сервиса. Заказы
+// хранятся в JSON под ключами order:<uid> и истекают через ttl. UID заказов
+// с временем записи дублируются в ZSET orders:index: по нему размер кэша
+// считается ZCARD без перебора ключей, а при превышении maxSize вытесняются
+// давно записанные заказы. Keys возвращает UID в порядке записи, а не LRU.
+type RedisCache struct {
+	client    *redis.Client
+	ttl       time.Duration
+	maxSize   atomic.Int64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// NewRedis подключается к Redis по адресу addr и создает кэш с временем жизни
+// записей ttl (0 — без истечения) и не более чем maxSize заказами (0 — без ограничения)
+func NewRedis(addr string, ttl time.Duration, maxSize int) (*RedisCache, error) {
+	if maxSize < 0 {
+		return nil, fmt.Errorf("invalid cache max size %d", maxSize)
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis ping error: %w", err)
+	}
+	c := &RedisCache{client: client, ttl: ttl}
+	c.maxSize.Store(int64(maxSize))
+	return c, nil
+}
+
+// Close закрывает соединения с Redis
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// GetCtx возвращает заказ по UID
+func (c *RedisCache) GetCtx(ctx context.Context, uid string) (*model.Order, bool, error) {
+	order, ok, err := c.get(ctx, uid)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return order, ok, nil
+}
+
+// get читает и разбирает заказ, не меняя счетчики
+func (c *RedisCache) get(ctx context.Context, uid string) (*model.Order, bool, error) {
+	data, err := c.client.Get(ctx, redisKeyPrefix+uid).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Ключ истек или вытеснен самим Redis: убираем его и из индекса,
+		// чтобы размер кэша не расходился с содержимым
+		c.client.ZRem(ctx, redisIndexKey, uid)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("redis get error: %w", err)
+	}
+
+	var order model.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, false, fmt.Errorf("decode cached order %s error: %w", uid, err)
+	}
+	return &order, true, nil
+}
+
+// SetCtx сохраняет заказ с временем жизни ttl и вытесняет давно записанные
+// заказы, если их стало больше maxSize
+func (c *RedisCache) SetCtx(ctx context.Context, order *model.Order) error {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("encode order error: %w", err)
+	}
+
+	var size *redis.IntCmd
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.queueSet(ctx, pipe, order.OrderUID, data, time.Now())
+		c.queuePruneExpired(ctx, pipe)
+		size = pipe.ZCard(ctx, redisIndexKey)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redis set error: %w", err)
+	}
+	return c.evictOverflow(ctx, size.Val())
+}
+
+// queueSet добавляет в pipeline запись заказа и его UID в индекс
+func (c *RedisCache) queueSet(ctx context.Context, pipe redis.Pipeliner, uid string, data []byte, now time.Time) {
+	pipe.Set(ctx, redisKeyPrefix+uid, data, c.ttl)
+	pipe.ZAdd(ctx, redisIndexKey, redis.Z{Score: float64(now.UnixMilli()), Member: uid})
+}
+
+// queuePruneExpired добавляет в pipeline удаление из индекса UID, ключи которых уже истекли по ttl
+func (c *RedisCache) queuePruneExpired(ctx context.Context, pipe redis.Pipeliner) {
+	if c.ttl <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-c.ttl).UnixMilli()
+	pipe.ZRemRangeByScore(ctx, redisIndexKey, "-inf", "("+strconv.FormatInt(cutoff, 10))
+}
+
+// evictOverflow вытесняет давно записанные заказы, если в индексе size заказов и это больше maxSize
+func (c *RedisCache) evictOverflow(ctx context.Context, size int64) error {
+	maxSize := c.maxSize.Load()
+	if maxSize <= 0 || size <= maxSize {
+		return nil
+	}
+
+	evicted, err := c.client.ZPopMin(ctx, redisIndexKey, size-maxSize).Result()
+	if err != nil {
+		return fmt.Errorf("redis evict error: %w", err)
+	}
+	if len(evicted) == 0 {
+		return nil
+	}
+	keys := make([]string, len(evicted))
+	for i, z := range evicted {
+		keys[i] = redisKeyPrefix + z.Member.(string)
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("redis evict error: %w", err)
+	}
+	c.evictions.Add(uint64(len(evicted)))
+	return nil
+}
+
+// Get возвращает заказ по UID; ошибка Redis считается промахом
+func (c *RedisCache) Get(uid string) (*model.Order, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	order, ok, err := c.GetCtx(ctx, uid)
+	if err != nil {
+		logger.Warnf("Redis cache get %s failed: %v", uid, err)
+	}
+	return order, ok
+}
+
+// Peek возвращает заказ по UID, не меняя счетчики попаданий
+func (c *RedisCache) Peek(uid string) (*model.Order, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	order, ok, err := c.get(ctx, uid)
+	if err != nil {
+		logger.Warnf("Redis cache peek %s failed: %v", uid, err)
+	}
+	return order, ok
+}
+
+// Set сохраняет заказ; ошибка Redis только логируется
+func (c *RedisCache) Set(order *model.Order) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	if err := c.SetCtx(ctx, order); err != nil {
+		logger.Warnf("Redis cache set %s failed: %v", order.OrderUID, err)
+	}
+}
+
+// Restore записывает не больше maxSize заказов пачками по redisBatchSize
+// через pipeline. Существующие ключи не удаляются, чтобы перезапуск одной
+// реплики не очищал кэш остальных: записи, которых нет в orders, истекут
+// по ttl или будут вытеснены, если кэш переполнен.
+func (c *RedisCache) Restore(orders []*model.Order) {
+	if maxSize := int(c.maxSize.Load()); maxSize > 0 && len(orders) > maxSize {
+		orders = orders[:maxSize]
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	for start := 0; start < len(orders); start += redisBatchSize {
+		batch := orders[start:min(start+redisBatchSize, len(orders))]
+		_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, order := range batch {
+				data, err := json.Marshal(order)
+				if err != nil {
+					logger.Errorf("Failed to encode order %s for redis: %v", order.OrderUID, err)
+					continue
+				}
+				c.queueSet(ctx, pipe, order.OrderUID, data, now)
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Errorf("Redis cache restore failed: %v", err)
+			return
+		}
+	}
+
+	if err := c.evictOverflow(ctx, int64(c.Size())); err != nil {
+		logger.Errorf("Redis cache restore failed: %v", err)
+	}
+}
+
+// Size возвращает число заказов в индексе кэша
+func (c *RedisCache) Size() int {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	var size *redis.IntCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.queuePruneExpired(ctx, pipe)
+		size = pipe.ZCard(ctx, redisIndexKey)
+		return nil
+	})
+	if err != nil {
+		logger.Warnf("Redis cache size failed: %v", err)
+		return 0
+	}
+	return int(size.Val())
+}
+
+// Stats возвращает статистику кэша. Evictions — вытеснения из-за maxSize,
+// выполненные этой репликой; вытеснения самим Redis не учитываются.
+func (c *RedisCache) Stats() Stats {
+	return Stats{
+		Size:      c.Size(),
+		MaxSize:   int(c.maxSize.Load()),
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// Keys возвращает UID закэшированных заказов от последнего записанного
+func (c *RedisCache) Keys() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	var uids *redis.StringSliceCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		c.queuePruneExpired(ctx, pipe)
+		uids = pipe.ZRevRange(ctx, redisIndexKey, 0, -1)
+		return nil
+	})
+	if err != nil {
+		logger.Warnf("Redis cache keys failed: %v", err)
+		return nil
+	}
+	return uids.Val()
+}
+
+// Delete удаляет заказ из кэша
+func (c *RedisCache) Delete(uid string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, redisKeyPrefix+uid)
+		pipe.ZRem(ctx, redisIndexKey, uid)
+		return nil
+	})
+	if err != nil {
+		logger.Warnf("Redis cache delete %s failed: %v", uid, err)
+	}
+}
+
+// Clear удаляет все заказы из кэша пачками по redisBatchSize
+func (c *RedisCache) Clear() {
+	ctx := context.Background()
+	for {
+		batch, err := c.client.ZPopMin(ctx, redisIndexKey, redisBatchSize).Result()
+		if err != nil {
+			logger.Warnf("Redis cache clear failed: %v", err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+		keys := make([]string, len(batch))
+		for i, z := range batch {
+			keys[i] = redisKeyPrefix + z.Member.(string)
+		}
+		if err := c.client.Del(ctx, keys...).Err(); err != nil {
+			logger.Warnf("Redis cache clear failed: %v", err)
+			return
+		}
+	}
+}
+
+// Resize меняет ограничение числа заказов и вытесняет лишние
+func (c *RedisCache) Resize(newMax int) {
+	if newMax < 0 {
+		return
+	}
+	c.maxSize.Store(int64(newMax))
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := c.evictOverflow(ctx, int64(c.Size())); err != nil {
+		logger.Warnf("Redis cache resize failed: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedis создает кэш поверх miniredis
+func newTestRedis(t *testing.T, ttl time.Duration, maxSize int) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	c, err := NewRedis(server.Addr(), ttl, maxSize)
+	if err != nil {
+		t.Fatalf("NewRedis: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, server
+}
+
+// redisOrders возвращает заказы prefix0..prefixN-1
+func redisOrders(prefix string, n int) []*model.Order {
+	orders := make([]*model.Order, n)
+	for i := range orders {
+		orders[i] = &model.Order{OrderUID: fmt.Sprintf("%s%d", prefix, i)}
+	}
+	return orders
+}
+
+func TestRedisGetSet(t *testing.T) {
+	c, server := newTestRedis(t, time.Hour, 10)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Get(a) hit in an empty cache")
+	}
+	c.Set(&model.Order{OrderUID: "a", TrackNumber: "WBILMTESTTRACK"})
+
+	order, ok := c.Get("a")
+	if !ok || order.TrackNumber != "WBILMTESTTRACK" {
+		t.Fatalf("Get(a) = %+v, %v; want the stored order", order, ok)
+	}
+	if _, ok := c.Peek("a"); !ok {
+		t.Error("Peek(a) missed the stored order")
+	}
+
+	// Заказ хранится в JSON под order:<uid> с временем жизни ttl
+	data, err := server.Get("order:a")
+	if err != nil {
+		t.Fatalf("order:a: %v", err)
+	}
+	var stored model.Order
+	if err := json.Unmarshal([]byte(data), &stored); err != nil || stored.OrderUID != "a" {
+		t.Errorf("order:a = %q, want the order JSON", data)
+	}
+	if ttl := server.TTL("order:a"); ttl != time.Hour {
+		t.Errorf("order:a TTL = %v, want 1h", ttl)
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 || stats.MaxSize != 10 {
+		t.Errorf("stats = %+v, want 1 hit, 1 miss, size 1 of 10 (Peek is not counted)", stats)
+	}
+}
+
+func TestRedisMaxSizeEvictsOldest(t *testing.T) {
+	c, server := newTestRedis(t, 0, 2)
+
+	setOrders(c, "a", "b", "c")
+
+	wantKeys(t, c, "c", "b")
+	if server.Exists("order:a") {
+		t.Error("evicted order a is still stored")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) hit an evicted order")
+	}
+	if got := c.Stats().Evictions; got != 1 {
+		t.Errorf("evictions = %d, want 1", got)
+	}
+
+	c.Resize(1)
+	wantKeys(t, c, "c")
+	if got := c.Stats().Evictions; got != 2 {
+		t.Errorf("evictions after Resize = %d, want 2", got)
+	}
+}
+
+func TestRedisRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxSize  int
+		orders   int
+		wantSize int
+	}{
+		// Больше одной пачки pipeline, чтобы проверить разбиение
+		{name: "unlimited", orders: redisBatchSize*2 + 5, wantSize: redisBatchSize*2 + 5},
+		{name: "capped", maxSize: 3, orders: redisBatchSize + 1, wantSize: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, server := newTestRedis(t, time.Hour, tt.maxSize)
+			orders := redisOrders("restore", tt.orders)
+
+			c.Restore(orders)
+
+			if got := c.Size(); got != tt.wantSize {
+				t.Fatalf("Size = %d, want %d", got, tt.wantSize)
+			}
+			if got := len(server.Keys()) - 1; got != tt.wantSize {
+				t.Errorf("stored %d orders in Redis, want %d", got, tt.wantSize)
+			}
+			// Как и LRU, восстанавливаются первые maxSize заказов
+			for _, order := range orders[:tt.wantSize] {
+				if !server.Exists("order:" + order.OrderUID) {
+					t.Fatalf("order %s was not restored", order.OrderUID)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisRestoreKeepsOtherReplicasOrders(t *testing.T) {
+	c, _ := newTestRedis(t, time.Hour, 0)
+	setOrders(c, "other")
+
+	c.Restore(redisOrders("restore", 2))
+
+	keys := c.Keys()
+	slices.Sort(keys)
+	if want := []string{"other", "restore0", "restore1"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
+
+func TestRedisSizeForgetsExpiredOrders(t *testing.T) {
+	c, server := newTestRedis(t, time.Minute, 0)
+	setOrders(c, "fresh", "stale")
+
+	// Запись stale сделана раньше ttl назад: ключ истек, запись в индексе устарела
+	server.Del("order:stale")
+	server.ZAdd(redisIndexKey, float64(time.Now().Add(-2*time.Minute).UnixMilli()), "stale")
+
+	wantKeys(t, c, "fresh")
+}
+
+func TestRedisMissForgetsEvictedKey(t *testing.T) {
+	c, server := newTestRedis(t, 0, 0)
+	setOrders(c, "a", "b")
+
+	// Ключ вытеснен самим Redis (maxmemory-policy) без изменения индекса
+	server.Del("order:a")
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Get(a) hit a deleted key")
+	}
+	wantKeys(t, c, "b")
+}
+
+func TestRedisDeleteAndClear(t *testing.T) {
+	c, server := newTestRedis(t, time.Hour, 0)
+	setOrders(c, "a", "b", "c")
+
+	c.Delete("b")
+	wantKeys(t, c, "c", "a")
+	if server.Exists("order:b") {
+		t.Error("deleted order b is still stored")
+	}
+
+	c.Clear()
+	wantKeys(t, c)
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("Redis keys after Clear = %v, want none", keys)
+	}
+}
+
+func TestNewRedisErrors(t *testing.T) {
+	server := miniredis.RunT(t)
+	if _, err := NewRedis(server.Addr(), time.Hour, -1); err == nil {
+		t.Error("NewRedis with a negative max size succeeded")
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+)
+
+// Типизированные геттеры переменных окружения. Незаданная переменная дает
+// значение по умолчанию; неразбираемое значение тоже заменяется значением по
+// умолчанию с предупреждением в лог, чтобы опечатка в одной переменной была
+// видна, но не мешала запуску. Логгер должен быть уже инициализирован.
+
+// GetString возвращает значение переменной или def, если она не задана
+func GetString(key, def string) string {
+	if env := os.Getenv(key); env != "" {
+		return env
+	}
+	return def
+}
+
+// GetInt возвращает целое значение переменной
+func GetInt(key string, def int) int {
+	env := os.Getenv(key)
+	if env == "" {
+		return def
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(env))
+	if err != nil {
+		warnInvalid(key, env, def)
+		return def
+	}
+	return value
+}
+
+// GetDuration возвращает длительность в формате time.ParseDuration (например, 30s)
+func GetDuration(key string, def time.Duration) time.Duration {
+	env := os.Getenv(key)
+	if env == "" {
+		return def
+	}
+	value, err := time.ParseDuration(strings.TrimSpace(env))
+	if err != nil {
+		warnInvalid(key, env, def)
+		return def
+	}
+	return value
+}
+
+// GetBool возвращает логическое значение переменной (true/false, 1/0)
+func GetBool(key string, def bool) bool {
+	env := os.Getenv(key)
+	if env == "" {
+		return def
+	}
+	value, err := strconv.ParseBool(strings.TrimSpace(env))
+	if err != nil {
+		warnInvalid(key, env, def)
+		return def
+	}
+	return value
+}
+
+// GetStringSlice возвращает список значений, перечисленных через запятую.
+// Пробелы вокруг элементов и пустые элементы отбрасываются.
+func GetStringSlice(key string, def []string) []string {
+	env := os.Getenv(key)
+	if env == "" {
+		return def
+	}
+	list := SplitList(env)
+	if len(list) == 0 {
+		warnInvalid(key, env, def)
+		return def
+	}
+	return list
+}
+
+// SplitList разбирает список значений, перечисленных через запятую
+func SplitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// warnInvalid сообщает о неразбираемом значении переменной
+func warnInvalid(key, env string, def any) {
+	logger.Warnf("Invalid %s %q, using default %v", key, env, def)
+}
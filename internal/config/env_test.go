@@ -0,0 +1,88 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// getterCase значение переменной и ожидаемый результат геттера
+type getterCase[T any] struct {
+	name     string
+	env      string
+	want     T
+	wantWarn bool
+}
+
+// runGetterCases проверяет геттер get на случаях cases для переменной TEST_CONFIG_VALUE
+func runGetterCases[T any](t *testing.T, cases []getterCase[T], get func(key string) T, equal func(a, b T) bool) {
+	t.Helper()
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := observeLogs(t)
+			t.Setenv("TEST_CONFIG_VALUE", tt.env)
+
+			if got := get("TEST_CONFIG_VALUE"); !equal(got, tt.want) {
+				t.Errorf("value of %q = %v, want %v", tt.env, got, tt.want)
+			}
+			warned := logs.FilterMessageSnippet("Invalid TEST_CONFIG_VALUE").Len() == 1
+			if warned != tt.wantWarn {
+				t.Errorf("warning logged = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func equalComparable[T comparable](a, b T) bool { return a == b }
+
+func TestGetString(t *testing.T) {
+	runGetterCases(t, []getterCase[string]{
+		{name: "unset", want: "default"},
+		{name: "set", env: "value", want: "value"},
+		{name: "spaces kept", env: " value ", want: " value "},
+	}, func(key string) string { return GetString(key, "default") }, equalComparable[string])
+}
+
+func TestGetInt(t *testing.T) {
+	runGetterCases(t, []getterCase[int]{
+		{name: "unset", want: 7},
+		{name: "set", env: "42", want: 42},
+		{name: "negative", env: "-3", want: -3},
+		{name: "surrounding spaces", env: " 42 ", want: 42},
+		{name: "not a number", env: "four", want: 7, wantWarn: true},
+		{name: "fraction", env: "1.5", want: 7, wantWarn: true},
+	}, func(key string) int { return GetInt(key, 7) }, equalComparable[int])
+}
+
+func TestGetDuration(t *testing.T) {
+	runGetterCases(t, []getterCase[time.Duration]{
+		{name: "unset", want: time.Minute},
+		{name: "set", env: "30s", want: 30 * time.Second},
+		{name: "compound", env: "1h30m", want: 90 * time.Minute},
+		{name: "zero", env: "0", want: 0},
+		{name: "no unit", env: "30", want: time.Minute, wantWarn: true},
+		{name: "garbage", env: "soon", want: time.Minute, wantWarn: true},
+	}, func(key string) time.Duration { return GetDuration(key, time.Minute) }, equalComparable[time.Duration])
+}
+
+func TestGetBool(t *testing.T) {
+	runGetterCases(t, []getterCase[bool]{
+		{name: "unset", want: true},
+		{name: "false", env: "false", want: false},
+		{name: "zero", env: "0", want: false},
+		{name: "one", env: "1", want: true},
+		{name: "upper case", env: "FALSE", want: false},
+		{name: "yes", env: "yes", want: true, wantWarn: true},
+	}, func(key string) bool { return GetBool(key, true) }, equalComparable[bool])
+}
+
+func TestGetStringSlice(t *testing.T) {
+	def := []string{"localhost:9092"}
+	runGetterCases(t, []getterCase[[]string]{
+		{name: "unset", want: def},
+		{name: "single", env: "kafka:9092", want: []string{"kafka:9092"}},
+		{name: "several", env: "kafka1:9092,kafka2:9092", want: []string{"kafka1:9092", "kafka2:9092"}},
+		{name: "spaces and empty items", env: " kafka1:9092 ,, kafka2:9092 ,", want: []string{"kafka1:9092", "kafka2:9092"}},
+		{name: "only separators", env: " , ,", want: def, wantWarn: true},
+	}, func(key string) []string { return GetStringSlice(key, def) }, slices.Equal[[]string])
+}
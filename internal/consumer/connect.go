@@ -0,0 +1,41 @@
+package consumer
+
+import (
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+
+	"github.com/IBM/sarama"
+)
+
+// Задержки между попытками подключения; переменные, чтобы тесты не ждали секундами
+var (
+	connectInitialBackoff = time.Second
+	connectMaxBackoff     = 30 * time.Second
+)
+
+// newClient создает клиента Kafka; переменная позволяет подменить подключение
+var newClient = sarama.NewClient
+
+// connect подключается к Kafka, повторяя попытки с экспоненциальной задержкой,
+// пока не истечет timeout. При timeout <= 0 выполняется одна попытка.
+func connect(brokers []string, config *sarama.Config, timeout time.Duration) (sarama.Client, error) {
+	deadline := time.Now().Add(timeout)
+	backoff := connectInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		client, err := newClient(brokers, config)
+		if err == nil {
+			return client, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, err
+		}
+		wait := min(backoff, remaining)
+		logger.Warnf("Failed to connect to Kafka (attempt %d): %v, retrying in %v", attempt, err, wait)
+		time.Sleep(wait)
+		backoff = min(backoff*2, connectMaxBackoff)
+	}
+}
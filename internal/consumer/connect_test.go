@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// stubConnect подменяет newClient: первые failures попыток завершаются ошибкой.
+// Возвращает указатель на число попыток.
+func stubConnect(t *testing.T, failures int) *int {
+	t.Helper()
+	previous, initial, maximum := newClient, connectInitialBackoff, connectMaxBackoff
+	connectInitialBackoff, connectMaxBackoff = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { newClient, connectInitialBackoff, connectMaxBackoff = previous, initial, maximum })
+
+	var attempts int
+	newClient = func([]string, *sarama.Config) (sarama.Client, error) {
+		attempts++
+		if attempts <= failures {
+			return nil, errors.New("kafka: client has run out of available brokers")
+		}
+		// Клиент не используется тестами, важно лишь отсутствие ошибки
+		return nil, nil
+	}
+	return &attempts
+}
+
+func TestConnectRetriesUntilSuccess(t *testing.T) {
+	attempts := stubConnect(t, 4)
+	if _, err := connect([]string{"kafka:9092"}, sarama.NewConfig(), time.Second); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if *attempts != 5 {
+		t.Errorf("attempts = %d, want 5", *attempts)
+	}
+}
+
+func TestConnectGivesUpAfterTimeout(t *testing.T) {
+	attempts := stubConnect(t, 1<<30)
+	start := time.Now()
+	if _, err := connect([]string{"kafka:9092"}, sarama.NewConfig(), 30*time.Millisecond); err == nil {
+		t.Fatal("connect succeeded, want the last connection error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("connect took %v with a 30ms timeout", elapsed)
+	}
+	if *attempts < 2 {
+		t.Errorf("attempts = %d, want retries within the timeout", *attempts)
+	}
+}
+
+func TestConnectSingleAttemptWithoutTimeout(t *testing.T) {
+	attempts := stubConnect(t, 1)
+	if _, err := connect([]string{"kafka:9092"}, sarama.NewConfig(), 0); err == nil {
+		t.Fatal("connect succeeded, want error without retries")
+	}
+	if *attempts != 1 {
+		t.Errorf("attempts = %d, want 1", *attempts)
+	}
+}
@@ -2,65 +2,322 @@ package consumer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go-kafka-postgres/internal/cache"
 	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/kafka"
 	"go-kafka-postgres/internal/logger"
 	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/store"
+	"go-kafka-postgres/internal/validator"
 
 	"github.com/IBM/sarama"
+	"go.uber.org/zap"
 )
 
+// GroupID идентификатор группы потребителей заказов
+const GroupID = "orders-consumer-group"
+
+// Options настройки потребителя
+type Options struct {
+	// LagInterval период вычисления отставания потребителя; 0 отключает расчет
+	LagInterval time.Duration
+	// ManualCommit отключает автокоммит: смещения фиксируются явно
+	// только после успешной записи заказа в БД и кэш
+	ManualCommit bool
+	// AutoCommitInterval период автокоммита смещений; 0 — значение sarama (1s).
+	// Чем он больше, тем больше сообщений будет обработано повторно после сбоя
+	AutoCommitInterval time.Duration
+	// Validator валидатор заказов; nil означает проверку по умолчанию
+	Validator *validator.Validator
+	// Store общее хранилище заказов; nil означает хранилище по умолчанию поверх cache и db
+	Store *store.OrderStore
+	// InitialOffset начальное смещение для новой группы: "oldest" или "newest" (по умолчанию)
+	InitialOffset string
+	// RebalanceStrategy стратегия распределения партиций: "roundrobin" (по умолчанию), "range" или "sticky"
+	RebalanceStrategy string
+	// RejectKeyMismatch пропускает сообщения, ключ которых не совпадает с order_uid;
+	// по умолчанию несовпадение только логируется
+	RejectKeyMismatch bool
+	// ProcessingTimeout ограничивает время сохранения одного заказа; 0 — без ограничения
+	ProcessingTimeout time.Duration
+	// StrictJSON отклоняет сообщения с полями, которых нет в формате заказа,
+	// чтобы рано обнаруживать расхождение схемы у продюсера; по умолчанию такие поля игнорируются
+	StrictJSON bool
+	// Tracer трассировка обработки сообщений; nil — только trace ID из traceparent в логах
+	Tracer Tracer
+	// MaxMessageBytes максимальный размер тела сообщения; большие сообщения
+	// отклоняются без разбора. 0 — без ограничения
+	MaxMessageBytes int
+	// DLQTopic топик для отклоненных сообщений (неразбираемых, невалидных,
+	// слишком больших); пустое значение — такие сообщения только логируются.
+	// При заданном топике в DLQ отправляются и заказы, которые БД отвергла из-за
+	// их содержимого (db.IsPermanent), вместо бесконечных повторов записи
+	DLQTopic string
+	// DLQAlertThreshold число отправок в DLQ за DLQAlertWindow, при котором
+	// вызывается OnDLQAlert; 0 отключает оповещение
+	DLQAlertThreshold int
+	// DLQAlertWindow окно подсчета отправок в DLQ
+	DLQAlertWindow time.Duration
+	// OnDLQAlert оповещение о всплеске отклоненных сообщений; nil — предупреждение в лог
+	OnDLQAlert DLQAlertFunc
+	// DryRun только разбирает и валидирует сообщения, не изменяя БД и кэш
+	DryRun bool
+	// DryRunMarkOffsets отмечает сообщения обработанными в режиме DryRun
+	DryRunMarkOffsets bool
+	// Writers число горутин, записывающих заказы в БД (минимум 1)
+	Writers int
+	// WriteBuffer емкость очереди между чтением сообщений и записью в БД
+	WriteBuffer int
+	// ConnectTimeout сколько повторять подключение к недоступной Kafka при старте; 0 — одна попытка
+	ConnectTimeout time.Duration
+	// SessionTimeout таймаут сессии в группе; 0 — значение sarama по умолчанию (10s)
+	SessionTimeout time.Duration
+	// HeartbeatInterval период heartbeat; 0 — значение sarama по умолчанию (3s)
+	HeartbeatInterval time.Duration
+	// MaxTimestampDrift допустимое расхождение метки времени сообщения и date_created
+	// заказа; сообщения с большим расхождением логируются, но не отклоняются. 0 — без проверки
+	MaxTimestampDrift time.Duration
+	// DedupWindow сколько помнить сохраненные заказы, чтобы пропускать их повторную
+	// доставку с тем же содержимым; 0 отключает проверку
+	DedupWindow time.Duration
+	// DedupMaxSize максимальное число запоминаемых заказов
+	DedupMaxSize int
+	// Security параметры SASL и TLS; пустые означают plaintext-подключение
+	Security kafka.Security
+	// StallTimeout сколько потребитель может не отмечать смещения при ненулевом
+	// отставании, прежде чем сторожевой таймер пересоздаст группу; 0 отключает проверку
+	StallTimeout time.Duration
+	// ShutdownGrace сколько Close ждет завершения записи уже полученных сообщений,
+	// прежде чем отменить ее; 0 — отменять сразу
+	ShutdownGrace time.Duration
+}
+
+// parseInitialOffset преобразует название начального смещения в константу sarama
+func parseInitialOffset(value string) (int64, error) {
+	switch value {
+	case "", "newest":
+		return sarama.OffsetNewest, nil
+	case "oldest":
+		return sarama.OffsetOldest, nil
+	default:
+		return 0, fmt.Errorf("invalid initial offset %q, expected oldest or newest", value)
+	}
+}
+
+// parseRebalanceStrategy преобразует название стратегии ребалансировки в стратегию sarama
+func parseRebalanceStrategy(value string) (sarama.BalanceStrategy, error) {
+	switch value {
+	case "", "roundrobin":
+		return sarama.NewBalanceStrategyRoundRobin(), nil
+	case "range":
+		return sarama.NewBalanceStrategyRange(), nil
+	case "sticky":
+		return sarama.NewBalanceStrategySticky(), nil
+	default:
+		return nil, fmt.Errorf("invalid rebalance strategy %q, expected roundrobin, range or sticky", value)
+	}
+}
+
+// configureCommit включает автокоммит смещений, если не задан ManualCommit, и
+// задает его период; без AutoCommitInterval остается значение sarama
+func configureCommit(config *sarama.Config, opts Options) {
+	config.Consumer.Offsets.AutoCommit.Enable = !opts.ManualCommit
+	if opts.AutoCommitInterval > 0 {
+		config.Consumer.Offsets.AutoCommit.Interval = opts.AutoCommitInterval
+	}
+	if !opts.ManualCommit {
+		logger.Infof("Offsets are auto-committed every %v", config.Consumer.Offsets.AutoCommit.Interval)
+	}
+}
+
+// validateGroupTimings проверяет, что heartbeat успевает отправиться
+// не менее трех раз за таймаут сессии, как рекомендует Kafka
+func validateGroupTimings(sessionTimeout, heartbeatInterval time.Duration) error {
+	if sessionTimeout <= 0 || heartbeatInterval <= 0 {
+		return fmt.Errorf("session timeout and heartbeat interval must be positive")
+	}
+	if heartbeatInterval >= sessionTimeout/3 {
+		return fmt.Errorf("heartbeat interval %v must be less than a third of session timeout %v",
+			heartbeatInterval, sessionTimeout)
+	}
+	return nil
+}
+
 // Consumer представляет потребителя Kafka для обработки заказов
 type Consumer struct {
-	consumer sarama.ConsumerGroup
+	client   sarama.Client
+	admin    sarama.ClusterAdmin
+	opts     Options
 	cache    cache.Cache
 	db       db.DatabaseInterface
-	topic    string
+	topics   []string
 	groupID  string
 	stopChan chan struct{}
 	wg       sync.WaitGroup
+	joined   atomic.Bool
+	paused   pauseGate
+
+	// groupMu защищает consumer: сторожевой таймер пересоздает группу (см. watchdog.go)
+	groupMu  sync.RWMutex
+	consumer sarama.ConsumerGroup
+	// lastProgress время последней отметки смещения (UnixNano)
+	lastProgress atomic.Int64
+
+	offsetsMu sync.Mutex
+	processed map[string]map[int32]int64
+
+	deadLetters *deadLetters
+
+	writeJobs []chan *writeJob
+	writersWg sync.WaitGroup
+
+	// writeCtx контекст записи заказов; в отличие от контекста сессии
+	// не отменяется при Close сразу, а только по истечении ShutdownGrace
+	writeCtx    context.Context
+	writeCancel context.CancelFunc
 }
 
 // New создает нового потребителя Kafka (ConsumerGroup)
-func New(brokers []string, topic string, cache cache.Cache, db db.DatabaseInterface) (*Consumer, error) {
-	config := sarama.NewConfig()
-	config.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRoundRobin()
-	config.Consumer.Offsets.Initial = sarama.OffsetNewest
-	config.Consumer.Offsets.AutoCommit.Enable = true
+func New(brokers []string, topics []string, cache cache.Cache, db db.DatabaseInterface, opts Options) (*Consumer, error) {
+	config, err := kafka.NewConfig(opts.Security)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := parseRebalanceStrategy(opts.RebalanceStrategy)
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{strategy}
+	initialOffset, err := parseInitialOffset(opts.InitialOffset)
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer.Offsets.Initial = initialOffset
+
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("no topics to consume")
+	}
+	configureCommit(config, opts)
+	if opts.DLQTopic != "" {
+		// Синхронный продюсер DLQ создается из того же клиента и требует подтверждений
+		config.Producer.Return.Successes = true
+		config.Producer.RequiredAcks = sarama.WaitForAll
+	}
+
+	if opts.SessionTimeout > 0 {
+		config.Consumer.Group.Session.Timeout = opts.SessionTimeout
+	}
+	if opts.HeartbeatInterval > 0 {
+		config.Consumer.Group.Heartbeat.Interval = opts.HeartbeatInterval
+	}
+	if err := validateGroupTimings(config.Consumer.Group.Session.Timeout, config.Consumer.Group.Heartbeat.Interval); err != nil {
+		return nil, err
+	}
+
+	groupID := GroupID
+	if initialOffset == sarama.OffsetOldest {
+		logger.Infof("Consumer group %s initial offset: oldest", groupID)
+	} else {
+		logger.Infof("Consumer group %s initial offset: newest", groupID)
+	}
+	logger.Infof("Consumer group %s session timeout %v, heartbeat interval %v", groupID,
+		config.Consumer.Group.Session.Timeout, config.Consumer.Group.Heartbeat.Interval)
+
+	client, err := connect(brokers, config, opts.ConnectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("connect to kafka error: %w", err)
+	}
 
-	groupID := "orders-consumer-group"
+	// ClusterAdmin использует тот же клиент и закрывает его при Close
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
 
-	consumer, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	consumer, err := sarama.NewConsumerGroupFromClient(groupID, client)
 	if err != nil {
+		admin.Close()
 		return nil, err
 	}
 
+	var dlq *deadLetters
+	if opts.DLQTopic != "" {
+		var alarm *dlqAlarm
+		if opts.DLQAlertThreshold > 0 && opts.DLQAlertWindow > 0 {
+			alarm = newDLQAlarm(opts.DLQAlertThreshold, opts.DLQAlertWindow, opts.OnDLQAlert)
+		}
+		dlq, err = newDeadLetters(client, opts.DLQTopic, alarm)
+		if err != nil {
+			consumer.Close()
+			admin.Close()
+			return nil, err
+		}
+		logger.Infof("Rejected messages will be sent to DLQ topic %s", opts.DLQTopic)
+	}
+
+	writeCtx, writeCancel := context.WithCancel(context.Background())
+
 	return &Consumer{
-		consumer: consumer,
-		cache:    cache,
-		db:       db,
-		topic:    topic,
-		groupID:  groupID,
-		stopChan: make(chan struct{}),
+		client:      client,
+		admin:       admin,
+		consumer:    consumer,
+		opts:        opts,
+		cache:       cache,
+		db:          db,
+		topics:      topics,
+		groupID:     groupID,
+		stopChan:    make(chan struct{}),
+		processed:   make(map[string]map[int32]int64),
+		deadLetters: dlq,
+		writeCtx:    writeCtx,
+		writeCancel: writeCancel,
 	}, nil
 }
 
 // Start начинает потребление сообщений
 func (c *Consumer) Start() {
+	handler := &consumerHandler{
+		store:             c.opts.Store,
+		manualCommit:      c.opts.ManualCommit,
+		validator:         c.opts.Validator,
+		rejectKeyMismatch: c.opts.RejectKeyMismatch,
+		processingTimeout: c.opts.ProcessingTimeout,
+		dryRun:            c.opts.DryRun,
+		dryRunMarkOffsets: c.opts.DryRunMarkOffsets,
+		onSetup:           func() { c.joined.Store(true) },
+		onMarked:          c.recordOffset,
+		onClaim:           c.pauseClaim,
+		paused:            &c.paused,
+		writeCtx:          c.writeCtx,
+		maxTimestampDrift: c.opts.MaxTimestampDrift,
+		strictJSON:        c.opts.StrictJSON,
+		tracer:            c.opts.Tracer,
+		maxMessageBytes:   c.opts.MaxMessageBytes,
+		deadLetters:       c.deadLetters,
+	}
+	if c.opts.DedupWindow > 0 && c.opts.DedupMaxSize > 0 {
+		handler.recent = newRecentOrders(c.opts.DedupWindow, c.opts.DedupMaxSize)
+	}
+	if handler.store == nil {
+		handler.store = store.New(c.cache, c.db, store.Options{})
+	}
+	if handler.validator == nil {
+		handler.validator = validator.New(validator.Options{FutureSkew: validator.DefaultFutureSkew})
+	}
+	c.startWriters(handler)
+
 	c.wg.Add(1)
 	go func() {
 		defer c.wg.Done()
-		handler := &consumerHandler{
-			cache: c.cache,
-			db:    c.db,
-		}
 		for {
-			if err := c.consumer.Consume(context.Background(), []string{c.topic}, handler); err != nil {
+			if err := c.group().Consume(context.Background(), c.topics, handler); err != nil {
 				logger.Errorf("Consumer error: %v", err)
 			}
 			select {
@@ -70,113 +327,270 @@ func (c *Consumer) Start() {
 			}
 		}
 	}()
-	logger.Infof("Started Kafka consumer group %s for topic %s", c.groupID, c.topic)
+
+	if c.opts.DryRun {
+		logger.Infof("Consumer is running in dry-run mode, orders will not be saved")
+	}
+
+	if c.opts.StallTimeout > 0 {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.watchdog()
+		}()
+	}
+
+	if c.opts.LagInterval > 0 {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.monitorLag()
+		}()
+	}
+
+	logger.Infof("Started Kafka consumer group %s for topics %v", c.groupID, c.topics)
 }
 
-// consumerHandler реализует sarama.ConsumerGroupHandler
-type consumerHandler struct {
-	cache cache.Cache
-	db    db.DatabaseInterface
+// Joined сообщает, присоединился ли потребитель к группе хотя бы один раз
+func (c *Consumer) Joined() bool {
+	return c.joined.Load()
 }
 
-func (h *consumerHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
-func (h *consumerHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+// recordOffset запоминает наибольшее обработанное смещение партиции
+func (c *Consumer) recordOffset(topic string, partition int32, offset int64) {
+	c.lastProgress.Store(time.Now().UnixNano())
 
-func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for message := range claim.Messages() {
-		logger.Infof("Received message from partition %d at offset %d", message.Partition, message.Offset)
+	c.offsetsMu.Lock()
+	defer c.offsetsMu.Unlock()
 
-		var order model.Order
-		if err := json.Unmarshal(message.Value, &order); err != nil {
-			logger.Errorf("Failed to unmarshal order: %v. Message: %s", err, string(message.Value))
-			session.MarkMessage(message, "")
-			continue
-		}
+	partitions, ok := c.processed[topic]
+	if !ok {
+		partitions = make(map[int32]int64)
+		c.processed[topic] = partitions
+	}
+	if current, ok := partitions[partition]; !ok || offset > current {
+		partitions[partition] = offset
+	}
+}
 
-		if err := validateOrder(&order); err != nil {
-			logger.Errorf("Invalid order %s: %v. Skipping.", order.OrderUID, err)
-			session.MarkMessage(message, "")
-			continue
-		}
+// ProcessedOffsets возвращает наибольшее обработанное смещение по каждой партиции топиков
+func (c *Consumer) ProcessedOffsets() map[string]map[int32]int64 {
+	c.offsetsMu.Lock()
+	defer c.offsetsMu.Unlock()
 
-		if err := h.db.InsertOrder(&order); err != nil {
-			logger.Errorf("Failed to insert order %s into database: %v", order.OrderUID, err)
-			continue
+	offsets := make(map[string]map[int32]int64, len(c.processed))
+	for topic, partitions := range c.processed {
+		copied := make(map[int32]int64, len(partitions))
+		for partition, offset := range partitions {
+			copied[partition] = offset
 		}
+		offsets[topic] = copied
+	}
+	return offsets
+}
 
-		h.cache.Set(&order)
-		logger.Infof("Order %s processed successfully", order.OrderUID)
+// consumerHandler реализует sarama.ConsumerGroupHandler
+type consumerHandler struct {
+	store             *store.OrderStore
+	manualCommit      bool
+	validator         *validator.Validator
+	rejectKeyMismatch bool
+	processingTimeout time.Duration
+	dryRun            bool
+	dryRunMarkOffsets bool
+	onSetup           func()
+	onMarked          func(topic string, partition int32, offset int64)
+	onClaim           func(topic string, partition int32)
+	// paused пауза потребителя; nil — обработчик не приостанавливается
+	paused            *pauseGate
+	jobs              []chan *writeJob
+	writeCtx          context.Context
+	recent            *recentOrders
+	maxTimestampDrift time.Duration
+	strictJSON        bool
+	tracer            Tracer
+	maxMessageBytes   int
+	deadLetters       *deadLetters
+}
 
-		session.MarkMessage(message, "")
+func (h *consumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	if h.onSetup != nil {
+		h.onSetup()
 	}
 	return nil
 }
 
-// Функция валидации
-func validateOrder(order *model.Order) error {
-	now := time.Now().Add(1 * time.Minute)
+func (h *consumerHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
 
-	if order.DateCreated.After(now) {
-		return fmt.Errorf("date_created is in the future: %v", order.DateCreated)
+// prepare разбирает и валидирует заказ из сообщения. Если заказ нужно
+// сохранить, возвращается заказ и resultProcessed, иначе — итог обработки
+// и, если сообщение отклонено, причина.
+func (h *consumerHandler) prepare(message *sarama.ConsumerMessage) (*model.Order, processResult, error) {
+	// Размер проверяется до любого разбора JSON, чтобы не выделять память под огромное сообщение
+	if h.maxMessageBytes > 0 && len(message.Value) > h.maxMessageBytes {
+		err := fmt.Errorf("message size %d exceeds limit %d bytes", len(message.Value), h.maxMessageBytes)
+		logger.Error("Oversized message, skipping", append(messageFields(message), zap.Error(err))...)
+		return nil, resultOversized, err
 	}
 
-	if order.OrderUID == "" {
-		return fmt.Errorf("missing order_uid")
-	}
-	if order.TrackNumber == "" {
-		return fmt.Errorf("missing track_number")
+	version, schema, err := resolveSchema(message)
+	if err != nil {
+		logger.Error("Failed to resolve schema, skipping", append(messageFields(message), zap.Error(err))...)
+		return nil, resultUnsupportedSchema, err
 	}
-	if order.Entry == "" {
-		return fmt.Errorf("missing entry")
+
+	decodeStart := time.Now()
+	decode := schema.Decode
+	if h.strictJSON && schema.DecodeStrict != nil {
+		decode = schema.DecodeStrict
 	}
-	if order.Locale == "" {
-		return fmt.Errorf("missing locale")
+	order, err := decode(message.Value)
+	observeStage(stageDecode, decodeStart)
+	if err != nil {
+		logger.Error("Failed to unmarshal order", append(messageFields(message),
+			zap.Int("schema_version", version), zap.Error(err), zap.ByteString("value", message.Value))...)
+		return nil, resultDecodeError, err
 	}
-	if order.CustomerID == "" {
-		return fmt.Errorf("missing customer_id")
+	observeTimestamps(message, order, time.Now(), h.maxTimestampDrift)
+
+	if key := string(message.Key); key != order.OrderUID {
+		if h.rejectKeyMismatch {
+			logger.Error("Message key does not match order_uid, skipping", append(messageFields(message),
+				zap.String("key", key), zap.String("order_uid", order.OrderUID))...)
+			return nil, resultKeyMismatch, fmt.Errorf("message key %q does not match order_uid %q", key, order.OrderUID)
+		}
+		logger.Warn("Message key does not match order_uid", append(messageFields(message),
+			zap.String("key", key), zap.String("order_uid", order.OrderUID))...)
 	}
-	if order.DeliveryService == "" {
-		return fmt.Errorf("missing delivery_service")
+
+	validateStart := time.Now()
+	err = schema.Validate(h.validator, order)
+	observeStage(stageValidate, validateStart)
+	if err != nil {
+		field := "unknown"
+		var validationErr *validator.ValidationError
+		if errors.As(err, &validationErr) {
+			field = validationErr.Field
+		}
+		validationErrors.Inc(field)
+		logger.Error("Invalid order, skipping", append(messageFields(message),
+			zap.String("order_uid", order.OrderUID), zap.String("field", field), zap.Error(err))...)
+		return nil, resultInvalid, err
 	}
-	if order.Shardkey == "" {
-		return fmt.Errorf("missing shardkey")
+
+	if h.dryRun {
+		logger.Info("Dry run: order is valid, not saving", zap.String("order_uid", order.OrderUID))
+		return nil, resultDryRun, nil
 	}
-	if order.OofShard == "" {
-		return fmt.Errorf("missing oof_shard")
+
+	return order, resultProcessed, nil
+}
+
+// save сохраняет заказ в БД и кэш с ограничением по времени
+func (h *consumerHandler) save(ctx context.Context, message *sarama.ConsumerMessage, order *model.Order) processResult {
+	// Сообщения одного заказа записывает один писатель по очереди,
+	// поэтому проверка и запоминание здесь не гонятся между собой
+	if h.recent != nil && h.recent.Seen(order.OrderUID, message.Value) {
+		logger.Info("Duplicate order delivery, skipping", append(messageFields(message),
+			zap.String("order_uid", order.OrderUID))...)
+		return resultDuplicateSkipped
 	}
 
-	if order.Delivery.Name == "" || order.Delivery.Phone == "" || order.Delivery.Zip == "" ||
-		order.Delivery.City == "" || order.Delivery.Address == "" || order.Delivery.Region == "" ||
-		order.Delivery.Email == "" {
-		return fmt.Errorf("missing fields in delivery")
+	start := time.Now()
+	ctx, cancel := h.processingContext(ctx)
+	err := h.store.Save(ctx, order)
+	cancel()
+	observeStage(stageSave, start)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			logger.Error("Order processing timed out",
+				zap.String("order_uid", order.OrderUID), zap.Duration("timeout", h.processingTimeout))
+		}
+		if h.deadLetters != nil && db.IsPermanent(err) {
+			// Повтор с теми же данными не поможет: заказ уходит в DLQ, смещение отмечается
+			logger.Error("Database rejected order", append(messageFields(message),
+				zap.String("order_uid", order.OrderUID), zap.Error(err))...)
+			h.deadLetters.Send(message, resultDBPermanent, err)
+			return resultDBPermanent
+		}
+		logger.Error("Failed to insert order into database",
+			zap.String("order_uid", order.OrderUID), zap.Error(err))
+		return resultDBError
+	}
+	if h.recent != nil {
+		h.recent.Add(order.OrderUID, message.Value)
 	}
 
-	if order.Payment.Transaction == "" || order.Payment.Currency == "" || order.Payment.Provider == "" ||
-		order.Payment.Bank == "" {
-		return fmt.Errorf("missing fields in payment")
+	logger.Info("Order processed successfully", append(messageFields(message),
+		zap.String("order_uid", order.OrderUID), zap.Duration("latency", time.Since(start)))...)
+	return resultProcessed
+}
+
+// mark отмечает сообщение обработанным
+func (h *consumerHandler) mark(session sarama.ConsumerGroupSession, message *sarama.ConsumerMessage) {
+	session.MarkMessage(message, "")
+	if h.onMarked != nil {
+		h.onMarked(message.Topic, message.Partition, message.Offset)
 	}
-	if order.Payment.Amount <= 0 || order.Payment.PaymentDt <= 0 || order.Payment.DeliveryCost < 0 ||
-		order.Payment.GoodsTotal <= 0 || order.Payment.CustomFee < 0 {
-		return fmt.Errorf("invalid numeric values in payment")
+}
+
+// processingContext возвращает контекст обработки одного сообщения
+func (h *consumerHandler) processingContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if h.processingTimeout > 0 {
+		return context.WithTimeout(parent, h.processingTimeout)
 	}
+	return context.WithCancel(parent)
+}
 
-	if len(order.Items) == 0 {
-		return fmt.Errorf("no items")
+// messageFields поля лога, идентифицирующие сообщение
+func messageFields(message *sarama.ConsumerMessage) []zap.Field {
+	fields := []zap.Field{
+		zap.String("topic", message.Topic),
+		zap.Int32("partition", message.Partition),
+		zap.Int64("offset", message.Offset),
 	}
-	for i, item := range order.Items {
-		if item.ChrtID == 0 || item.TrackNumber == "" || item.Price <= 0 || item.Rid == "" ||
-			item.Name == "" || item.Sale < 0 || item.Size == "" || item.TotalPrice <= 0 ||
-			item.NmID == 0 || item.Brand == "" || item.Status <= 0 {
-			return fmt.Errorf("missing/invalid fields in item #%d", i+1)
-		}
+	if traceID := messageTraceID(message); traceID != "" {
+		fields = append(fields, zap.String("trace_id", traceID))
 	}
+	return fields
+}
 
-	return nil
+// headerValue возвращает значение заголовка сообщения или пустую строку
+func headerValue(message *sarama.ConsumerMessage, key string) string {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == key {
+			return string(header.Value)
+		}
+	}
+	return ""
 }
 
-// Close закрывает потребителя
+// Close закрывает потребителя: новые сообщения больше не читаются, а запись
+// уже полученных завершается в пределах ShutdownGrace, чтобы их смещения
+// были отмечены и сообщения не обрабатывались повторно после перезапуска
 func (c *Consumer) Close() error {
 	close(c.stopChan)
+	if c.opts.ShutdownGrace > 0 {
+		grace := time.AfterFunc(c.opts.ShutdownGrace, func() {
+			logger.Warnf("Shutdown grace period %v expired, cancelling in-flight writes", c.opts.ShutdownGrace)
+			c.writeCancel()
+		})
+		defer grace.Stop()
+	} else {
+		c.writeCancel()
+	}
+	defer c.writeCancel()
+
+	err := c.group().Close()
 	c.wg.Wait()
-	return c.consumer.Close()
+	c.stopWriters()
+	if c.deadLetters != nil {
+		if dlqErr := c.deadLetters.Close(); err == nil {
+			err = dlqErr
+		}
+	}
+	if adminErr := c.admin.Close(); err == nil {
+		err = adminErr
+	}
+	return err
 }
@@ -0,0 +1,282 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+func TestManualCommitSkipsFailedInsert(t *testing.T) {
+	database := &fakeDB{save: func(_ context.Context, order *model.Order) error {
+		if order.OrderUID == "failing" {
+			return errors.New("connection reset")
+		}
+		return nil
+	}}
+	h := &consumerHandler{manualCommit: true}
+	startTestHandler(t, h, database, 1)
+
+	session := consume(t, h,
+		orderMessage(t, testutil.Order("first"), 0),
+		orderMessage(t, testutil.Order("failing"), 1),
+		orderMessage(t, testutil.Order("after"), 2),
+	)
+
+	if got := session.Marked(); !slices.Equal(got, []int64{0}) {
+		t.Errorf("marked offsets = %v, want [0]", got)
+	}
+	if got := session.Commits(); got != 1 {
+		t.Errorf("Commit called %d times, want 1 (only after the successful insert)", got)
+	}
+}
+
+func TestManualCommitAfterEachInsert(t *testing.T) {
+	database := &fakeDB{}
+	h := &consumerHandler{manualCommit: true}
+	startTestHandler(t, h, database, 1)
+
+	session := consume(t, h,
+		orderMessage(t, testutil.Order("first"), 0),
+		orderMessage(t, testutil.Order("second"), 1),
+	)
+
+	if got := session.Marked(); !slices.Equal(got, []int64{0, 1}) {
+		t.Errorf("marked offsets = %v, want [0 1]", got)
+	}
+	if got := session.Commits(); got != 2 {
+		t.Errorf("Commit called %d times, want 2", got)
+	}
+}
+
+func TestAutoCommitDoesNotCommitExplicitly(t *testing.T) {
+	database := &fakeDB{}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 1)
+
+	session := consume(t, h, orderMessage(t, testutil.Order("first"), 0))
+
+	if got := session.Marked(); !slices.Equal(got, []int64{0}) {
+		t.Errorf("marked offsets = %v, want [0]", got)
+	}
+	if got := session.Commits(); got != 0 {
+		t.Errorf("Commit called %d times in auto-commit mode, want 0", got)
+	}
+}
+
+func TestMessageKeyMismatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		reject     bool
+		wantResult processResult
+		wantSaved  bool
+	}{
+		{name: "matching key", key: "b563feb7b2b84b6test", wantResult: resultProcessed, wantSaved: true},
+		{name: "mismatch logged", key: "other", wantResult: resultProcessed, wantSaved: true},
+		{name: "missing key logged", key: "", wantResult: resultProcessed, wantSaved: true},
+		{name: "matching key in reject mode", key: "b563feb7b2b84b6test", reject: true, wantResult: resultProcessed, wantSaved: true},
+		{name: "mismatch rejected", key: "other", reject: true, wantResult: resultKeyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			h := &consumerHandler{rejectKeyMismatch: tt.reject}
+			startTestHandler(t, h, database, 1)
+
+			message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 7)
+			message.Key = []byte(tt.key)
+
+			_, result, err := h.prepare(message)
+			if result != tt.wantResult {
+				t.Errorf("prepare result = %q (%v), want %q", result, err, tt.wantResult)
+			}
+
+			session := consume(t, h, message)
+			if saved := len(database.Saved()) == 1; saved != tt.wantSaved {
+				t.Errorf("order saved = %v, want %v", saved, tt.wantSaved)
+			}
+			// Отклоненное сообщение не будет обработано и при повторе, поэтому смещение отмечается
+			if got := session.Marked(); !slices.Equal(got, []int64{7}) {
+				t.Errorf("marked offsets = %v, want [7]", got)
+			}
+		})
+	}
+}
+
+func TestSchemaVersionHeader(t *testing.T) {
+	tests := []struct {
+		header     string
+		wantResult processResult
+	}{
+		{header: "", wantResult: resultProcessed},
+		{header: "1", wantResult: resultProcessed},
+		{header: "2", wantResult: resultUnsupportedSchema},
+		{header: "v1", wantResult: resultUnsupportedSchema},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			h := &consumerHandler{}
+			startTestHandler(t, h, &fakeDB{}, 1)
+
+			message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+			if tt.header != "" {
+				message.Headers = []*sarama.RecordHeader{{Key: []byte(schemaVersionHeader), Value: []byte(tt.header)}}
+			}
+			if _, result, err := h.prepare(message); result != tt.wantResult {
+				t.Errorf("prepare result = %q (%v), want %q", result, err, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestProcessingTimeout(t *testing.T) {
+	database := &fakeDB{save: func(ctx context.Context, _ *model.Order) error {
+		// Зависшая запись завершается только по отмене контекста
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	h := &consumerHandler{processingTimeout: 20 * time.Millisecond, manualCommit: true}
+	startTestHandler(t, h, database, 1)
+
+	start := time.Now()
+	message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+	if result := h.save(context.Background(), message, testutil.Order("b563feb7b2b84b6test")); result != resultDBError {
+		t.Errorf("save result = %q, want %q", result, resultDBError)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("save took %v, want the 20ms timeout to cancel it", elapsed)
+	}
+
+	session := consume(t, h, message)
+	if marked := session.Marked(); len(marked) != 0 {
+		t.Errorf("timed out message was marked: %v", marked)
+	}
+}
+
+func TestProcessingWithoutTimeout(t *testing.T) {
+	var deadline bool
+	database := &fakeDB{save: func(ctx context.Context, _ *model.Order) error {
+		_, deadline = ctx.Deadline()
+		return nil
+	}}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 1)
+
+	result := h.save(context.Background(), orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0),
+		testutil.Order("b563feb7b2b84b6test"))
+	if result != resultProcessed {
+		t.Errorf("save result = %q, want processed", result)
+	}
+	if deadline {
+		t.Error("write context has a deadline although no timeout is configured")
+	}
+}
+
+func TestStartConsumesAllTopics(t *testing.T) {
+	group := newFakeGroup()
+	topics := []string{"orders-tenant-a", "orders-tenant-b", "orders-tenant-c"}
+	c := newTestConsumer(group, &fakeDB{}, topics, Options{})
+	c.Start()
+	defer c.Close()
+
+	select {
+	case got := <-group.consumed:
+		if !slices.Equal(got, topics) {
+			t.Errorf("Consume topics = %v, want %v", got, topics)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Consume was not called")
+	}
+}
+
+func TestValidationErrorsByField(t *testing.T) {
+	tests := []struct {
+		field  string
+		mutate func(*model.Order)
+	}{
+		{field: "track_number", mutate: func(o *model.Order) { o.TrackNumber = "" }},
+		{field: "payment", mutate: func(o *model.Order) { o.Payment.Transaction = "" }},
+		{field: "item", mutate: func(o *model.Order) { o.Items[0].Name = "" }},
+		{field: "items", mutate: func(o *model.Order) { o.Items = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			h := &consumerHandler{}
+			startTestHandler(t, h, &fakeDB{}, 1)
+			order := testutil.Order("b563feb7b2b84b6test")
+			tt.mutate(order)
+
+			before := validationErrors.Get(tt.field)
+			if _, result, _ := h.prepare(orderMessage(t, order, 0)); result != resultInvalid {
+				t.Fatalf("prepare result = %q, want %q", result, resultInvalid)
+			}
+			if got := validationErrors.Get(tt.field) - before; got != 1 {
+				t.Errorf("validation errors for field %q grew by %v, want 1", tt.field, got)
+			}
+		})
+	}
+}
+
+func TestValidateGroupTimings(t *testing.T) {
+	tests := []struct {
+		name      string
+		session   time.Duration
+		heartbeat time.Duration
+		wantErr   bool
+	}{
+		{name: "sarama defaults", session: 10 * time.Second, heartbeat: 3 * time.Second},
+		{name: "just below a third", session: 30 * time.Second, heartbeat: 10*time.Second - time.Nanosecond},
+		{name: "exactly a third", session: 30 * time.Second, heartbeat: 10 * time.Second, wantErr: true},
+		{name: "heartbeat above session", session: 5 * time.Second, heartbeat: 6 * time.Second, wantErr: true},
+		{name: "zero session", heartbeat: time.Second, wantErr: true},
+		{name: "negative heartbeat", session: 10 * time.Second, heartbeat: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGroupTimings(tt.session, tt.heartbeat)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateGroupTimings(%v, %v) = %v, want error %v", tt.session, tt.heartbeat, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigureCommit(t *testing.T) {
+	defaultInterval := sarama.NewConfig().Consumer.Offsets.AutoCommit.Interval
+	tests := []struct {
+		name         string
+		opts         Options
+		wantEnable   bool
+		wantInterval time.Duration
+	}{
+		{name: "sarama default", wantEnable: true, wantInterval: defaultInterval},
+		{name: "configured interval", opts: Options{AutoCommitInterval: 5 * time.Second}, wantEnable: true, wantInterval: 5 * time.Second},
+		{name: "manual commit", opts: Options{ManualCommit: true}, wantInterval: defaultInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := sarama.NewConfig()
+			configureCommit(config, tt.opts)
+
+			autoCommit := config.Consumer.Offsets.AutoCommit
+			if autoCommit.Enable != tt.wantEnable {
+				t.Errorf("AutoCommit.Enable = %v, want %v", autoCommit.Enable, tt.wantEnable)
+			}
+			if autoCommit.Interval != tt.wantInterval {
+				t.Errorf("AutoCommit.Interval = %v, want %v", autoCommit.Interval, tt.wantInterval)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package consumer
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// recentOrders помнит недавно сохраненные заказы, чтобы при повторной доставке
+// (at-least-once) не сохранять тот же заказ снова. Заказ считается дубликатом,
+// только если совпадают order_uid и содержимое сообщения: новая версия заказа
+// (например, при UPSERT_ORDERS) обрабатывается как обычно.
+type recentOrders struct {
+	mu      sync.Mutex
+	window  time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type recentEntry struct {
+	uid     string
+	digest  uint64
+	expires time.Time
+}
+
+func newRecentOrders(window time.Duration, maxSize int) *recentOrders {
+	return &recentOrders{
+		window:  window,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Seen сообщает, сохранялся ли заказ с таким же содержимым в пределах окна
+func (r *recentOrders) Seen(uid string, value []byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[uid]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*recentEntry)
+	if time.Now().After(entry.expires) {
+		r.order.Remove(elem)
+		delete(r.entries, uid)
+		return false
+	}
+	return entry.digest == digest(value)
+}
+
+// Add запоминает сохраненный заказ
+func (r *recentOrders) Add(uid string, value []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := &recentEntry{uid: uid, digest: digest(value), expires: time.Now().Add(r.window)}
+	if elem, ok := r.entries[uid]; ok {
+		elem.Value = entry
+		r.order.MoveToFront(elem)
+		return
+	}
+
+	if r.order.Len() >= r.maxSize {
+		if oldest := r.order.Back(); oldest != nil {
+			r.order.Remove(oldest)
+			delete(r.entries, oldest.Value.(*recentEntry).uid)
+		}
+	}
+
+	r.entries[uid] = r.order.PushFront(entry)
+}
+
+// digest хэш содержимого сообщения
+func digest(value []byte) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write(value)
+	return hash.Sum64()
+}
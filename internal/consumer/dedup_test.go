@@ -0,0 +1,75 @@
+package consumer
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestDuplicateDeliverySavedOnce(t *testing.T) {
+	database := &fakeDB{}
+	h := &consumerHandler{recent: newRecentOrders(time.Minute, 10)}
+	startTestHandler(t, h, database, 1)
+
+	message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+	redelivered := *message
+	redelivered.Offset = 1
+
+	before := messagesProcessed.Get(string(resultDuplicateSkipped))
+	session := consume(t, h, message, &redelivered)
+
+	if got := database.Saved(); len(got) != 1 {
+		t.Errorf("InsertOrder called for %v, want once", got)
+	}
+	if got := messagesProcessed.Get(string(resultDuplicateSkipped)) - before; got != 1 {
+		t.Errorf("duplicate_skipped grew by %v, want 1", got)
+	}
+	if got := session.Marked(); !slices.Equal(got, []int64{0, 1}) {
+		t.Errorf("marked offsets = %v, want [0 1]: the duplicate must still be marked", got)
+	}
+}
+
+func TestChangedOrderIsNotDuplicate(t *testing.T) {
+	database := &fakeDB{}
+	h := &consumerHandler{recent: newRecentOrders(time.Minute, 10)}
+	startTestHandler(t, h, database, 1)
+
+	updated := testutil.Order("b563feb7b2b84b6test")
+	updated.Payment.Amount++
+	consume(t, h,
+		orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0),
+		orderMessage(t, updated, 1),
+	)
+
+	if got := len(database.Saved()); got != 2 {
+		t.Errorf("InsertOrder called %d times, want 2 for a new version of the order", got)
+	}
+}
+
+func TestRecentOrdersWindow(t *testing.T) {
+	r := newRecentOrders(20*time.Millisecond, 10)
+	r.Add("a", []byte("order"))
+	if !r.Seen("a", []byte("order")) {
+		t.Fatal("Seen = false right after Add")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if r.Seen("a", []byte("order")) {
+		t.Error("Seen = true after the window expired")
+	}
+}
+
+func TestRecentOrdersBounded(t *testing.T) {
+	r := newRecentOrders(time.Minute, 2)
+	r.Add("a", []byte("a"))
+	r.Add("b", []byte("b"))
+	r.Add("c", []byte("c"))
+
+	if r.Seen("a", []byte("a")) {
+		t.Error("oldest entry a survived overflow")
+	}
+	if !r.Seen("b", []byte("b")) || !r.Seen("c", []byte("c")) {
+		t.Error("recent entries b and c were evicted")
+	}
+}
@@ -0,0 +1,122 @@
+package consumer
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/metrics"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+// Отклоненные сообщения (неподдерживаемая схема, ошибка разбора, несовпадение
+// ключа, невалидный или слишком большой заказ, а также заказ, который БД
+// отвергла из-за его содержимого) при заданном DLQTopic
+// пересылаются в отдельный топик без изменений: с исходными ключом, телом и
+// заголовками, к которым добавляются причина и координаты исходного сообщения.
+// Смещение исходного сообщения отмечается в любом случае; ошибка отправки в DLQ
+// только логируется и учитывается в метрике.
+
+// Заголовки сообщения DLQ
+const (
+	dlqReasonHeader    = "dlq-reason"
+	dlqErrorHeader     = "dlq-error"
+	dlqTopicHeader     = "dlq-source-topic"
+	dlqPartitionHeader = "dlq-source-partition"
+	dlqOffsetHeader    = "dlq-source-offset"
+)
+
+var dlqMessages = metrics.NewCounterVec(
+	"kafka_consumer_dlq_messages_total",
+	"Number of rejected messages sent to the DLQ topic by reason and send status",
+	"reason", "status",
+)
+
+var dlqRejections = metrics.NewCounterVec(
+	"kafka_consumer_dlq_rejections_total",
+	"Number of messages routed to the DLQ by reason class: unmarshal_error, validation_error, db_permanent_error",
+	"reason",
+)
+
+// Классы причин отправки в DLQ для метрики и оповещения
+const (
+	dlqClassUnmarshal   = "unmarshal_error"
+	dlqClassValidation  = "validation_error"
+	dlqClassDBPermanent = "db_permanent_error"
+)
+
+// dlqClass сводит результат обработки к классу причины отправки в DLQ
+func dlqClass(reason processResult) string {
+	switch reason {
+	case resultUnsupportedSchema, resultDecodeError, resultOversized:
+		return dlqClassUnmarshal
+	case resultDBPermanent:
+		return dlqClassDBPermanent
+	default:
+		return dlqClassValidation
+	}
+}
+
+// deadLetters отправка отклоненных сообщений в DLQ
+type deadLetters struct {
+	producer sarama.SyncProducer
+	topic    string
+	alarm    *dlqAlarm
+}
+
+// newDeadLetters создает продюсер DLQ поверх клиента потребителя.
+// Продюсер, созданный из клиента, не закрывает его при Close.
+func newDeadLetters(client sarama.Client, topic string, alarm *dlqAlarm) (*deadLetters, error) {
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		return nil, fmt.Errorf("create dlq producer error: %w", err)
+	}
+	return &deadLetters{producer: producer, topic: topic, alarm: alarm}, nil
+}
+
+// Send пересылает сообщение в DLQ с причиной отклонения.
+// Безопасен для вызова из нескольких горутин.
+func (d *deadLetters) Send(message *sarama.ConsumerMessage, reason processResult, cause error) {
+	dlqRejections.Inc(dlqClass(reason))
+	if d.alarm != nil {
+		d.alarm.Record(time.Now())
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+5)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(dlqReasonHeader), Value: []byte(reason)},
+		sarama.RecordHeader{Key: []byte(dlqErrorHeader), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(dlqTopicHeader), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(dlqPartitionHeader), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(dlqOffsetHeader), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+	)
+
+	_, _, err := d.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   d.topic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		dlqMessages.Inc(string(reason), "error")
+		logger.Error("Failed to send message to DLQ", append(messageFields(message),
+			zap.String("dlq_topic", d.topic), zap.String("reason", string(reason)), zap.Error(err))...)
+		return
+	}
+	dlqMessages.Inc(string(reason), "sent")
+	logger.Warn("Message sent to DLQ", append(messageFields(message),
+		zap.String("dlq_topic", d.topic), zap.String("reason", string(reason)))...)
+}
+
+// Close закрывает продюсер DLQ
+func (d *deadLetters) Close() error {
+	return d.producer.Close()
+}
@@ -0,0 +1,101 @@
+package consumer
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+func TestOversizedMessageSentToDLQ(t *testing.T) {
+	database := &fakeDB{}
+	dlq, producer := newTestDeadLetters()
+	h := &consumerHandler{maxMessageBytes: 4096, deadLetters: dlq}
+	startTestHandler(t, h, database, 1)
+
+	// За корректным заказом идет мусор: если бы сообщение разбиралось,
+	// итогом была бы ошибка разбора, а не превышение размера
+	message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 5)
+	message.Value = append(message.Value, bytes.Repeat([]byte("x"), h.maxMessageBytes)...)
+
+	if _, result, err := h.prepare(message); result != resultOversized {
+		t.Fatalf("prepare result = %q (%v), want %q", result, err, resultOversized)
+	}
+
+	before := dlqMessages.Get(string(resultOversized), "sent")
+	session := consume(t, h, message)
+
+	if saved := database.Saved(); len(saved) != 0 {
+		t.Errorf("saved orders %v, want none", saved)
+	}
+	if got := session.Marked(); !slices.Equal(got, []int64{5}) {
+		t.Errorf("marked offsets = %v, want [5]", got)
+	}
+	sent := producer.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages to DLQ, want 1", len(sent))
+	}
+	if got := dlqReason(sent[0]); got != string(resultOversized) {
+		t.Errorf("dlq-reason = %q, want %q", got, resultOversized)
+	}
+	if got := dlqMessages.Get(string(resultOversized), "sent") - before; got != 1 {
+		t.Errorf("dlq messages sent grew by %v, want 1", got)
+	}
+}
+
+func TestMessageAtSizeLimitProcessed(t *testing.T) {
+	database := &fakeDB{}
+	dlq, producer := newTestDeadLetters()
+	message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+	h := &consumerHandler{maxMessageBytes: len(message.Value), deadLetters: dlq}
+	startTestHandler(t, h, database, 1)
+
+	consume(t, h, message)
+
+	if saved := database.Saved(); len(saved) != 1 {
+		t.Errorf("saved orders %v, want the order at the size limit", saved)
+	}
+	if sent := producer.Sent(); len(sent) != 0 {
+		t.Errorf("sent %d messages to DLQ, want none", len(sent))
+	}
+}
+
+func TestDeadLetterHeaders(t *testing.T) {
+	dlq, producer := newTestDeadLetters()
+	message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 42)
+	message.Partition = 3
+	message.Headers = []*sarama.RecordHeader{{Key: []byte("source"), Value: []byte("producer")}}
+
+	cause := errors.New("track_number is required")
+	dlq.Send(message, resultInvalid, cause)
+
+	sent := producer.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages to DLQ, want 1", len(sent))
+	}
+	if sent[0].Topic != "orders-dlq" {
+		t.Errorf("DLQ topic = %q, want orders-dlq", sent[0].Topic)
+	}
+	headers := make(map[string]string)
+	for _, header := range sent[0].Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	want := map[string]string{
+		"source":           "producer",
+		dlqReasonHeader:    string(resultInvalid),
+		dlqErrorHeader:     cause.Error(),
+		dlqTopicHeader:     message.Topic,
+		dlqPartitionHeader: "3",
+		dlqOffsetHeader:    strconv.Itoa(42),
+	}
+	for key, value := range want {
+		if headers[key] != value {
+			t.Errorf("header %s = %q, want %q", key, headers[key], value)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"sync"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+)
+
+// DLQAlertFunc вызывается, когда за окно window в DLQ отправлено не меньше
+// count сообщений (count равен порогу). Вызывается синхронно в горутине обработки сообщений,
+// поэтому не должна блокироваться надолго.
+type DLQAlertFunc func(count int, window time.Duration)
+
+// dlqAlarm считает отправки в DLQ в скользящем окне и вызывает оповещение при
+// достижении порога. Оповещение срабатывает один раз при пересечении порога и
+// снова взводится, когда число отправок в окне опускается ниже него.
+type dlqAlarm struct {
+	mu        sync.Mutex
+	threshold int
+	window    time.Duration
+	alert     DLQAlertFunc
+	// events моменты последних отправок в пределах окна в порядке возрастания
+	events []time.Time
+	firing bool
+}
+
+// newDLQAlarm создает счетчик с порогом threshold; nil alert пишет предупреждение в лог
+func newDLQAlarm(threshold int, window time.Duration, alert DLQAlertFunc) *dlqAlarm {
+	if alert == nil {
+		alert = logDLQAlert
+	}
+	return &dlqAlarm{threshold: threshold, window: window, alert: alert}
+}
+
+// Record учитывает отправку в момент now и при пересечении порога вызывает оповещение
+func (a *dlqAlarm) Record(now time.Time) {
+	a.mu.Lock()
+	cutoff := now.Add(-a.window)
+	expired := 0
+	for expired < len(a.events) && !a.events[expired].After(cutoff) {
+		expired++
+	}
+	a.events = append(a.events[expired:], now)
+	// Для сравнения с порогом достаточно последних threshold отправок
+	if len(a.events) > a.threshold {
+		a.events = a.events[len(a.events)-a.threshold:]
+	}
+
+	count := len(a.events)
+	fire := count >= a.threshold && !a.firing
+	a.firing = count >= a.threshold
+	a.mu.Unlock()
+
+	if fire {
+		a.alert(count, a.window)
+	}
+}
+
+// logDLQAlert оповещение по умолчанию
+func logDLQAlert(count int, window time.Duration) {
+	logger.Warnf("DLQ rate alert: at least %d messages sent to DLQ in the last %v", count, window)
+}
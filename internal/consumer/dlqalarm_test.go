@@ -0,0 +1,158 @@
+package consumer
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// alertLog запоминает вызовы оповещения DLQ
+type alertLog struct {
+	counts []int
+}
+
+func (l *alertLog) alert(count int, _ time.Duration) {
+	l.counts = append(l.counts, count)
+}
+
+func TestDLQAlarmFiresPastThreshold(t *testing.T) {
+	var alerts alertLog
+	alarm := newDLQAlarm(3, time.Minute, alerts.alert)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	alarm.Record(start)
+	alarm.Record(start.Add(time.Second))
+	if len(alerts.counts) != 0 {
+		t.Fatalf("alert fired below the threshold: %v", alerts.counts)
+	}
+
+	alarm.Record(start.Add(2 * time.Second))
+	if !slices.Equal(alerts.counts, []int{3}) {
+		t.Fatalf("alerts = %v, want one alert with count 3", alerts.counts)
+	}
+
+	// Пока порог превышен, оповещение не повторяется
+	alarm.Record(start.Add(3 * time.Second))
+	if len(alerts.counts) != 1 {
+		t.Fatalf("alerts = %v, want no repeat while the rate stays high", alerts.counts)
+	}
+
+	// После окна затишья счетчик опускается ниже порога и оповещение снова взводится
+	later := start.Add(5 * time.Minute)
+	alarm.Record(later)
+	alarm.Record(later.Add(time.Second))
+	if len(alerts.counts) != 1 {
+		t.Fatalf("alerts = %v, want no alert after the window expired", alerts.counts)
+	}
+	alarm.Record(later.Add(2 * time.Second))
+	if !slices.Equal(alerts.counts, []int{3, 3}) {
+		t.Errorf("alerts = %v, want a second alert after re-arming", alerts.counts)
+	}
+}
+
+func TestDLQAlarmSpreadOutMessages(t *testing.T) {
+	var alerts alertLog
+	alarm := newDLQAlarm(2, time.Minute, alerts.alert)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range 10 {
+		alarm.Record(start.Add(time.Duration(i) * time.Minute))
+	}
+	if len(alerts.counts) != 0 {
+		t.Errorf("alerts = %v, want none for one message per window", alerts.counts)
+	}
+}
+
+func TestDLQClass(t *testing.T) {
+	tests := []struct {
+		result processResult
+		want   string
+	}{
+		{result: resultDecodeError, want: dlqClassUnmarshal},
+		{result: resultUnsupportedSchema, want: dlqClassUnmarshal},
+		{result: resultOversized, want: dlqClassUnmarshal},
+		{result: resultInvalid, want: dlqClassValidation},
+		{result: resultKeyMismatch, want: dlqClassValidation},
+		{result: resultDBPermanent, want: dlqClassDBPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.result), func(t *testing.T) {
+			if got := dlqClass(tt.result); got != tt.want {
+				t.Errorf("dlqClass(%q) = %q, want %q", tt.result, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDLQRejectionsCountedAndAlerted(t *testing.T) {
+	var alerts alertLog
+	dlq, _ := newTestDeadLetters()
+	dlq.alarm = newDLQAlarm(2, time.Minute, alerts.alert)
+	h := &consumerHandler{deadLetters: dlq}
+	startTestHandler(t, h, &fakeDB{}, 1)
+
+	invalid := testutil.Order("invalid")
+	invalid.TrackNumber = ""
+	garbage := orderMessage(t, testutil.Order("garbage"), 1)
+	garbage.Value = []byte("{not json")
+
+	beforeValidation := dlqRejections.Get(dlqClassValidation)
+	beforeUnmarshal := dlqRejections.Get(dlqClassUnmarshal)
+	consume(t, h, orderMessage(t, invalid, 0), garbage)
+
+	if got := dlqRejections.Get(dlqClassValidation) - beforeValidation; got != 1 {
+		t.Errorf("validation rejections grew by %v, want 1", got)
+	}
+	if got := dlqRejections.Get(dlqClassUnmarshal) - beforeUnmarshal; got != 1 {
+		t.Errorf("unmarshal rejections grew by %v, want 1", got)
+	}
+	if !slices.Equal(alerts.counts, []int{2}) {
+		t.Errorf("alerts = %v, want one alert after the second rejection", alerts.counts)
+	}
+}
+
+func TestDBPermanentErrorSentToDLQ(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantResult processResult
+		wantMarked []int64
+	}{
+		{name: "constraint violation", err: &pgconn.PgError{Code: "23514"}, wantResult: resultDBPermanent, wantMarked: []int64{0}},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, wantResult: resultDBError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{save: func(context.Context, *model.Order) error { return tt.err }}
+			dlq, producer := newTestDeadLetters()
+			h := &consumerHandler{manualCommit: true, deadLetters: dlq}
+			startTestHandler(t, h, database, 1)
+
+			before := messagesProcessed.Get(string(tt.wantResult))
+			session := consume(t, h, orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0))
+
+			if got := messagesProcessed.Get(string(tt.wantResult)) - before; got != 1 {
+				t.Errorf("messages with result %q grew by %v, want 1", tt.wantResult, got)
+			}
+			if got := session.Marked(); !slices.Equal(got, tt.wantMarked) {
+				t.Errorf("marked offsets = %v, want %v", got, tt.wantMarked)
+			}
+			sent := producer.Sent()
+			if tt.wantResult == resultDBPermanent {
+				if len(sent) != 1 || dlqReason(sent[0]) != string(resultDBPermanent) {
+					t.Errorf("DLQ messages = %d, want the rejected order with reason %s", len(sent), resultDBPermanent)
+				}
+			} else if len(sent) != 0 {
+				t.Errorf("sent %d messages to DLQ for a temporary error, want none", len(sent))
+			}
+		})
+	}
+}
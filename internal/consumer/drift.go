@@ -0,0 +1,67 @@
+package consumer
+
+import (
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/metrics"
+	"go-kafka-postgres/internal/model"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+// Метка времени сообщения Kafka (время отправки продюсером или записи брокером)
+// сравнивается с текущим временем — это задержка конвейера от продюсера до
+// потребителя — и с date_created заказа. Большое расхождение с date_created
+// указывает на сбитые часы или повторную отправку старых заказов. Сообщения
+// только наблюдаются и не отклоняются.
+
+// lagBuckets границы корзин задержки сообщений в секундах: от миллисекунд до суток
+var lagBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 3600, 21600, 86400}
+
+var messageLag = metrics.NewHistogramVec(
+	"kafka_consumer_message_lag_seconds",
+	"Time from the Kafka message timestamp to its receipt by the consumer",
+	lagBuckets,
+	"topic",
+)
+
+var timestampDrift = metrics.NewCounterVec(
+	"kafka_consumer_timestamp_drift_total",
+	"Number of messages whose timestamp differs from order date_created by more than the allowed drift",
+	"topic",
+)
+
+// observeTimestamps записывает задержку сообщения и проверяет расхождение его
+// метки времени с date_created заказа. maxDrift 0 отключает проверку расхождения.
+func observeTimestamps(message *sarama.ConsumerMessage, order *model.Order, now time.Time, maxDrift time.Duration) {
+	// Сообщения старого формата (до Kafka 0.10) не содержат метки времени
+	if message.Timestamp.IsZero() {
+		return
+	}
+
+	messageLag.Observe(max(now.Sub(message.Timestamp), 0).Seconds(), message.Topic)
+
+	if maxDrift <= 0 || order.DateCreated.IsZero() {
+		return
+	}
+	drift := timestampDriftOf(message.Timestamp, order.DateCreated.Time)
+	if drift > maxDrift {
+		timestampDrift.Inc(message.Topic)
+		logger.Warn("Message timestamp is inconsistent with order date_created", append(messageFields(message),
+			zap.String("order_uid", order.OrderUID),
+			zap.Time("message_timestamp", message.Timestamp),
+			zap.Time("date_created", order.DateCreated.Time),
+			zap.Duration("drift", drift))...)
+	}
+}
+
+// timestampDriftOf возвращает абсолютное расхождение метки времени сообщения и date_created
+func timestampDriftOf(messageTime, dateCreated time.Time) time.Duration {
+	drift := messageTime.Sub(dateCreated)
+	if drift < 0 {
+		return -drift
+	}
+	return drift
+}
@@ -0,0 +1,87 @@
+package consumer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+func TestTimestampDriftOf(t *testing.T) {
+	created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	tests := []struct {
+		name    string
+		message time.Time
+		want    time.Duration
+	}{
+		{name: "sent after creation", message: created.Add(90 * time.Second), want: 90 * time.Second},
+		{name: "sent before creation", message: created.Add(-time.Hour), want: time.Hour},
+		{name: "same instant in another zone", message: created.In(time.FixedZone("MSK", 3*60*60)), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timestampDriftOf(tt.message, created); got != tt.want {
+				t.Errorf("drift = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObserveTimestamps(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		created   time.Time
+		maxDrift  time.Duration
+		wantLag   float64
+		wantDrift bool
+	}{
+		{name: "consistent", timestamp: now.Add(-2 * time.Second), created: now.Add(-3 * time.Second),
+			maxDrift: time.Minute, wantLag: 2},
+		{name: "drifted", timestamp: now.Add(-time.Second), created: now.Add(-2 * time.Hour),
+			maxDrift: time.Minute, wantLag: 1, wantDrift: true},
+		{name: "drift check disabled", timestamp: now.Add(-time.Second), created: now.Add(-2 * time.Hour), wantLag: 1},
+		{name: "timestamp ahead of the consumer clock", timestamp: now.Add(time.Second), created: now,
+			maxDrift: time.Minute, wantLag: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// У каждого случая своя тема, чтобы их метрики не смешивались
+			topic := "drift-" + tt.name
+			order := testutil.Order("b563feb7b2b84b6test")
+			order.DateCreated = model.NewTimestamp(tt.created)
+			message := &sarama.ConsumerMessage{Topic: topic, Timestamp: tt.timestamp}
+
+			observeTimestamps(message, order, now, tt.maxDrift)
+
+			if got := messageLag.Count(topic); got != 1 {
+				t.Fatalf("lag observations = %d, want 1", got)
+			}
+			if got := messageLag.Sum(topic); math.Abs(got-tt.wantLag) > 1e-9 {
+				t.Errorf("lag = %vs, want %vs", got, tt.wantLag)
+			}
+			if got := timestampDrift.Get(topic) == 1; got != tt.wantDrift {
+				t.Errorf("drift counted = %v, want %v", got, tt.wantDrift)
+			}
+		})
+	}
+}
+
+func TestObserveTimestampsWithoutTimestamp(t *testing.T) {
+	const topic = "drift-legacy"
+	observeTimestamps(&sarama.ConsumerMessage{Topic: topic}, testutil.Order("b563feb7b2b84b6test"), time.Now(), time.Minute)
+
+	if got := messageLag.Count(topic); got != 0 {
+		t.Errorf("lag observations = %d, want none for a message without timestamp", got)
+	}
+	if got := timestampDrift.Get(topic); got != 0 {
+		t.Errorf("drift counter = %v, want 0", got)
+	}
+}
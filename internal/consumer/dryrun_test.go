@@ -0,0 +1,51 @@
+package consumer
+
+import (
+	"slices"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/store"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestDryRunSkipsWrites(t *testing.T) {
+	for _, markOffsets := range []bool{false, true} {
+		database := &fakeDB{}
+		orderCache := cache.New(10)
+		h := &consumerHandler{
+			dryRun:            true,
+			dryRunMarkOffsets: markOffsets,
+			store:             store.New(orderCache, database, store.Options{}),
+		}
+		startTestHandler(t, h, database, 1)
+
+		before := messagesProcessed.Get(string(resultDryRun))
+		invalid := testutil.Order("invalid")
+		invalid.Payment.Bank = ""
+		session := consume(t, h,
+			orderMessage(t, testutil.Order("first"), 0),
+			orderMessage(t, invalid, 1),
+			orderMessage(t, testutil.Order("second"), 2),
+		)
+
+		if saved := database.Saved(); len(saved) != 0 {
+			t.Errorf("markOffsets=%v: InsertOrder called for %v in dry-run mode", markOffsets, saved)
+		}
+		if size := orderCache.Size(); size != 0 {
+			t.Errorf("markOffsets=%v: cache has %d orders in dry-run mode", markOffsets, size)
+		}
+		if got := messagesProcessed.Get(string(resultDryRun)) - before; got != 2 {
+			t.Errorf("markOffsets=%v: dry_run counter increased by %v, want 2", markOffsets, got)
+		}
+
+		// Невалидные сообщения отмечаются всегда, валидные — только с DryRunMarkOffsets
+		want := []int64{1}
+		if markOffsets {
+			want = []int64{0, 1, 2}
+		}
+		if got := session.Marked(); !slices.Equal(got, want) {
+			t.Errorf("markOffsets=%v: marked = %v, want %v", markOffsets, got, want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/store"
+	"go-kafka-postgres/internal/validator"
+
+	"github.com/IBM/sarama"
+)
+
+// fakeDB записывает сохраненные заказы; остальные методы DatabaseInterface не вызываются
+type fakeDB struct {
+	db.DatabaseInterface
+
+	mu    sync.Mutex
+	saved []string
+	// save вызывается при записи заказа; nil — запись успешна
+	save func(ctx context.Context, order *model.Order) error
+}
+
+func (f *fakeDB) InsertOrder(ctx context.Context, order *model.Order) error {
+	f.mu.Lock()
+	f.saved = append(f.saved, order.OrderUID)
+	save := f.save
+	f.mu.Unlock()
+	if save != nil {
+		return save(ctx, order)
+	}
+	return nil
+}
+
+func (f *fakeDB) UpsertOrder(ctx context.Context, order *model.Order) error {
+	return f.InsertOrder(ctx, order)
+}
+
+// Saved возвращает UID заказов в порядке записи
+func (f *fakeDB) Saved() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.saved...)
+}
+
+// fakeSession запоминает отмеченные смещения и вызовы Commit
+type fakeSession struct {
+	ctx context.Context
+
+	mu      sync.Mutex
+	marked  []int64
+	commits int
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{ctx: context.Background()}
+}
+
+func (s *fakeSession) Claims() map[string][]int32                        { return nil }
+func (s *fakeSession) MemberID() string                                  { return "member" }
+func (s *fakeSession) GenerationID() int32                               { return 1 }
+func (s *fakeSession) MarkOffset(string, int32, int64, string)           {}
+func (s *fakeSession) ResetOffset(string, int32, int64, string)          {}
+func (s *fakeSession) Context() context.Context                          { return s.ctx }
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) { s.mark(msg.Offset) }
+
+func (s *fakeSession) mark(offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, offset)
+}
+
+func (s *fakeSession) Commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commits++
+}
+
+// Marked возвращает отмеченные смещения в порядке отметки
+func (s *fakeSession) Marked() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+// Commits возвращает число вызовов Commit
+func (s *fakeSession) Commits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commits
+}
+
+// fakeClaim партиция с заранее заданными сообщениями
+type fakeClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+// newFakeClaim возвращает партицию, канал которой закрыт после messages
+func newFakeClaim(messages ...*sarama.ConsumerMessage) *fakeClaim {
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(messages))}
+	for _, message := range messages {
+		claim.messages <- message
+	}
+	close(claim.messages)
+	return claim
+}
+
+func (c *fakeClaim) Topic() string                            { return "orders" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+// testWriteBuffer общая емкость очередей писателей в тестах
+const testWriteBuffer = 16
+
+// startTestHandler дополняет обработчик значениями по умолчанию и запускает
+// писателей так же, как Consumer.Start
+func startTestHandler(t *testing.T, h *consumerHandler, database db.DatabaseInterface, writers int) {
+	t.Helper()
+	if h.store == nil {
+		h.store = store.New(nil, database, store.Options{})
+	}
+	if h.validator == nil {
+		h.validator = validator.New(validator.Options{FutureSkew: validator.DefaultFutureSkew})
+	}
+	if h.writeCtx == nil {
+		h.writeCtx = context.Background()
+	}
+	c := &Consumer{opts: Options{Writers: writers, WriteBuffer: testWriteBuffer}}
+	c.startWriters(h)
+	t.Cleanup(c.stopWriters)
+}
+
+// consume обрабатывает сообщения одной партиции до их исчерпания
+func consume(t *testing.T, h *consumerHandler, messages ...*sarama.ConsumerMessage) *fakeSession {
+	t.Helper()
+	session := newFakeSession()
+	if err := h.ConsumeClaim(session, newFakeClaim(messages...)); err != nil {
+		t.Fatalf("ConsumeClaim: %v", err)
+	}
+	return session
+}
+
+// orderMessage кодирует заказ в сообщение с ключом order_uid
+func orderMessage(t *testing.T, order *model.Order, offset int64) *sarama.ConsumerMessage {
+	t.Helper()
+	value, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("marshal order: %v", err)
+	}
+	return &sarama.ConsumerMessage{
+		Topic:     "orders",
+		Partition: 0,
+		Offset:    offset,
+		Key:       []byte(order.OrderUID),
+		Value:     value,
+		Timestamp: time.Now(),
+	}
+}
+
+// fakeGroup передает в consumed темы каждого вызова Consume и, если задана
+// партиция claim, обрабатывает ее в сессии session. Сессия длится до Close,
+// который, как и sarama, закрывает канал сообщений партиции.
+type fakeGroup struct {
+	sarama.ConsumerGroup
+
+	consumed chan []string
+	session  *fakeSession
+	claim    *fakeClaim
+
+	closeOnce sync.Once
+	release   chan struct{}
+}
+
+func newFakeGroup() *fakeGroup {
+	return &fakeGroup{consumed: make(chan []string, 1), release: make(chan struct{})}
+}
+
+func (g *fakeGroup) Consume(_ context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	select {
+	case g.consumed <- append([]string(nil), topics...):
+	default:
+	}
+	if g.claim != nil {
+		if err := handler.ConsumeClaim(g.session, g.claim); err != nil {
+			return err
+		}
+	}
+	<-g.release
+	return nil
+}
+
+func (g *fakeGroup) Close() error {
+	g.closeOnce.Do(func() {
+		if g.claim != nil {
+			close(g.claim.messages)
+		}
+		close(g.release)
+	})
+	return nil
+}
+
+// fakeAdmin ClusterAdmin, который только закрывается
+type fakeAdmin struct {
+	sarama.ClusterAdmin
+}
+
+func (fakeAdmin) Close() error { return nil }
+
+// newTestConsumer создает потребителя поверх группы group без подключения к Kafka
+func newTestConsumer(group sarama.ConsumerGroup, database db.DatabaseInterface, topics []string, opts Options) *Consumer {
+	writeCtx, writeCancel := context.WithCancel(context.Background())
+	return &Consumer{
+		admin:       fakeAdmin{},
+		consumer:    group,
+		opts:        opts,
+		db:          database,
+		topics:      topics,
+		stopChan:    make(chan struct{}),
+		processed:   make(map[string]map[int32]int64),
+		writeCtx:    writeCtx,
+		writeCancel: writeCancel,
+	}
+}
+
+// fakeProducer запоминает сообщения, отправленные в DLQ
+type fakeProducer struct {
+	sarama.SyncProducer
+
+	mu   sync.Mutex
+	sent []*sarama.ProducerMessage
+}
+
+func (p *fakeProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, msg)
+	return 0, int64(len(p.sent) - 1), nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+// Sent возвращает отправленные сообщения в порядке отправки
+func (p *fakeProducer) Sent() []*sarama.ProducerMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*sarama.ProducerMessage(nil), p.sent...)
+}
+
+// newTestDeadLetters возвращает DLQ поверх fakeProducer
+func newTestDeadLetters() (*deadLetters, *fakeProducer) {
+	producer := &fakeProducer{}
+	return &deadLetters{producer: producer, topic: "orders-dlq"}, producer
+}
+
+// dlqReason возвращает причину из заголовков сообщения DLQ
+func dlqReason(msg *sarama.ProducerMessage) string {
+	for _, header := range msg.Headers {
+		if string(header.Key) == dlqReasonHeader {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package consumer
+
+import (
+	"strconv"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/metrics"
+
+	"github.com/IBM/sarama"
+)
+
+var consumerLag = metrics.NewGaugeVec(
+	"kafka_consumer_lag",
+	"Difference between the partition high water mark and the committed offset",
+	"topic", "partition",
+)
+
+// monitorLag периодически вычисляет отставание до остановки потребителя
+func (c *Consumer) monitorLag() {
+	ticker := time.NewTicker(c.opts.LagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-ticker.C:
+			for _, topic := range c.topics {
+				lag, err := c.Lag(topic)
+				if err != nil {
+					logger.Errorf("Failed to compute consumer lag for topic %s: %v", topic, err)
+					continue
+				}
+
+				var total int64
+				for partition, value := range lag {
+					consumerLag.Set(float64(value), topic, strconv.Itoa(int(partition)))
+					total += value
+				}
+				logger.Infof("Consumer lag for topic %s: total %d, per partition %v", topic, total, lag)
+			}
+		}
+	}
+}
+
+// Lag возвращает отставание потребителя по каждой партиции топика
+func (c *Consumer) Lag(topic string) (map[int32]int64, error) {
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	committed, err := c.admin.ListConsumerGroupOffsets(c.groupID, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, err
+	}
+
+	lag := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		hwm, err := c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, err
+		}
+
+		offset := int64(-1)
+		if block := committed.GetBlock(topic, partition); block != nil {
+			offset = block.Offset
+		}
+
+		// Нет зафиксированного смещения — отставание считается от смещения, с
+		// которого группа начнет чтение: для newest сообщения, уже лежащие в
+		// партиции, группа не прочитает никогда
+		if offset < 0 {
+			start, err := c.startOffset(topic, partition, hwm)
+			if err != nil {
+				return nil, err
+			}
+			offset = start
+		}
+
+		lag[partition] = max(hwm-offset, 0)
+	}
+
+	return lag, nil
+}
+
+// startOffset возвращает смещение, с которого новая группа начнет читать
+// партицию согласно Options.InitialOffset; hwm верхняя граница партиции
+func (c *Consumer) startOffset(topic string, partition int32, hwm int64) (int64, error) {
+	initial, err := parseInitialOffset(c.opts.InitialOffset)
+	if err != nil {
+		return 0, err
+	}
+	if initial == sarama.OffsetNewest {
+		return hwm, nil
+	}
+	return c.client.GetOffset(topic, partition, sarama.OffsetOldest)
+}
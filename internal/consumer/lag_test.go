@@ -0,0 +1,113 @@
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// lagClient клиент с партициями 0..len(highWater)-1 и заданными верхними
+// границами; самое старое смещение у всех партиций oldest
+type lagClient struct {
+	sarama.Client
+	oldest    int64
+	highWater []int64
+}
+
+func (c lagClient) Partitions(string) ([]int32, error) {
+	partitions := make([]int32, len(c.highWater))
+	for i := range partitions {
+		partitions[i] = int32(i)
+	}
+	return partitions, nil
+}
+
+func (c lagClient) GetOffset(_ string, partition int32, at int64) (int64, error) {
+	if at == sarama.OffsetOldest {
+		return c.oldest, nil
+	}
+	return c.highWater[partition], nil
+}
+
+// lagAdmin возвращает зафиксированные смещения партиций; для партиций, которых
+// нет в committed, как и Kafka, отдает смещение -1
+type lagAdmin struct {
+	fakeAdmin
+	committed map[int32]int64
+}
+
+func (a lagAdmin) ListConsumerGroupOffsets(_ string, partitions map[string][]int32) (*sarama.OffsetFetchResponse, error) {
+	response := &sarama.OffsetFetchResponse{}
+	for topic, ids := range partitions {
+		for _, partition := range ids {
+			offset, ok := a.committed[partition]
+			if !ok {
+				offset = -1
+			}
+			response.AddBlock(topic, partition, &sarama.OffsetFetchResponseBlock{Offset: offset})
+		}
+	}
+	return response, nil
+}
+
+// lagConsumer потребитель топика topic поверх lagClient и lagAdmin: партиция 0
+// с зафиксированным смещением 40 из 100, партиция 1 без зафиксированного смещения
+func lagConsumer(topic, initialOffset string) *Consumer {
+	c := newTestConsumer(newFakeGroup(), &fakeDB{}, []string{topic},
+		Options{InitialOffset: initialOffset, LagInterval: time.Millisecond})
+	c.client = lagClient{oldest: 10, highWater: []int64{100, 60}}
+	c.admin = lagAdmin{committed: map[int32]int64{0: 40}}
+	return c
+}
+
+func TestLag(t *testing.T) {
+	tests := []struct {
+		initialOffset string
+		want          map[int32]int64
+	}{
+		// Группа с newest начнет партицию 1 с ее конца, поэтому отставания нет
+		{initialOffset: "", want: map[int32]int64{0: 60, 1: 0}},
+		{initialOffset: "newest", want: map[int32]int64{0: 60, 1: 0}},
+		// С oldest группа прочитает партицию 1 целиком
+		{initialOffset: "oldest", want: map[int32]int64{0: 60, 1: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.initialOffset, func(t *testing.T) {
+			lag, err := lagConsumer("orders", tt.initialOffset).Lag("orders")
+			if err != nil {
+				t.Fatalf("Lag: %v", err)
+			}
+			if len(lag) != len(tt.want) || lag[0] != tt.want[0] || lag[1] != tt.want[1] {
+				t.Errorf("Lag = %v, want %v", lag, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorLagStopsOnClose(t *testing.T) {
+	const topic = "orders-monitor-lag"
+	c := lagConsumer(topic, "oldest")
+
+	done := make(chan struct{})
+	go func() {
+		c.monitorLag()
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for consumerLag.Get(topic, "0") != 60 || consumerLag.Get(topic, "1") != 50 {
+		if time.Now().After(deadline) {
+			t.Fatalf("kafka_consumer_lag = %v/%v, want 60/50", consumerLag.Get(topic, "0"), consumerLag.Get(topic, "1"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(c.stopChan)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("monitorLag did not return after stopChan was closed")
+	}
+}
@@ -0,0 +1,55 @@
+package consumer
+
+import (
+	"time"
+
+	"go-kafka-postgres/internal/metrics"
+)
+
+// processResult итог обработки одного сообщения
+type processResult string
+
+const (
+	resultProcessed         processResult = "processed"
+	resultDryRun            processResult = "dry_run"
+	resultUnsupportedSchema processResult = "unsupported_schema"
+	resultDecodeError       processResult = "decode_error"
+	resultKeyMismatch       processResult = "key_mismatch"
+	resultInvalid           processResult = "invalid"
+	resultDBError           processResult = "db_error"
+	resultDBPermanent       processResult = "db_permanent_error"
+	resultDuplicateSkipped  processResult = "duplicate_skipped"
+	resultOversized         processResult = "oversized"
+)
+
+var messagesProcessed = metrics.NewCounterVec(
+	"kafka_consumer_messages_total",
+	"Number of consumed Kafka messages by processing result",
+	"result",
+)
+
+var validationErrors = metrics.NewCounterVec(
+	"kafka_consumer_validation_errors_total",
+	"Number of invalid orders by the field that failed validation",
+	"field",
+)
+
+// Этапы обработки сообщения для гистограммы длительностей
+const (
+	stageDecode   = "decode"
+	stageValidate = "validate"
+	stageSave     = "save"
+	stageTotal    = "total"
+)
+
+var stageDuration = metrics.NewHistogramVec(
+	"kafka_consumer_stage_duration_seconds",
+	"Duration of message processing stages: decode, validate, save (DB write and cache update) and total from receipt to offset marking",
+	nil,
+	"stage",
+)
+
+// observeStage записывает длительность этапа, начавшегося в start
+func observeStage(stage string, start time.Time) {
+	stageDuration.Observe(time.Since(start).Seconds(), stage)
+}
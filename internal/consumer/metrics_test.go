@@ -0,0 +1,56 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestStageDurationsRecorded(t *testing.T) {
+	database := &fakeDB{save: func(context.Context, *model.Order) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	}}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 1)
+
+	stages := []string{stageDecode, stageValidate, stageSave, stageTotal}
+	counts := make(map[string]uint64)
+	sums := make(map[string]float64)
+	for _, stage := range stages {
+		counts[stage], sums[stage] = stageDuration.Count(stage), stageDuration.Sum(stage)
+	}
+
+	consume(t, h, orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0))
+
+	for _, stage := range stages {
+		if got := stageDuration.Count(stage) - counts[stage]; got != 1 {
+			t.Errorf("stage %s observed %d times, want 1", stage, got)
+		}
+		if got := stageDuration.Sum(stage) - sums[stage]; got <= 0 {
+			t.Errorf("stage %s duration = %v, want > 0", stage, got)
+		}
+	}
+	// Запись длится не меньше миллисекунды, и общее время включает ее
+	if save := stageDuration.Sum(stageSave) - sums[stageSave]; save < time.Millisecond.Seconds() {
+		t.Errorf("save duration = %vs, want at least 1ms", save)
+	}
+	if total := stageDuration.Sum(stageTotal) - sums[stageTotal]; total < time.Millisecond.Seconds() {
+		t.Errorf("total duration = %vs, want at least the save duration", total)
+	}
+}
+
+func TestStageDurationsSkipFailedSave(t *testing.T) {
+	database := &fakeDB{save: func(context.Context, *model.Order) error { return context.DeadlineExceeded }}
+	h := &consumerHandler{manualCommit: true}
+	startTestHandler(t, h, database, 1)
+
+	before := stageDuration.Count(stageTotal)
+	consume(t, h, orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0))
+	if got := stageDuration.Count(stageTotal) - before; got != 0 {
+		t.Errorf("total observed %d times for a failed write, want 0", got)
+	}
+}
@@ -0,0 +1,47 @@
+package consumer
+
+import (
+	"reflect"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestProcessedOffsets(t *testing.T) {
+	c := &Consumer{processed: make(map[string]map[int32]int64)}
+	h := &consumerHandler{onMarked: c.recordOffset}
+	startTestHandler(t, h, &fakeDB{}, 2)
+
+	invalid := testutil.Order("invalid")
+	invalid.Items = nil
+	consume(t, h,
+		orderMessage(t, testutil.Order("first"), 10),
+		orderMessage(t, testutil.Order("second"), 11),
+		orderMessage(t, invalid, 12),
+	)
+
+	want := map[string]map[int32]int64{"orders": {0: 12}}
+	if got := c.ProcessedOffsets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProcessedOffsets = %v, want %v", got, want)
+	}
+	if c.lastProgress.Load() == 0 {
+		t.Error("last progress time was not recorded")
+	}
+
+	// Смещения других партиций и топиков хранятся отдельно, меньшее смещение не откатывает запись
+	c.recordOffset("orders", 1, 5)
+	c.recordOffset("orders-eu", 0, 3)
+	c.recordOffset("orders", 0, 4)
+
+	offsets := c.ProcessedOffsets()
+	want = map[string]map[int32]int64{"orders": {0: 12, 1: 5}, "orders-eu": {0: 3}}
+	if !reflect.DeepEqual(offsets, want) {
+		t.Errorf("ProcessedOffsets = %v, want %v", offsets, want)
+	}
+
+	// Возвращается копия
+	offsets["orders"][0] = 0
+	if got := c.ProcessedOffsets()["orders"][0]; got != 12 {
+		t.Errorf("ProcessedOffsets result aliases internal state: offset = %d", got)
+	}
+}
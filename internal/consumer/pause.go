@@ -0,0 +1,88 @@
+package consumer
+
+import (
+	"sync"
+
+	"go-kafka-postgres/internal/logger"
+)
+
+// pauseGate состояние паузы потребителя. PauseAll в sarama останавливает
+// только запросы к брокеру, а сообщения, уже лежащие в буфере партиции
+// (до ChannelBufferSize), по-прежнему доступны в claim.Messages(), поэтому
+// ConsumeClaim на время паузы перестает их читать и ждет канала wait.
+type pauseGate struct {
+	mu sync.Mutex
+	// resumed закрывается при Resume; nil — потребление не приостановлено
+	resumed chan struct{}
+}
+
+// pause приостанавливает потребление; false — оно уже приостановлено
+func (g *pauseGate) pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		return false
+	}
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// resume возобновляет потребление; false — оно не было приостановлено
+func (g *pauseGate) resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		return false
+	}
+	close(g.resumed)
+	g.resumed = nil
+	return true
+}
+
+// wait возвращает канал, который закроется при Resume, или nil, если
+// потребление не приостановлено
+func (g *pauseGate) wait() <-chan struct{} {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		return nil
+	}
+	return g.resumed
+}
+
+// Pause приостанавливает чтение всех партиций группы без выхода из нее.
+// Сообщения, уже отправленные на запись, дописываются; новые, в том числе
+// уже полученные от брокера, не обрабатываются, поэтому смещения не
+// продвигаются и записи в БД не выполняются до вызова Resume.
+func (c *Consumer) Pause() {
+	if !c.paused.pause() {
+		return
+	}
+	c.group().PauseAll()
+	logger.Infof("Consumer group %s paused", c.groupID)
+}
+
+// Resume возобновляет чтение партиций после Pause
+func (c *Consumer) Resume() {
+	if !c.paused.resume() {
+		return
+	}
+	c.group().ResumeAll()
+	logger.Infof("Consumer group %s resumed", c.groupID)
+}
+
+// Paused сообщает, приостановлено ли потребление
+func (c *Consumer) Paused() bool {
+	return c.paused.wait() != nil
+}
+
+// pauseClaim приостанавливает партицию, полученную после ребалансировки,
+// если потребление приостановлено: PauseAll действует только на текущие партиции
+func (c *Consumer) pauseClaim(topic string, partition int32) {
+	if c.Paused() {
+		c.group().Pause(map[string][]int32{topic: {partition}})
+	}
+}
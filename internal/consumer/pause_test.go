@@ -0,0 +1,169 @@
+package consumer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+// pausingGroup запоминает вызовы PauseAll, ResumeAll и Pause
+type pausingGroup struct {
+	*fakeGroup
+
+	mu           sync.Mutex
+	pauseAll     int
+	resumeAll    int
+	pausedClaims map[string][]int32
+}
+
+func (g *pausingGroup) PauseAll() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pauseAll++
+}
+
+func (g *pausingGroup) ResumeAll() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.resumeAll++
+}
+
+func (g *pausingGroup) Pause(partitions map[string][]int32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pausedClaims = partitions
+}
+
+// bufferedClaim партиция, в буфере которой, как у sarama после PauseAll,
+// уже лежат полученные от брокера сообщения
+func bufferedClaim(t *testing.T, uids ...string) *fakeClaim {
+	t.Helper()
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(uids))}
+	for i, uid := range uids {
+		claim.messages <- orderMessage(t, testutil.Order(uid), int64(i))
+	}
+	return claim
+}
+
+// assertNothingProcessed проверяет, что за время паузы заказы не записаны и смещения не отмечены
+func assertNothingProcessed(t *testing.T, database *fakeDB, session *fakeSession) {
+	t.Helper()
+	time.Sleep(30 * time.Millisecond)
+	if saved := database.Saved(); len(saved) != 0 {
+		t.Errorf("orders saved while paused: %v", saved)
+	}
+	if marked := session.Marked(); len(marked) != 0 {
+		t.Errorf("offsets marked while paused: %v", marked)
+	}
+}
+
+func TestPausedHandlerSkipsBufferedMessages(t *testing.T) {
+	gate := &pauseGate{}
+	database := &fakeDB{}
+	h := &consumerHandler{paused: gate}
+	startTestHandler(t, h, database, 1)
+
+	claim := bufferedClaim(t, "first", "second")
+	gate.pause()
+
+	session := newFakeSession()
+	done := make(chan error, 1)
+	go func() { done <- h.ConsumeClaim(session, claim) }()
+
+	assertNothingProcessed(t, database, session)
+
+	gate.resume()
+	waitFor(t, "both offsets to be marked after resume", func() bool { return len(session.Marked()) == 2 })
+	if saved := database.Saved(); !slices.Equal(saved, []string{"first", "second"}) {
+		t.Errorf("saved after resume = %v, want [first second]", saved)
+	}
+
+	close(claim.messages)
+	if err := <-done; err != nil {
+		t.Errorf("ConsumeClaim: %v", err)
+	}
+}
+
+func TestPausedHandlerSessionEnds(t *testing.T) {
+	gate := &pauseGate{}
+	database := &fakeDB{}
+	h := &consumerHandler{paused: gate}
+	startTestHandler(t, h, database, 1)
+	gate.pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &fakeSession{ctx: ctx}
+	done := make(chan error, 1)
+	go func() { done <- h.ConsumeClaim(session, bufferedClaim(t, "first")) }()
+
+	// Ребалансировка во время паузы: обработчик выходит, не читая буфер
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ConsumeClaim: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ConsumeClaim did not return after the session ended while paused")
+	}
+	if saved, marked := database.Saved(), session.Marked(); len(saved) != 0 || len(marked) != 0 {
+		t.Errorf("saved %v and marked %v after the paused session ended, want nothing", saved, marked)
+	}
+}
+
+func TestPausedConsumerProcessesNothing(t *testing.T) {
+	group := &pausingGroup{fakeGroup: newFakeGroup()}
+	group.session = newFakeSession()
+	group.claim = bufferedClaim(t, "b563feb7b2b84b6test")
+	database := &fakeDB{}
+	c := newTestConsumer(group, database, []string{"orders"}, Options{})
+
+	// Сообщение уже в буфере партиции до паузы и до начала сессии
+	c.Pause()
+	c.Pause()
+	if !c.Paused() {
+		t.Fatal("Paused() = false after Pause")
+	}
+	c.Start()
+	defer c.Close()
+
+	assertNothingProcessed(t, database, group.session)
+
+	c.Resume()
+	c.Resume()
+	if c.Paused() {
+		t.Error("Paused() = true after Resume")
+	}
+	waitFor(t, "the offset to be marked after Resume", func() bool { return len(group.session.Marked()) == 1 })
+	if saved := database.Saved(); !slices.Equal(saved, []string{"b563feb7b2b84b6test"}) {
+		t.Errorf("saved after Resume = %v", saved)
+	}
+
+	group.mu.Lock()
+	defer group.mu.Unlock()
+	if group.pauseAll != 1 || group.resumeAll != 1 {
+		t.Errorf("PauseAll/ResumeAll called %d/%d times, want 1/1: repeated calls are no-ops", group.pauseAll, group.resumeAll)
+	}
+}
+
+func TestPauseClaimAfterRebalance(t *testing.T) {
+	group := &pausingGroup{fakeGroup: newFakeGroup()}
+	c := newTestConsumer(group, &fakeDB{}, []string{"orders"}, Options{})
+
+	c.pauseClaim("orders", 3)
+	if group.pausedClaims != nil {
+		t.Errorf("partition paused while consuming: %v", group.pausedClaims)
+	}
+
+	c.Pause()
+	c.pauseClaim("orders", 3)
+	if got := group.pausedClaims["orders"]; !slices.Equal(got, []int32{3}) {
+		t.Errorf("paused partitions = %v, want the newly assigned orders/3", group.pausedClaims)
+	}
+}
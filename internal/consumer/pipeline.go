@@ -0,0 +1,199 @@
+package consumer
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+
+	"github.com/IBM/sarama"
+)
+
+// Запись в БД вынесена из ConsumeClaim в пул горутин-писателей.
+// ConsumeClaim разбирает и валидирует сообщения и отправляет заказы в
+// ограниченную очередь; когда очередь заполнена, чтение партиции
+// приостанавливается (back-pressure). Смещения отмечаются строго в порядке
+// сообщений партиции и только после того, как писатель подтвердил запись,
+// поэтому семантика at-least-once сохраняется: при сбое повторно будут
+// получены все сообщения после последнего отмеченного.
+//
+// У каждого писателя своя очередь, и заказ направляется писателю по хэшу
+// order_uid: сообщения одного заказа (а продюсер отправляет их с ключом
+// order_uid в одну партицию) записываются одним писателем в порядке получения,
+// а разные заказы по-прежнему пишутся параллельно.
+
+// writeJob задание на запись заказа в БД
+type writeJob struct {
+	ctx     context.Context
+	message *sarama.ConsumerMessage
+	order   *model.Order
+	done    chan processResult
+}
+
+// pendingMessage сообщение партиции, ожидающее отметки смещения
+type pendingMessage struct {
+	message  *sarama.ConsumerMessage
+	received time.Time
+	result   processResult
+	done     chan processResult
+	span     Span
+}
+
+// startWriters запускает горутины записи заказов, каждую со своей очередью
+func (c *Consumer) startWriters(h *consumerHandler) {
+	writers := max(c.opts.Writers, 1)
+	buffer := max(c.opts.WriteBuffer, 0)
+	// Общая емкость очередей примерно равна WriteBuffer
+	perWriter := (buffer + writers - 1) / writers
+
+	c.writeJobs = make([]chan *writeJob, writers)
+	for i := range c.writeJobs {
+		jobs := make(chan *writeJob, perWriter)
+		c.writeJobs[i] = jobs
+
+		c.writersWg.Add(1)
+		go func() {
+			defer c.writersWg.Done()
+			for job := range jobs {
+				job.done <- h.save(job.ctx, job.message, job.order)
+			}
+		}()
+	}
+	h.jobs = c.writeJobs
+
+	logger.Infof("Started %d DB writers with buffer %d each", writers, perWriter)
+}
+
+// stopWriters закрывает очереди и дожидается завершения записи
+func (c *Consumer) stopWriters() {
+	for _, jobs := range c.writeJobs {
+		close(jobs)
+	}
+	c.writersWg.Wait()
+}
+
+// writerFor возвращает очередь писателя, закрепленного за заказом
+func (h *consumerHandler) writerFor(orderUID string) chan *writeJob {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(orderUID))
+	return h.jobs[hash.Sum32()%uint32(len(h.jobs))]
+}
+
+func (h *consumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	if h.onClaim != nil {
+		h.onClaim(claim.Topic(), claim.Partition())
+	}
+
+	var pending []*pendingMessage
+	messages := claim.Messages()
+
+	for messages != nil || len(pending) > 0 {
+		var headDone chan processResult
+		if len(pending) > 0 {
+			headDone = pending[0].done
+		}
+
+		// На время паузы сообщения партиции не читаются, иначе уже полученные
+		// sarama сообщения были бы записаны и отмечены (см. pauseGate)
+		receive := messages
+		resumed := h.paused.wait()
+		var sessionDone <-chan struct{}
+		if resumed != nil && messages != nil {
+			receive = nil
+			sessionDone = session.Context().Done()
+		}
+
+		select {
+		case message, ok := <-receive:
+			if !ok {
+				// Партиция отозвана: дожидаемся записи уже отправленных заказов
+				messages = nil
+				continue
+			}
+			pending = append(pending, h.dispatch(session.Context(), message))
+
+		case result := <-headDone:
+			pending[0].result = result
+			pending[0].done = nil
+
+		case <-resumed:
+			continue
+
+		case <-sessionDone:
+			// Сессия завершилась во время паузы: непрочитанные сообщения будут
+			// получены повторно в новой сессии
+			messages = nil
+			continue
+		}
+
+		var stop bool
+		pending, stop = h.complete(session, pending)
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// dispatch разбирает сообщение и при необходимости ставит заказ в очередь записи
+func (h *consumerHandler) dispatch(ctx context.Context, message *sarama.ConsumerMessage) *pendingMessage {
+	logger.Info("Received message", messageFields(message)...)
+	// Запись идет в контексте потребителя, а не сессии: при остановке
+	// начатая запись завершается, а не прерывается (см. Consumer.Close)
+	writeCtx, span := h.startMessageSpan(h.writeCtx, message)
+	pending := &pendingMessage{message: message, received: time.Now(), span: span}
+
+	order, result, err := h.prepare(message)
+	if order == nil {
+		if err != nil && h.deadLetters != nil {
+			h.deadLetters.Send(message, result, err)
+		}
+		pending.result = result
+		return pending
+	}
+
+	job := &writeJob{ctx: writeCtx, message: message, order: order, done: make(chan processResult, 1)}
+	select {
+	case h.writerFor(order.OrderUID) <- job:
+		pending.done = job.done
+	case <-ctx.Done():
+		pending.result = resultDBError
+	}
+	return pending
+}
+
+// complete отмечает смещения завершенных сообщений с начала очереди.
+// stop означает, что обработку партиции нужно прекратить.
+func (h *consumerHandler) complete(session sarama.ConsumerGroupSession, pending []*pendingMessage) ([]*pendingMessage, bool) {
+	for len(pending) > 0 && pending[0].done == nil {
+		head := pending[0]
+		pending = pending[1:]
+
+		messagesProcessed.Inc(string(head.result))
+		endMessageSpan(head.span, head.result)
+
+		switch {
+		case head.result == resultDBError:
+			if h.manualCommit {
+				// Прекращаем обработку партиции, чтобы смещение не ушло дальше
+				// необработанного сообщения; оно будет получено повторно в новой сессии
+				for _, rest := range pending {
+					rest.span.End(errAbandoned)
+				}
+				return nil, true
+			}
+		case head.result == resultDryRun && !h.dryRunMarkOffsets:
+		default:
+			h.mark(session, head.message)
+			if h.manualCommit && head.result == resultProcessed {
+				session.Commit()
+			}
+			if head.result == resultProcessed {
+				observeStage(stageTotal, head.received)
+			}
+		}
+	}
+	return pending, false
+}
@@ -0,0 +1,169 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+// runClaim запускает ConsumeClaim над партицией, сообщения которой подает тест;
+// возвращаемый канал закрывается по завершении ConsumeClaim
+func runClaim(t *testing.T, h *consumerHandler, session *fakeSession, claim *fakeClaim) <-chan struct{} {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := h.ConsumeClaim(session, claim); err != nil {
+			t.Errorf("ConsumeClaim: %v", err)
+		}
+	}()
+	return done
+}
+
+// waitFor ждет выполнения условия не дольше секунды
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOffsetsMarkedAfterEarlierWrites(t *testing.T) {
+	release := make(chan struct{})
+	database := &fakeDB{save: func(_ context.Context, order *model.Order) error {
+		if order.OrderUID == "slow" {
+			<-release
+		}
+		return nil
+	}}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 2)
+
+	// Быстрый заказ должен попасть к другому писателю, чтобы не ждать медленный
+	fast := "fast"
+	for i := 0; h.writerFor(fast) == h.writerFor("slow"); i++ {
+		fast = fmt.Sprintf("fast%d", i)
+	}
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- orderMessage(t, testutil.Order("slow"), 0)
+	claim.messages <- orderMessage(t, testutil.Order(fast), 1)
+	session := newFakeSession()
+	done := runClaim(t, h, session, claim)
+
+	waitFor(t, "the fast order to be written", func() bool { return slices.Contains(database.Saved(), fast) })
+	if marked := session.Marked(); len(marked) != 0 {
+		t.Errorf("offsets %v marked before the earlier write finished", marked)
+	}
+
+	close(release)
+	close(claim.messages)
+	<-done
+	if got := session.Marked(); !slices.Equal(got, []int64{0, 1}) {
+		t.Errorf("marked offsets = %v, want [0 1]", got)
+	}
+}
+
+func TestWriteQueueBackPressure(t *testing.T) {
+	release := make(chan struct{})
+	database := &fakeDB{save: func(context.Context, *model.Order) error {
+		<-release
+		return nil
+	}}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 1)
+
+	const total = 30
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, total)}
+	for i := range total {
+		claim.messages <- orderMessage(t, testutil.Order(fmt.Sprintf("order%d", i)), int64(i))
+	}
+	session := newFakeSession()
+	done := runClaim(t, h, session, claim)
+
+	// Одно сообщение пишется, testWriteBuffer ждут в очереди и еще одно ждет места в ней
+	wantUnread := total - 1 - testWriteBuffer - 1
+	waitFor(t, "the write queue to fill", func() bool { return len(claim.messages) == wantUnread })
+	time.Sleep(20 * time.Millisecond)
+	if unread := len(claim.messages); unread != wantUnread {
+		t.Errorf("unread messages = %d, want %d: partition read ahead of a full write queue", unread, wantUnread)
+	}
+
+	close(release)
+	close(claim.messages)
+	<-done
+	if got := len(session.Marked()); got != total {
+		t.Errorf("marked %d offsets, want %d", got, total)
+	}
+}
+
+func TestSameOrderWrittenByOneWriterInOrder(t *testing.T) {
+	release := make(chan struct{})
+	var (
+		mu       sync.Mutex
+		versions []int
+	)
+	database := &fakeDB{save: func(_ context.Context, order *model.Order) error {
+		if order.OrderUID != "same" {
+			return nil
+		}
+		mu.Lock()
+		versions = append(versions, order.Payment.Amount)
+		first := len(versions) == 1
+		mu.Unlock()
+		if first {
+			<-release
+		}
+		return nil
+	}}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 4)
+
+	other := "other"
+	for i := 0; h.writerFor(other) == h.writerFor("same"); i++ {
+		other = fmt.Sprintf("other%d", i)
+	}
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 3)}
+	for offset, amount := range []int{1, 2} {
+		order := testutil.Order("same")
+		order.Payment.Amount = amount
+		claim.messages <- orderMessage(t, order, int64(offset))
+	}
+	claim.messages <- orderMessage(t, testutil.Order(other), 2)
+	session := newFakeSession()
+	done := runClaim(t, h, session, claim)
+
+	// Другой заказ пишется параллельно, а вторая версия ждет того же писателя
+	waitFor(t, "the other order to be written", func() bool { return slices.Contains(database.Saved(), other) })
+	mu.Lock()
+	started := len(versions)
+	mu.Unlock()
+	if started != 1 {
+		t.Errorf("%d writes of the same order started concurrently, want 1", started)
+	}
+
+	close(release)
+	close(claim.messages)
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(versions, []int{1, 2}) {
+		t.Errorf("same order versions written = %v, want [1 2]", versions)
+	}
+	if got := session.Marked(); !slices.Equal(got, []int64{0, 1, 2}) {
+		t.Errorf("marked offsets = %v, want [0 1 2]", got)
+	}
+}
@@ -0,0 +1,96 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/validator"
+
+	"github.com/IBM/sarama"
+)
+
+// schemaVersionHeader заголовок сообщения с версией формата заказа
+const schemaVersionHeader = "schema-version"
+
+// DefaultSchemaVersion версия формата для сообщений без явной версии
+const DefaultSchemaVersion = 1
+
+// Schema описывает обработку заказа определенной версии формата
+type Schema struct {
+	// Decode разбирает тело сообщения в заказ
+	Decode func(data []byte) (*model.Order, error)
+	// DecodeStrict разбирает тело сообщения, отклоняя неизвестные поля;
+	// используется при Options.StrictJSON. nil — строгий разбор не поддерживается
+	// и используется Decode
+	DecodeStrict func(data []byte) (*model.Order, error)
+	// Validate проверяет разобранный заказ
+	Validate func(v *validator.Validator, order *model.Order) error
+}
+
+// schemas реестр поддерживаемых версий формата
+var schemas = map[int]Schema{
+	1: {Decode: decodeV1, DecodeStrict: decodeStrictV1, Validate: validateV1},
+}
+
+// RegisterSchema добавляет или заменяет обработку версии формата.
+// Должна вызываться до Start.
+func RegisterSchema(version int, schema Schema) {
+	schemas[version] = schema
+}
+
+func decodeV1(data []byte) (*model.Order, error) {
+	var order model.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func decodeStrictV1(data []byte) (*model.Order, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var order model.Order
+	if err := decoder.Decode(&order); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after order JSON")
+	}
+	return &order, nil
+}
+
+func validateV1(v *validator.Validator, order *model.Order) error {
+	return v.Validate(order)
+}
+
+// resolveSchema определяет версию формата сообщения: по заголовку schema-version,
+// затем по полю version в теле, по умолчанию — DefaultSchemaVersion
+func resolveSchema(message *sarama.ConsumerMessage) (int, Schema, error) {
+	version := DefaultSchemaVersion
+
+	if header := headerValue(message, schemaVersionHeader); header != "" {
+		parsed, err := strconv.Atoi(header)
+		if err != nil {
+			return 0, Schema{}, fmt.Errorf("invalid %s header %q", schemaVersionHeader, header)
+		}
+		version = parsed
+	} else {
+		var envelope struct {
+			Version int `json:"version"`
+		}
+		// Ошибку разбора вернет Decode выбранной схемы
+		if err := json.Unmarshal(message.Value, &envelope); err == nil && envelope.Version != 0 {
+			version = envelope.Version
+		}
+	}
+
+	schema, ok := schemas[version]
+	if !ok {
+		return 0, Schema{}, fmt.Errorf("unsupported schema version %d", version)
+	}
+	return version, schema, nil
+}
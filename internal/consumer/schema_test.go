@@ -0,0 +1,106 @@
+package consumer
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+	"go-kafka-postgres/internal/validator"
+
+	"github.com/IBM/sarama"
+)
+
+func TestV1MessageWithoutVersion(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	message := orderMessage(t, order, 0)
+	if slices.Contains(jsonKeys(t, message.Value), "version") {
+		t.Fatal("fixture must not contain a version field")
+	}
+
+	version, _, err := resolveSchema(message)
+	if err != nil || version != DefaultSchemaVersion {
+		t.Fatalf("resolveSchema = %d, %v; want version %d", version, err, DefaultSchemaVersion)
+	}
+
+	database := &fakeDB{}
+	h := &consumerHandler{}
+	startTestHandler(t, h, database, 1)
+
+	decoded, result, err := h.prepare(message)
+	if result != resultProcessed || err != nil {
+		t.Fatalf("prepare = %q, %v; want processed", result, err)
+	}
+	if !reflect.DeepEqual(decoded, order) {
+		t.Errorf("decoded order = %+v, want %+v", decoded, order)
+	}
+
+	session := consume(t, h, message)
+	if saved := database.Saved(); !slices.Equal(saved, []string{order.OrderUID}) {
+		t.Errorf("saved = %v, want [%s]", saved, order.OrderUID)
+	}
+	if marked := session.Marked(); !slices.Equal(marked, []int64{0}) {
+		t.Errorf("marked = %v, want [0]", marked)
+	}
+}
+
+func TestResolveSchemaFromBody(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	order.Version = 1
+	if version, _, err := resolveSchema(orderMessage(t, order, 0)); err != nil || version != 1 {
+		t.Errorf("resolveSchema with version 1 = %d, %v", version, err)
+	}
+
+	order.Version = 3
+	if _, _, err := resolveSchema(orderMessage(t, order, 0)); err == nil {
+		t.Error("resolveSchema with unknown version 3 succeeded, want error")
+	}
+
+	// Заголовок важнее поля в теле
+	message := orderMessage(t, order, 0)
+	message.Headers = []*sarama.RecordHeader{{Key: []byte(schemaVersionHeader), Value: []byte("1")}}
+	if version, _, err := resolveSchema(message); err != nil || version != 1 {
+		t.Errorf("resolveSchema with header 1 = %d, %v", version, err)
+	}
+}
+
+func TestRegisterSchema(t *testing.T) {
+	var validated bool
+	RegisterSchema(2, Schema{
+		Decode: decodeV1,
+		Validate: func(v *validator.Validator, order *model.Order) error {
+			validated = true
+			return v.Validate(order)
+		},
+	})
+	t.Cleanup(func() { delete(schemas, 2) })
+
+	order := testutil.Order("b563feb7b2b84b6test")
+	order.Version = 2
+	h := &consumerHandler{strictJSON: true}
+	startTestHandler(t, h, &fakeDB{}, 1)
+
+	// Для версии без DecodeStrict используется Decode даже в строгом режиме
+	if _, result, err := h.prepare(orderMessage(t, order, 0)); result != resultProcessed {
+		t.Fatalf("prepare = %q, %v; want processed", result, err)
+	}
+	if !validated {
+		t.Error("version 2 validator was not used")
+	}
+}
+
+// jsonKeys возвращает ключи верхнего уровня JSON-объекта
+func jsonKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,108 @@
+package consumer
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// closeDuringWrite запускает потребителя, дожидается начала записи сообщения
+// и вызывает Close. save получает контекст записи и канал, закрываемый после
+// вызова Close. Возвращает отмеченные смещения, время Close и предупреждения лога.
+func closeDuringWrite(t *testing.T, grace time.Duration, save func(ctx context.Context, closing <-chan struct{}) error) ([]int64, time.Duration, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.WarnLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+
+	started := make(chan struct{})
+	closing := make(chan struct{})
+	database := &fakeDB{save: func(ctx context.Context, _ *model.Order) error {
+		close(started)
+		return save(ctx, closing)
+	}}
+
+	group := newFakeGroup()
+	group.session = newFakeSession()
+	group.claim = &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 1)}
+	group.claim.messages <- orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+
+	c := newTestConsumer(group, database, []string{"orders"}, Options{ShutdownGrace: grace})
+	c.Start()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("write did not start")
+	}
+
+	go func() {
+		// Запись завершается уже после начала Close
+		time.Sleep(10 * time.Millisecond)
+		close(closing)
+	}()
+	start := time.Now()
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return group.session.Marked(), time.Since(start), logs
+}
+
+func TestCloseFinishesInFlightWrite(t *testing.T) {
+	marked, _, logs := closeDuringWrite(t, time.Second, func(ctx context.Context, closing <-chan struct{}) error {
+		<-closing
+		// Запись продолжается после Close и не отменена
+		return ctx.Err()
+	})
+
+	if !slices.Equal(marked, []int64{0}) {
+		t.Errorf("marked offsets = %v, want the in-flight message marked", marked)
+	}
+	if n := logs.FilterMessageSnippet("grace period").Len(); n != 0 {
+		t.Errorf("grace period expired %d times for a write that finished in time", n)
+	}
+}
+
+func TestCloseCancelsWriteAfterGrace(t *testing.T) {
+	marked, elapsed, logs := closeDuringWrite(t, 50*time.Millisecond, func(ctx context.Context, _ <-chan struct{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if len(marked) != 0 {
+		t.Errorf("cancelled write was marked: %v", marked)
+	}
+	if elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Close took %v, want about the 50ms grace period", elapsed)
+	}
+	if n := logs.FilterMessageSnippet("grace period").Len(); n != 1 {
+		t.Errorf("logged %d grace period warnings, want 1", n)
+	}
+}
+
+func TestCloseWithoutGraceCancelsImmediately(t *testing.T) {
+	marked, elapsed, logs := closeDuringWrite(t, 0, func(ctx context.Context, _ <-chan struct{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if len(marked) != 0 {
+		t.Errorf("cancelled write was marked: %v", marked)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Close took %v without a grace period", elapsed)
+	}
+	if n := logs.FilterMessageSnippet("grace period").Len(); n != 0 {
+		t.Errorf("logged %d grace period warnings, want none: no grace timer is armed", n)
+	}
+}
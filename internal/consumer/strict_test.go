@@ -0,0 +1,66 @@
+package consumer
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+func TestStrictJSONRejectsUnknownField(t *testing.T) {
+	tests := []struct {
+		name      string
+		strict    bool
+		wantSaved bool
+	}{
+		{name: "lenient", wantSaved: true},
+		{name: "strict", strict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &fakeDB{}
+			dlq, producer := newTestDeadLetters()
+			h := &consumerHandler{strictJSON: tt.strict, deadLetters: dlq}
+			startTestHandler(t, h, database, 1)
+
+			message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 3)
+			message.Value = bytes.Replace(message.Value, []byte("{"), []byte(`{"loyalty_points":10,`), 1)
+			session := consume(t, h, message)
+
+			if saved := len(database.Saved()) == 1; saved != tt.wantSaved {
+				t.Errorf("order saved = %v, want %v", saved, tt.wantSaved)
+			}
+			sent := producer.Sent()
+			if tt.wantSaved {
+				if len(sent) != 0 {
+					t.Errorf("sent %d messages to DLQ, want none in lenient mode", len(sent))
+				}
+			} else {
+				if len(sent) != 1 || dlqReason(sent[0]) != string(resultDecodeError) {
+					t.Fatalf("DLQ messages = %d, want 1 with reason %s", len(sent), resultDecodeError)
+				}
+				if body, ok := sent[0].Value.(sarama.ByteEncoder); !ok || !bytes.Equal(body, message.Value) {
+					t.Error("DLQ message body differs from the original")
+				}
+			}
+			if got := session.Marked(); !slices.Equal(got, []int64{3}) {
+				t.Errorf("marked offsets = %v, want [3]", got)
+			}
+		})
+	}
+}
+
+func TestStrictJSONRejectsTrailingData(t *testing.T) {
+	h := &consumerHandler{strictJSON: true}
+	startTestHandler(t, h, &fakeDB{}, 1)
+
+	message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+	message.Value = append(message.Value, []byte(`{"order_uid":"other"}`)...)
+	if _, result, err := h.prepare(message); result != resultDecodeError {
+		t.Errorf("prepare result = %q (%v), want %q", result, err, resultDecodeError)
+	}
+}
@@ -0,0 +1,106 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// Трассировка: продюсер может передать контекст трассировки W3C в заголовке
+// traceparent. Потребитель извлекает из него trace ID, добавляет его в поля
+// логов сообщения и в контекст записи заказа (TraceIDFromContext), а обработку
+// каждого сообщения оборачивает в span подключенного Tracer. Зависимости от
+// конкретной библиотеки нет: адаптер OpenTelemetry реализует Tracer, извлекая
+// контекст из заголовков пропагатором (например, propagation.MapCarrier).
+
+// traceParentHeader заголовок сообщения с контекстом трассировки W3C
+const traceParentHeader = "traceparent"
+
+// Span обработка одного сообщения в трассировке
+type Span interface {
+	// End завершает span; err — итог обработки, nil при успехе
+	End(err error)
+}
+
+// Tracer точка подключения трассировки
+type Tracer interface {
+	// Start начинает span обработки сообщения. headers — заголовки сообщения,
+	// из которых извлекается родительский контекст трассировки.
+	Start(ctx context.Context, name string, headers map[string]string) (context.Context, Span)
+}
+
+type traceIDKey struct{}
+
+// TraceIDFromContext возвращает trace ID сообщения, заказ из которого записывается
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// parseTraceParent возвращает trace ID из значения traceparent
+// формата version-traceid-parentid-flags или пустую строку, если формат неверен
+func parseTraceParent(value string) string {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if !isHex(traceID) || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	return traceID
+}
+
+// isHex сообщает, состоит ли строка только из шестнадцатеричных цифр в нижнем регистре
+func isHex(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// messageTraceID возвращает trace ID из заголовка traceparent сообщения
+func messageTraceID(message *sarama.ConsumerMessage) string {
+	return parseTraceParent(headerValue(message, traceParentHeader))
+}
+
+// startMessageSpan возвращает контекст обработки сообщения с trace ID и span трассировки
+func (h *consumerHandler) startMessageSpan(ctx context.Context, message *sarama.ConsumerMessage) (context.Context, Span) {
+	if traceID := messageTraceID(message); traceID != "" {
+		ctx = context.WithValue(ctx, traceIDKey{}, traceID)
+	}
+	if h.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		if header != nil {
+			headers[string(header.Key)] = string(header.Value)
+		}
+	}
+	return h.tracer.Start(ctx, "kafka.consume "+message.Topic, headers)
+}
+
+// endMessageSpan завершает span сообщения с итогом обработки
+func endMessageSpan(span Span, result processResult) {
+	switch result {
+	case resultProcessed, resultDryRun, resultDuplicateSkipped:
+		span.End(nil)
+	default:
+		span.End(errors.New(string(result)))
+	}
+}
+
+// errAbandoned итог span сообщений, обработка которых прекращена после ошибки
+// предыдущего сообщения партиции; они будут получены повторно
+var errAbandoned = errors.New("abandoned after partition error")
+
+// noopSpan span без трассировщика
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
@@ -0,0 +1,167 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/IBM/sarama"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testTraceParent = "00-" + testTraceID + "-00f067aa0ba902b7-01"
+)
+
+// fakeTracer запоминает заголовки начатых span и итоги завершенных
+type fakeTracer struct {
+	mu      sync.Mutex
+	headers []map[string]string
+	ended   []error
+}
+
+func (t *fakeTracer) Start(ctx context.Context, _ string, headers map[string]string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.headers = append(t.headers, headers)
+	return ctx, fakeSpan{tracer: t}
+}
+
+// Ended возвращает итоги завершенных span в порядке завершения
+func (t *fakeTracer) Ended() []error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]error(nil), t.ended...)
+}
+
+type fakeSpan struct {
+	tracer *fakeTracer
+}
+
+func (s fakeSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.ended = append(s.tracer.ended, err)
+}
+
+// tracedMessage возвращает сообщение заказа с заголовком traceparent
+func tracedMessage(t *testing.T, order *model.Order, offset int64, traceParent string) *sarama.ConsumerMessage {
+	t.Helper()
+	message := orderMessage(t, order, offset)
+	message.Headers = []*sarama.RecordHeader{{Key: []byte(traceParentHeader), Value: []byte(traceParent)}}
+	return message
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "valid", value: testTraceParent, want: testTraceID},
+		{name: "upper case", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", want: testTraceID},
+		{name: "surrounding spaces", value: " " + testTraceParent + " ", want: testTraceID},
+		{name: "empty", value: ""},
+		{name: "missing flags", value: "00-" + testTraceID + "-00f067aa0ba902b7"},
+		{name: "short trace id", value: "00-4bf92f3577b34da6-00f067aa0ba902b7-01"},
+		{name: "non-hex trace id", value: "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01"},
+		{name: "all-zero trace id", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "short parent id", value: "00-" + testTraceID + "-00f067aa-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTraceParent(tt.value); got != tt.want {
+				t.Errorf("parseTraceParent(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceIDInWriteContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		want        string
+	}{
+		{name: "traceparent", traceParent: testTraceParent, want: testTraceID},
+		{name: "invalid traceparent", traceParent: "garbage"},
+		{name: "no header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			database := &fakeDB{save: func(ctx context.Context, _ *model.Order) error {
+				got = TraceIDFromContext(ctx)
+				return nil
+			}}
+			h := &consumerHandler{}
+			startTestHandler(t, h, database, 1)
+
+			message := orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)
+			if tt.traceParent != "" {
+				message = tracedMessage(t, testutil.Order("b563feb7b2b84b6test"), 0, tt.traceParent)
+			}
+			consume(t, h, message)
+
+			if len(database.Saved()) != 1 {
+				t.Fatalf("saved %d orders, want 1", len(database.Saved()))
+			}
+			if got != tt.want {
+				t.Errorf("TraceIDFromContext = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageFieldsTraceID(t *testing.T) {
+	message := tracedMessage(t, testutil.Order("b563feb7b2b84b6test"), 0, testTraceParent)
+	var got string
+	for _, field := range messageFields(message) {
+		if field.Key == "trace_id" {
+			got = field.String
+		}
+	}
+	if got != testTraceID {
+		t.Errorf("trace_id log field = %q, want %q", got, testTraceID)
+	}
+
+	for _, field := range messageFields(orderMessage(t, testutil.Order("b563feb7b2b84b6test"), 0)) {
+		if field.Key == "trace_id" {
+			t.Errorf("trace_id log field %q is set for a message without traceparent", field.String)
+		}
+	}
+}
+
+func TestTracerSpans(t *testing.T) {
+	tracer := &fakeTracer{}
+	h := &consumerHandler{tracer: tracer}
+	startTestHandler(t, h, &fakeDB{}, 1)
+
+	invalid := testutil.Order("invalid")
+	invalid.TrackNumber = ""
+	consume(t, h,
+		tracedMessage(t, testutil.Order("b563feb7b2b84b6test"), 0, testTraceParent),
+		tracedMessage(t, invalid, 1, testTraceParent),
+	)
+
+	ended := tracer.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(ended))
+	}
+	if ended[0] != nil {
+		t.Errorf("span of the saved order ended with %v, want nil", ended[0])
+	}
+	if ended[1] == nil || ended[1].Error() != string(resultInvalid) {
+		t.Errorf("span of the invalid order ended with %v, want %q", ended[1], resultInvalid)
+	}
+	for i, headers := range tracer.headers {
+		if headers[traceParentHeader] != testTraceParent {
+			t.Errorf("span %d headers = %v, want traceparent %q", i, headers, testTraceParent)
+		}
+	}
+}
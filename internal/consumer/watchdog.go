@@ -0,0 +1,128 @@
+package consumer
+
+import (
+	"fmt"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/metrics"
+
+	"github.com/IBM/sarama"
+)
+
+// Сторожевой таймер обнаруживает зависание потребителя: если в топиках есть
+// необработанные сообщения (отставание больше нуля), а смещения не отмечались
+// дольше StallTimeout, группа закрывается и создается заново поверх того же
+// клиента. Цикл Consume в Start после закрытия старой группы продолжает работу
+// с новой, а партиции распределяются заново. Приостановленный потребитель
+// (Pause) зависшим не считается. Отставание партиций без зафиксированного
+// смещения считается от начального смещения группы (см. Lag), поэтому новая
+// группа с newest на простаивающем топике не пересоздается.
+
+var consumerRestarts = metrics.NewCounterVec(
+	"kafka_consumer_restarts_total",
+	"Number of consumer group restarts triggered by the stall watchdog",
+	"group",
+)
+
+// group возвращает текущую группу потребителей
+func (c *Consumer) group() sarama.ConsumerGroup {
+	c.groupMu.RLock()
+	defer c.groupMu.RUnlock()
+	return c.consumer
+}
+
+// newGroupFromClient создает группу потребителей поверх клиента; переменная
+// позволяет подменить пересоздание группы
+var newGroupFromClient = sarama.NewConsumerGroupFromClient
+
+// watchdog периодически проверяет, продвигается ли обработка, до остановки потребителя
+func (c *Consumer) watchdog() {
+	interval := max(c.opts.StallTimeout/4, time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.lastProgress.Store(time.Now().UnixNano())
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-ticker.C:
+			c.checkProgress()
+		}
+	}
+}
+
+// checkProgress пересоздает группу, если обработка стоит дольше StallTimeout
+// при ненулевом отставании; возвращает true, если группа пересоздана
+func (c *Consumer) checkProgress() bool {
+	if c.Paused() {
+		c.lastProgress.Store(time.Now().UnixNano())
+		return false
+	}
+
+	idle := time.Since(time.Unix(0, c.lastProgress.Load()))
+	if idle < c.opts.StallTimeout {
+		return false
+	}
+
+	lag, err := c.totalLag()
+	if err != nil {
+		logger.Errorf("Watchdog failed to compute consumer lag: %v", err)
+		return false
+	}
+	if lag == 0 {
+		return false
+	}
+
+	logger.Errorf("Consumer group %s made no progress for %v with lag %d, restarting", c.groupID, idle, lag)
+	if err := c.restartGroup(); err != nil {
+		logger.Errorf("Failed to restart consumer group %s: %v", c.groupID, err)
+		return false
+	}
+	consumerRestarts.Inc(c.groupID)
+	c.lastProgress.Store(time.Now().UnixNano())
+	return true
+}
+
+// totalLag возвращает суммарное отставание по всем топикам
+func (c *Consumer) totalLag() (int64, error) {
+	var total int64
+	for _, topic := range c.topics {
+		lag, err := c.Lag(topic)
+		if err != nil {
+			return 0, err
+		}
+		for _, value := range lag {
+			total += value
+		}
+	}
+	return total, nil
+}
+
+// restartGroup закрывает текущую группу и создает новую поверх того же клиента.
+// Группа, созданная из клиента, не закрывает его при Close.
+func (c *Consumer) restartGroup() error {
+	group, err := newGroupFromClient(c.groupID, c.client)
+	if err != nil {
+		return fmt.Errorf("create consumer group error: %w", err)
+	}
+
+	c.groupMu.Lock()
+	select {
+	case <-c.stopChan:
+		// Потребитель уже останавливается: Close закроет текущую группу
+		c.groupMu.Unlock()
+		return group.Close()
+	default:
+	}
+	old := c.consumer
+	c.consumer = group
+	c.groupMu.Unlock()
+
+	if err := old.Close(); err != nil {
+		logger.Warnf("Error closing stalled consumer group %s: %v", c.groupID, err)
+	}
+	logger.Infof("Consumer group %s restarted", c.groupID)
+	return nil
+}
@@ -0,0 +1,120 @@
+package consumer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// stalledConsumer возвращает потребителя, который час не отмечал смещения,
+// с отставанием highWater-committed; committed -1 — смещение не зафиксировано.
+// Пересоздание группы подменяется: новая группа берется из restarted или
+// создание завершается ошибкой restartErr.
+func stalledConsumer(t *testing.T, highWater, committed int64, restartErr error) (c *Consumer, old, restarted *fakeGroup) {
+	t.Helper()
+	old, restarted = newFakeGroup(), newFakeGroup()
+	c = newTestConsumer(old, &fakeDB{}, []string{"orders"}, Options{StallTimeout: time.Minute})
+	c.groupID = "orders-watchdog"
+	c.client = lagClient{highWater: []int64{highWater}}
+	c.admin = lagAdmin{committed: map[int32]int64{}}
+	if committed >= 0 {
+		c.admin = lagAdmin{committed: map[int32]int64{0: committed}}
+	}
+	c.lastProgress.Store(time.Now().Add(-time.Hour).UnixNano())
+
+	previous := newGroupFromClient
+	newGroupFromClient = func(string, sarama.Client) (sarama.ConsumerGroup, error) {
+		if restartErr != nil {
+			return nil, restartErr
+		}
+		return restarted, nil
+	}
+	t.Cleanup(func() { newGroupFromClient = previous })
+	return c, old, restarted
+}
+
+func TestWatchdogRestartsStalledGroup(t *testing.T) {
+	c, old, restarted := stalledConsumer(t, 10, 5, nil)
+	before := consumerRestarts.Get(c.groupID)
+
+	if !c.checkProgress() {
+		t.Fatal("checkProgress did not restart a stalled group with lag")
+	}
+	if c.group() != restarted {
+		t.Error("consumer still uses the stalled group")
+	}
+	select {
+	case <-old.release:
+	default:
+		t.Error("stalled group was not closed")
+	}
+	if got := consumerRestarts.Get(c.groupID) - before; got != 1 {
+		t.Errorf("restarts metric grew by %v, want 1", got)
+	}
+	if idle := time.Since(time.Unix(0, c.lastProgress.Load())); idle > time.Minute {
+		t.Errorf("last progress is %v old after restart, want it reset", idle)
+	}
+}
+
+func TestWatchdogKeepsHealthyGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		committed  int64
+		progressed bool
+		paused     bool
+		restartErr error
+	}{
+		{name: "no lag", committed: 10},
+		{name: "recent progress", committed: 5, progressed: true},
+		{name: "paused", committed: 5, paused: true},
+		{name: "restart failed", committed: 5, restartErr: errors.New("coordinator not available")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, old, _ := stalledConsumer(t, 10, tt.committed, tt.restartErr)
+			if tt.progressed {
+				c.lastProgress.Store(time.Now().UnixNano())
+			}
+			if tt.paused {
+				c.paused.pause()
+			}
+
+			if c.checkProgress() {
+				t.Error("checkProgress restarted the group")
+			}
+			if c.group() != old {
+				t.Error("consumer group was replaced")
+			}
+		})
+	}
+}
+
+func TestWatchdogNewGroupOnIdleTopic(t *testing.T) {
+	tests := []struct {
+		initialOffset string
+		wantRestart   bool
+	}{
+		// Группа с newest не прочитает уже лежащие в партиции сообщения, так что ждать нечего
+		{initialOffset: ""},
+		{initialOffset: "newest"},
+		// С oldest группа должна прочитать партицию с начала, и стоящая обработка — зависание
+		{initialOffset: "oldest", wantRestart: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.initialOffset, func(t *testing.T) {
+			c, old, _ := stalledConsumer(t, 100, -1, nil)
+			c.opts.InitialOffset = tt.initialOffset
+
+			if restarted := c.checkProgress(); restarted != tt.wantRestart {
+				t.Errorf("checkProgress restarted = %v, want %v", restarted, tt.wantRestart)
+			}
+			if replaced := c.group() != old; replaced != tt.wantRestart {
+				t.Errorf("consumer group replaced = %v, want %v", replaced, tt.wantRestart)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+)
+
+// getAllOrdersAggregated извлекает все заказы одним запросом: товары каждого
+// заказа собираются в Postgres в JSON-массив (json_agg) и разбираются в Go.
+// Используется вместо пары запросов GetAllOrders при Options.AggregateItems,
+// чтобы сравнить оба способа на реальных данных. Колонки items, которых нет
+// в model.Item (id, order_uid), при разборе игнорируются, NULL дает нулевое значение.
+func (db *Database) getAllOrdersAggregated(ctx context.Context) ([]*model.Order, error) {
+	query := `SELECT ` + orderColumns + `,
+			COALESCE((SELECT json_agg(i ORDER BY i.id) FROM items i WHERE i.order_uid = o.order_uid), '[]')` +
+		orderFrom + ` WHERE ` + notDeleted(ctx)
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query orders error: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*model.Order
+	for rows.Next() {
+		var row orderRow
+		var items []byte
+		if err := rows.Scan(append(row.scanTargets(), &items)...); err != nil {
+			logger.Errorf("Error scanning order: %v", err)
+			continue
+		}
+
+		order := row.toOrder()
+		if err := json.Unmarshal(items, &order.Items); err != nil {
+			logger.Errorf("Error decoding items of order %s: %v", order.OrderUID, err)
+			continue
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return orders, nil
+}
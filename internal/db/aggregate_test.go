@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// sortByUID упорядочивает заказы по order_uid: GetAllOrders порядок не гарантирует
+func sortByUID(orders []*model.Order) {
+	slices.SortFunc(orders, func(a, b *model.Order) int { return strings.Compare(a.OrderUID, b.OrderUID) })
+}
+
+func TestGetAllOrdersAggregatedMatchesTwoQueries(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	insertOrdersAt(t, db, "agg", 5, time.Second)
+	exec(t, db, `DELETE FROM items WHERE order_uid = $1`, "agg0001")
+	exec(t, db, `DELETE FROM payment WHERE order_uid = $1`, "agg0002")
+
+	want, err := db.GetAllOrders(ctx)
+	if err != nil {
+		t.Fatalf("GetAllOrders: %v", err)
+	}
+	got, err := db.getAllOrdersAggregated(ctx)
+	if err != nil {
+		t.Fatalf("getAllOrdersAggregated: %v", err)
+	}
+	sortByUID(want)
+	sortByUID(got)
+
+	if len(got) != len(want) {
+		t.Fatalf("aggregated query returned %d orders, want %d", len(got), len(want))
+	}
+	for i := range want {
+		// Заказ без товаров: в одном способе nil, в другом пустой срез
+		if len(want[i].Items) == 0 && len(got[i].Items) == 0 {
+			got[i].Items = want[i].Items
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("aggregated order %s = %+v, want %+v", want[i].OrderUID, got[i], want[i])
+		}
+	}
+}
+
+// benchmarkOrders число заказов в бенчмарках чтения всех заказов
+const benchmarkOrders = 10000
+
+// benchmarkGetAll измеряет загрузку benchmarkOrders заказов функцией load
+func benchmarkGetAll(b *testing.B, opts Options, load func(ctx context.Context, db *Database) ([]*model.Order, error)) {
+	db := newTestDB(b, opts)
+	ctx := context.Background()
+	insertOrdersAt(b, db, "bench", benchmarkOrders, time.Millisecond)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		orders, err := load(ctx, db)
+		if err != nil {
+			b.Fatalf("load: %v", err)
+		}
+		if len(orders) != benchmarkOrders {
+			b.Fatalf("loaded %d orders, want %d", len(orders), benchmarkOrders)
+		}
+	}
+}
+
+// BenchmarkGetAllOrdersPerOrder запрос заказа и его товаров на каждый заказ (N+1)
+func BenchmarkGetAllOrdersPerOrder(b *testing.B) {
+	benchmarkGetAll(b, Options{}, func(ctx context.Context, db *Database) ([]*model.Order, error) {
+		rows, err := db.pool.Query(ctx, `SELECT order_uid FROM orders`)
+		if err != nil {
+			return nil, err
+		}
+		var uids []string
+		for rows.Next() {
+			var uid string
+			if err := rows.Scan(&uid); err != nil {
+				return nil, err
+			}
+			uids = append(uids, uid)
+		}
+		rows.Close()
+
+		orders := make([]*model.Order, 0, len(uids))
+		for _, uid := range uids {
+			order, err := db.GetOrderByUID(ctx, uid)
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+		}
+		return orders, nil
+	})
+}
+
+// BenchmarkGetAllOrdersTwoQueries заказы и все товары двумя запросами
+func BenchmarkGetAllOrdersTwoQueries(b *testing.B) {
+	benchmarkGetAll(b, Options{}, func(ctx context.Context, db *Database) ([]*model.Order, error) {
+		return db.GetAllOrders(ctx)
+	})
+}
+
+// BenchmarkGetAllOrdersAggregated товары в том же запросе через json_agg
+func BenchmarkGetAllOrdersAggregated(b *testing.B) {
+	benchmarkGetAll(b, Options{AggregateItems: true}, func(ctx context.Context, db *Database) ([]*model.Order, error) {
+		return db.GetAllOrders(ctx)
+	})
+}
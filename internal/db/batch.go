@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// BatchOptions настройки пакетной вставки заказов
+type BatchOptions struct {
+	// Size число заказов в одной транзакции; 0 — все заказы в одной транзакции
+	Size int
+	// SkipFailed пропускает заказ, который не удалось вставить, откатываясь
+	// к точке сохранения, вместо отката всей пачки
+	SkipFailed bool
+}
+
+// BatchError перечисляет заказы, пропущенные при вставке с SkipFailed
+type BatchError struct {
+	Failed map[string]error
+}
+
+func (e *BatchError) Error() string {
+	uids := make([]string, 0, len(e.Failed))
+	for uid := range e.Failed {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return fmt.Sprintf("failed to insert %d orders: %s", len(uids), strings.Join(uids, ", "))
+}
+
+// InsertOrders вставляет заказы пачками по opts.Size в одной транзакции на пачку,
+// что значительно сокращает число коммитов при загрузке данных. Без SkipFailed
+// ошибка откатывает всю текущую пачку и прерывает вставку, уже зафиксированные
+// пачки сохраняются. С SkipFailed неудачные заказы пропускаются и возвращаются в *BatchError.
+// Возвращает число заказов, записанных в зафиксированных пачках; заказы, которые
+// уже были в БД, не учитываются.
+func (db *Database) InsertOrders(ctx context.Context, orders []*model.Order, opts BatchOptions) (int, error) {
+	size := opts.Size
+	if size <= 0 {
+		size = len(orders)
+	}
+
+	inserted := 0
+	failed := make(map[string]error)
+	for start := 0; start < len(orders); start += size {
+		end := min(start+size, len(orders))
+		created, err := db.insertBatch(ctx, orders[start:end], opts.SkipFailed, failed)
+		if err != nil {
+			return inserted, fmt.Errorf("insert batch %d-%d error: %w", start, end-1, err)
+		}
+		inserted += created
+	}
+
+	if len(failed) > 0 {
+		return inserted, &BatchError{Failed: failed}
+	}
+	return inserted, nil
+}
+
+// insertBatch вставляет одну пачку заказов в транзакции и возвращает число
+// записанных заказов. При skipFailed каждый заказ пишется внутри точки
+// сохранения, а ошибки собираются в failed.
+func (db *Database) insertBatch(ctx context.Context, orders []*model.Order, skipFailed bool, failed map[string]error) (int, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created := 0
+	for _, order := range orders {
+		if !skipFailed {
+			result, err := writeOrderTx(ctx, tx, order, writeOptions{})
+			if err != nil {
+				return 0, fmt.Errorf("order %s: %w", order.OrderUID, err)
+			}
+			if result.created {
+				created++
+			}
+			continue
+		}
+
+		// Вложенный Begin создает точку сохранения (SAVEPOINT)
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("savepoint error: %w", err)
+		}
+		result, err := writeOrderTx(ctx, savepoint, order, writeOptions{})
+		if err != nil {
+			if rbErr := savepoint.Rollback(ctx); rbErr != nil {
+				return 0, fmt.Errorf("rollback to savepoint error: %w", rbErr)
+			}
+			failed[order.OrderUID] = err
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return 0, fmt.Errorf("release savepoint error: %w", err)
+		}
+		if result.created {
+			created++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit transaction error: %w", err)
+	}
+	return created, nil
+}
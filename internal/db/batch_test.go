@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// badOrder возвращает заказ, который PostgreSQL отвергнет: locale длиннее VARCHAR(10)
+func badOrder(uid string) *model.Order {
+	order := testutil.Order(uid)
+	order.Locale = "much-too-long-locale"
+	return order
+}
+
+func TestInsertOrdersRollsBackBatch(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	orders := ordersAt("batch", 4, time.Second)
+	orders[2] = badOrder("batchbad")
+
+	inserted, err := db.InsertOrders(ctx, orders, BatchOptions{Size: 2})
+	if err == nil {
+		t.Fatal("InsertOrders succeeded with an order the database rejects")
+	}
+	if inserted != 2 {
+		t.Errorf("inserted = %d, want 2 from the committed first batch", inserted)
+	}
+
+	// Первая пачка зафиксирована, вторая откатилась целиком, до третьей вставка не дошла
+	for uid, want := range map[string]int{"batch0000": 1, "batch0001": 1, "batchbad": 0, "batch0003": 0} {
+		if got := countRows(t, db, "orders", uid); got != want {
+			t.Errorf("orders rows for %s = %d, want %d", uid, got, want)
+		}
+	}
+}
+
+func TestInsertOrdersSkipFailed(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	orders := ordersAt("skip", 3, time.Second)
+	orders[1] = badOrder("skipbad")
+
+	inserted, err := db.InsertOrders(ctx, orders, BatchOptions{SkipFailed: true})
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("InsertOrders error = %v, want *BatchError", err)
+	}
+	if inserted != 2 {
+		t.Errorf("inserted = %d, want 2", inserted)
+	}
+	if _, ok := batchErr.Failed["skipbad"]; !ok || len(batchErr.Failed) != 1 {
+		t.Errorf("failed orders = %v, want only skipbad", batchErr.Failed)
+	}
+	for uid, want := range map[string]int{"skip0000": 1, "skipbad": 0, "skip0002": 1} {
+		if got := countRows(t, db, "orders", uid); got != want {
+			t.Errorf("orders rows for %s = %d, want %d", uid, got, want)
+		}
+	}
+}
+
+func TestInsertOrdersCountsOnlyWritten(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	existing := ordersAt("existing", 2, time.Second)
+	if inserted, err := db.InsertOrders(ctx, existing, BatchOptions{}); err != nil || inserted != 2 {
+		t.Fatalf("InsertOrders = %d, %v; want 2 orders", inserted, err)
+	}
+
+	// ON CONFLICT DO NOTHING оставляет существующие заказы как есть, и они не считаются записанными
+	orders := append(ordersAt("fresh", 1, time.Second), existing...)
+	inserted, err := db.InsertOrders(ctx, orders, BatchOptions{Size: 2, SkipFailed: true})
+	if err != nil {
+		t.Fatalf("InsertOrders: %v", err)
+	}
+	if inserted != 1 {
+		t.Errorf("inserted = %d, want only the new order", inserted)
+	}
+}
+
+// benchmarkInsert вставляет по 500 новых заказов за итерацию функцией insert
+func benchmarkInsert(b *testing.B, insert func(ctx context.Context, db *Database, orders []*model.Order) error) {
+	db := newTestDB(b, Options{})
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := range b.N {
+		b.StopTimer()
+		orders := ordersAt(fmt.Sprintf("bench%d_", i), 500, time.Millisecond)
+		b.StartTimer()
+		if err := insert(ctx, db, orders); err != nil {
+			b.Fatalf("insert: %v", err)
+		}
+	}
+}
+
+// BenchmarkInsertOrderSingle каждый заказ в своей транзакции через InsertOrder
+func BenchmarkInsertOrderSingle(b *testing.B) {
+	benchmarkInsert(b, func(ctx context.Context, db *Database, orders []*model.Order) error {
+		for _, order := range orders {
+			if err := db.InsertOrder(ctx, order); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BenchmarkInsertOrdersBatched все 500 заказов в одной транзакции
+func BenchmarkInsertOrdersBatched(b *testing.B) {
+	benchmarkInsert(b, func(ctx context.Context, db *Database, orders []*model.Order) error {
+		_, err := db.InsertOrders(ctx, orders, BatchOptions{Size: len(orders)})
+		return err
+	})
+}
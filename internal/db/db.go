@@ -2,37 +2,113 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go-kafka-postgres/internal/logger"
 	"go-kafka-postgres/internal/model"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrOrderNotFound возвращается, если заказ отсутствует в базе данных
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrOrderDeleted возвращается InsertOrder и UpsertOrder, если сохраненный
+// заказ мягко удален. Запись завершена, но пометку удаления она не снимает,
+// поэтому заказ по-прежнему не отдается при чтении.
+var ErrOrderDeleted = errors.New("order is deleted")
+
 type DatabaseInterface interface {
-	InsertOrder(order *model.Order) error
-	GetAllOrders() ([]*model.Order, error)
-	GetOrderByUID(uid string) (*model.Order, error)
+	InsertOrder(ctx context.Context, order *model.Order) error
+	UpsertOrder(ctx context.Context, order *model.Order) error
+	InsertOrders(ctx context.Context, orders []*model.Order, opts BatchOptions) (int, error)
+	GetAllOrders(ctx context.Context) ([]*model.Order, error)
+	GetOrderByUID(ctx context.Context, uid string) (*model.Order, error)
+	GetDeliveryByUID(ctx context.Context, uid string) (*model.Delivery, error)
+	GetOrderByTrackNumber(ctx context.Context, trackNumber string) (*model.Order, error)
+	GetOrdersByDateRange(ctx context.Context, from, to time.Time) ([]*model.Order, error)
+	GetOrdersByUIDs(ctx context.Context, uids []string) (map[string]*model.Order, error)
+	ListOrdersAfter(ctx context.Context, cursor *Cursor, limit int) ([]*model.Order, error)
+	StreamOrders(ctx context.Context, fn func(*model.Order) error) error
+	CountOrders(ctx context.Context) (int64, error)
+	CountOrdersByDateRange(ctx context.Context, from, to time.Time) (int64, error)
+	SoftDeleteOrder(ctx context.Context, uid string) error
+	UndeleteOrder(ctx context.Context, uid string) error
 	Close()
 }
 
 type Database struct {
-	pool *pgxpool.Pool
+	pool           *pgxpool.Pool
+	aggregateItems bool
+	skipBadItems   bool
+}
+
+// Задержки между проверками соединения при старте; переменные, чтобы тесты не ждали секундами
+var (
+	connectInitialBackoff = 500 * time.Millisecond
+	connectMaxBackoff     = 5 * time.Second
+)
+
+// Options настройки подключения к базе данных
+type Options struct {
+	// ConnectTimeout сколько повторять проверку соединения при старте; 0 — одна попытка
+	ConnectTimeout time.Duration
+	// LogQueries пишет каждый SQL-запрос и его длительность в лог на уровне debug
+	LogQueries bool
+	// AggregateItems загружает товары в GetAllOrders тем же запросом через json_agg
+	// вместо отдельного запроса к items
+	AggregateItems bool
+	// SkipBadItems в UpsertOrder пропускает товары, которые БД отвергла из-за их
+	// содержимого (IsPermanent), вместо отката всего заказа. Каждый товар тогда
+	// пишется в своей точке сохранения; ранее сохраненная версия пропущенного
+	// товара не удаляется
+	SkipBadItems bool
 }
 
-// New создает новое подключение к базе данных
+// New создает новое подключение к базе данных. Если база недоступна,
+// ошибка возвращается сразу; для ожидания запуска БД используется NewWithOptions.
 func New(connString string) (*Database, error) {
-	pool, err := pgxpool.New(context.Background(), connString)
+	return NewWithOptions(connString, Options{})
+}
+
+// NewWithOptions создает подключение к базе данных, повторяя проверку
+// соединения с экспоненциальной задержкой, пока не истечет opts.ConnectTimeout
+func NewWithOptions(connString string, opts Options) (*Database, error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection string: %w", err)
+	}
+	if opts.LogQueries {
+		config.ConnConfig.Tracer = queryLogger{}
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create connection pool: %w", err)
 	}
 
-	if err := pool.Ping(context.Background()); err != nil {
-		return nil, fmt.Errorf("unable to ping database: %w", err)
+	deadline := time.Now().Add(opts.ConnectTimeout)
+	backoff := connectInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := pool.Ping(context.Background())
+		if err == nil {
+			break
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			pool.Close()
+			return nil, fmt.Errorf("unable to ping database: %w", err)
+		}
+		wait := min(backoff, remaining)
+		logger.Warnf("Failed to ping database (attempt %d): %v, retrying in %v", attempt, err, wait)
+		time.Sleep(wait)
+		backoff = min(backoff*2, connectMaxBackoff)
 	}
 
-	return &Database{pool: pool}, nil
+	return &Database{pool: pool, aggregateItems: opts.AggregateItems, skipBadItems: opts.SkipBadItems}, nil
 }
 
 // Close закрывает пул соединений с базой данных
@@ -40,28 +116,104 @@ func (db *Database) Close() {
 	db.pool.Close()
 }
 
-// InsertOrder вставляет новый заказ в базу данных в транзакции
-func (db *Database) InsertOrder(order *model.Order) error {
-	ctx := context.Background()
+// InsertOrder вставляет новый заказ в базу данных в транзакции.
+// Каждая дочерняя строка (delivery, payment, items) вставляется со своим
+// ON CONFLICT DO NOTHING независимо от того, существовала ли строка orders,
+// поэтому повторная доставка заказа дописывает недостающие части,
+// а уже сохраненные не изменяет.
+func (db *Database) InsertOrder(ctx context.Context, order *model.Order) error {
+	return db.writeOrder(ctx, order, writeOptions{})
+}
+
+// UpsertOrder сохраняет заказ как актуальную версию: существующие строки
+// заказа, доставки, оплаты и товаров (по chrt_id) обновляются, а товары,
+// отсутствующие в новой версии заказа, удаляются
+func (db *Database) UpsertOrder(ctx context.Context, order *model.Order) error {
+	return db.writeOrder(ctx, order, writeOptions{upsert: true, skipBadItems: db.skipBadItems})
+}
+
+// writeOptions режим записи заказа
+type writeOptions struct {
+	// upsert обновляет существующие строки вместо их сохранения без изменений
+	upsert bool
+	// skipBadItems пропускает товары, отвергнутые БД (см. Options.SkipBadItems)
+	skipBadItems bool
+}
 
+// ItemError ошибка записи товара заказа с его позицией и chrt_id
+type ItemError struct {
+	// Index позиция товара в order.Items, начиная с 0
+	Index  int
+	ChrtID int
+	Err    error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("insert item %d (chrt_id %d) error: %v", e.Index, e.ChrtID, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// writeOrder записывает заказ в отдельной транзакции
+func (db *Database) writeOrder(ctx context.Context, order *model.Order, opts writeOptions) error {
 	tx, err := db.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction error: %w", err)
 	}
 
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx)
-		}
-	}()
+	// После успешного Commit откат ничего не делает
+	defer tx.Rollback(ctx)
 
+	result, err := writeOrderTx(ctx, tx, order, opts)
+	if err != nil {
+		return err
+	}
+
+	// Фиксируем транзакцию
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction error: %w", err)
+	}
+	if result.deleted {
+		return ErrOrderDeleted
+	}
+	return nil
+}
+
+// writeResult состояние строки заказа после записи
+type writeResult struct {
+	// created строка заказа записана этим вызовом; false — заказ уже был
+	// в БД и ON CONFLICT DO NOTHING оставил его без изменений
+	created bool
+	// deleted сохраненный заказ мягко удален
+	deleted bool
+}
+
+// writeOrderTx записывает строки заказа в переданной транзакции
+func writeOrderTx(ctx context.Context, tx pgx.Tx, order *model.Order, opts writeOptions) (writeResult, error) {
+	upsert := opts.upsert
 	orderQuery := `INSERT INTO orders (
 		order_uid, track_number, entry, locale, internal_signature,
 		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
 	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	ON CONFLICT (order_uid) DO NOTHING`
+	ON CONFLICT (order_uid) DO NOTHING
+	RETURNING deleted_at`
+	if upsert {
+		orderQuery = `INSERT INTO orders (
+		order_uid, track_number, entry, locale, internal_signature,
+		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (order_uid) DO UPDATE SET
+		track_number = EXCLUDED.track_number, entry = EXCLUDED.entry, locale = EXCLUDED.locale,
+		internal_signature = EXCLUDED.internal_signature, customer_id = EXCLUDED.customer_id,
+		delivery_service = EXCLUDED.delivery_service, shardkey = EXCLUDED.shardkey,
+		sm_id = EXCLUDED.sm_id, date_created = EXCLUDED.date_created, oof_shard = EXCLUDED.oof_shard
+	RETURNING deleted_at`
+	}
 
-	_, err = tx.Exec(ctx, orderQuery,
+	var deletedAt *time.Time
+	err := tx.QueryRow(ctx, orderQuery,
 		order.OrderUID,
 		order.TrackNumber,
 		order.Entry,
@@ -71,17 +223,31 @@ func (db *Database) InsertOrder(order *model.Order) error {
 		order.DeliveryService,
 		order.Shardkey,
 		order.SmID,
-		order.DateCreated,
+		order.DateCreated.Time,
 		order.OofShard,
-	)
+	).Scan(&deletedAt)
+	created := !errors.Is(err, pgx.ErrNoRows)
+	if !created {
+		// DO NOTHING не возвращает существующую строку
+		err = tx.QueryRow(ctx, `SELECT deleted_at FROM orders WHERE order_uid = $1`, order.OrderUID).Scan(&deletedAt)
+	}
 	if err != nil {
-		return fmt.Errorf("insert order error: %w", err)
+		return writeResult{}, fmt.Errorf("insert order error: %w", err)
 	}
+	result := writeResult{created: created, deleted: deletedAt != nil}
 
 	deliveryQuery := `INSERT INTO delivery (
 		order_uid, name, phone, zip, city, address, region, email
 	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	ON CONFLICT (order_uid) DO NOTHING`
+	if upsert {
+		deliveryQuery = `INSERT INTO delivery (
+		order_uid, name, phone, zip, city, address, region, email
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (order_uid) DO UPDATE SET
+		name = EXCLUDED.name, phone = EXCLUDED.phone, zip = EXCLUDED.zip, city = EXCLUDED.city,
+		address = EXCLUDED.address, region = EXCLUDED.region, email = EXCLUDED.email`
+	}
 
 	_, err = tx.Exec(ctx, deliveryQuery,
 		order.OrderUID,
@@ -94,7 +260,7 @@ func (db *Database) InsertOrder(order *model.Order) error {
 		order.Delivery.Email,
 	)
 	if err != nil {
-		return fmt.Errorf("insert delivery error: %w", err)
+		return writeResult{}, fmt.Errorf("insert delivery error: %w", err)
 	}
 
 	paymentQuery := `INSERT INTO payment (
@@ -102,6 +268,17 @@ func (db *Database) InsertOrder(order *model.Order) error {
 		amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
 	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	ON CONFLICT (order_uid) DO NOTHING`
+	if upsert {
+		paymentQuery = `INSERT INTO payment (
+		order_uid, transaction, request_id, currency, provider,
+		amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (order_uid) DO UPDATE SET
+		transaction = EXCLUDED.transaction, request_id = EXCLUDED.request_id,
+		currency = EXCLUDED.currency, provider = EXCLUDED.provider, amount = EXCLUDED.amount,
+		payment_dt = EXCLUDED.payment_dt, bank = EXCLUDED.bank, delivery_cost = EXCLUDED.delivery_cost,
+		goods_total = EXCLUDED.goods_total, custom_fee = EXCLUDED.custom_fee`
+	}
 
 	_, err = tx.Exec(ctx, paymentQuery,
 		order.OrderUID,
@@ -117,7 +294,7 @@ func (db *Database) InsertOrder(order *model.Order) error {
 		order.Payment.CustomFee,
 	)
 	if err != nil {
-		return fmt.Errorf("insert payment error: %w", err)
+		return writeResult{}, fmt.Errorf("insert payment error: %w", err)
 	}
 
 	itemQuery := `INSERT INTO items (
@@ -125,9 +302,21 @@ func (db *Database) InsertOrder(order *model.Order) error {
 		sale, size, total_price, nm_id, brand, status
 	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	ON CONFLICT (order_uid, chrt_id) DO NOTHING`
+	if upsert {
+		itemQuery = `INSERT INTO items (
+		order_uid, chrt_id, track_number, price, rid, name,
+		sale, size, total_price, nm_id, brand, status
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	ON CONFLICT (order_uid, chrt_id) DO UPDATE SET
+		track_number = EXCLUDED.track_number, price = EXCLUDED.price, rid = EXCLUDED.rid,
+		name = EXCLUDED.name, sale = EXCLUDED.sale, size = EXCLUDED.size,
+		total_price = EXCLUDED.total_price, nm_id = EXCLUDED.nm_id, brand = EXCLUDED.brand,
+		status = EXCLUDED.status`
+	}
 
-	for _, item := range order.Items {
-		_, err = tx.Exec(ctx, itemQuery,
+	chrtIDs := make([]int, 0, len(order.Items))
+	for i, item := range order.Items {
+		args := []any{
 			order.OrderUID,
 			item.ChrtID,
 			item.TrackNumber,
@@ -140,31 +329,62 @@ func (db *Database) InsertOrder(order *model.Order) error {
 			item.NmID,
 			item.Brand,
 			item.Status,
-		)
+		}
+		// Сохраненную ранее версию товара не удаляем, даже если новую записать не удалось
+		chrtIDs = append(chrtIDs, item.ChrtID)
+
+		if !opts.skipBadItems {
+			if _, err = tx.Exec(ctx, itemQuery, args...); err != nil {
+				return writeResult{}, &ItemError{Index: i, ChrtID: item.ChrtID, Err: err}
+			}
+			continue
+		}
+
+		// Ошибка оператора прерывает всю транзакцию, поэтому каждый товар
+		// пишется в своей точке сохранения
+		if err := writeItemSavepoint(ctx, tx, itemQuery, args); err != nil {
+			if !IsPermanent(err) {
+				return writeResult{}, &ItemError{Index: i, ChrtID: item.ChrtID, Err: err}
+			}
+			logger.Warnf("Skipping item %d (chrt_id %d) of order %s rejected by database: %v",
+				i, item.ChrtID, order.OrderUID, err)
+		}
+	}
+
+	if upsert {
+		// Товары, которых нет в новой версии заказа, удаляем
+		_, err = tx.Exec(ctx, `DELETE FROM items WHERE order_uid = $1 AND NOT (chrt_id = ANY($2))`,
+			order.OrderUID, chrtIDs)
 		if err != nil {
-			return fmt.Errorf("insert item error: %w", err)
+			return writeResult{}, fmt.Errorf("delete removed items error: %w", err)
 		}
 	}
 
-	// Фиксируем транзакцию
-	return tx.Commit(ctx)
+	return result, nil
 }
 
-// GetAllOrders извлекает все заказы из базы данных
-func (db *Database) GetAllOrders() ([]*model.Order, error) {
-	ctx := context.Background()
-
-	query := `
-		SELECT 
-			o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
-			o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
-			d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
-			p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
-			p.bank, p.delivery_cost, p.goods_total, p.custom_fee
-		FROM orders o
-		LEFT JOIN delivery d ON o.order_uid = d.order_uid
-		LEFT JOIN payment p ON o.order_uid = p.order_uid
-	`
+// writeItemSavepoint записывает товар в точке сохранения и откатывает ее при ошибке
+func writeItemSavepoint(ctx context.Context, tx pgx.Tx, query string, args []any) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin savepoint error: %w", err)
+	}
+	if _, err := savepoint.Exec(ctx, query, args...); err != nil {
+		if rbErr := savepoint.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("rollback savepoint error: %w", rbErr)
+		}
+		return err
+	}
+	return savepoint.Commit(ctx)
+}
+
+// GetAllOrders извлекает все заказы из базы данных (кроме мягко удаленных, см. WithDeleted)
+func (db *Database) GetAllOrders(ctx context.Context) ([]*model.Order, error) {
+	if db.aggregateItems {
+		return db.getAllOrdersAggregated(ctx)
+	}
+
+	query := orderSelectQuery + ` WHERE ` + notDeleted(ctx)
 
 	rows, err := db.pool.Query(ctx, query)
 	if err != nil {
@@ -174,55 +394,21 @@ func (db *Database) GetAllOrders() ([]*model.Order, error) {
 
 	ordersMap := make(map[string]*model.Order)
 	for rows.Next() {
-		var order model.Order
-		var delivery model.Delivery
-		var payment model.Payment
-
-		err := rows.Scan(
-			&order.OrderUID,
-			&order.TrackNumber,
-			&order.Entry,
-			&order.Locale,
-			&order.InternalSignature,
-			&order.CustomerID,
-			&order.DeliveryService,
-			&order.Shardkey,
-			&order.SmID,
-			&order.DateCreated,
-			&order.OofShard,
-			&delivery.Name,
-			&delivery.Phone,
-			&delivery.Zip,
-			&delivery.City,
-			&delivery.Address,
-			&delivery.Region,
-			&delivery.Email,
-			&payment.Transaction,
-			&payment.RequestID,
-			&payment.Currency,
-			&payment.Provider,
-			&payment.Amount,
-			&payment.PaymentDt,
-			&payment.Bank,
-			&payment.DeliveryCost,
-			&payment.GoodsTotal,
-			&payment.CustomFee,
-		)
-		if err != nil {
+		var row orderRow
+		if err := rows.Scan(row.scanTargets()...); err != nil {
 			logger.Errorf("Error scanning order: %v", err)
 			continue
 		}
 
-		order.Delivery = delivery
-		order.Payment = payment
-		ordersMap[order.OrderUID] = &order
+		order := row.toOrder()
+		ordersMap[order.OrderUID] = order
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	itemsQuery := `SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status FROM items`
+	itemsQuery := `SELECT order_uid, ` + itemColumns + ` FROM items`
 	itemsRows, err := db.pool.Query(ctx, itemsQuery)
 	if err != nil {
 		return nil, fmt.Errorf("query items error: %w", err)
@@ -230,30 +416,16 @@ func (db *Database) GetAllOrders() ([]*model.Order, error) {
 	defer itemsRows.Close()
 
 	for itemsRows.Next() {
-		var item model.Item
+		var row itemRow
 		var orderUID string
 
-		err := itemsRows.Scan(
-			&orderUID,
-			&item.ChrtID,
-			&item.TrackNumber,
-			&item.Price,
-			&item.Rid,
-			&item.Name,
-			&item.Sale,
-			&item.Size,
-			&item.TotalPrice,
-			&item.NmID,
-			&item.Brand,
-			&item.Status,
-		)
-		if err != nil {
+		if err := itemsRows.Scan(append([]any{&orderUID}, row.scanTargets()...)...); err != nil {
 			logger.Errorf("Error scanning item: %v", err)
 			continue
 		}
 
 		if order, exists := ordersMap[orderUID]; exists {
-			order.Items = append(order.Items, item)
+			order.Items = append(order.Items, row.toItem(orderUID))
 		}
 	}
 
@@ -270,67 +442,21 @@ func (db *Database) GetAllOrders() ([]*model.Order, error) {
 }
 
 // GetOrderByUID извлекает конкретный заказ по его UID
-func (db *Database) GetOrderByUID(uid string) (*model.Order, error) {
-	ctx := context.Background()
-
-	query := `
-		SELECT 
-			o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
-			o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
-			d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
-			p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
-			p.bank, p.delivery_cost, p.goods_total, p.custom_fee
-		FROM orders o
-		LEFT JOIN delivery d ON o.order_uid = d.order_uid
-		LEFT JOIN payment p ON o.order_uid = p.order_uid
-		WHERE o.order_uid = $1
-	`
-
-	var order model.Order
-	var delivery model.Delivery
-	var payment model.Payment
-
-	err := db.pool.QueryRow(ctx, query, uid).Scan(
-		&order.OrderUID,
-		&order.TrackNumber,
-		&order.Entry,
-		&order.Locale,
-		&order.InternalSignature,
-		&order.CustomerID,
-		&order.DeliveryService,
-		&order.Shardkey,
-		&order.SmID,
-		&order.DateCreated,
-		&order.OofShard,
-		&delivery.Name,
-		&delivery.Phone,
-		&delivery.Zip,
-		&delivery.City,
-		&delivery.Address,
-		&delivery.Region,
-		&delivery.Email,
-		&payment.Transaction,
-		&payment.RequestID,
-		&payment.Currency,
-		&payment.Provider,
-		&payment.Amount,
-		&payment.PaymentDt,
-		&payment.Bank,
-		&payment.DeliveryCost,
-		&payment.GoodsTotal,
-		&payment.CustomFee,
-	)
+func (db *Database) GetOrderByUID(ctx context.Context, uid string) (*model.Order, error) {
+	query := orderSelectQuery + ` WHERE o.order_uid = $1 AND ` + notDeleted(ctx)
+
+	var row orderRow
+	err := db.pool.QueryRow(ctx, query, uid).Scan(row.scanTargets()...)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("order not found")
+			return nil, ErrOrderNotFound
 		}
 		return nil, fmt.Errorf("query order error: %w", err)
 	}
 
-	order.Delivery = delivery
-	order.Payment = payment
+	order := row.toOrder()
 
-	itemsQuery := `SELECT chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status FROM items WHERE order_uid = $1`
+	itemsQuery := `SELECT ` + itemColumns + ` FROM items WHERE order_uid = $1`
 	itemsRows, err := db.pool.Query(ctx, itemsQuery, uid)
 	if err != nil {
 		return nil, fmt.Errorf("query items error: %w", err)
@@ -338,30 +464,17 @@ func (db *Database) GetOrderByUID(uid string) (*model.Order, error) {
 	defer itemsRows.Close()
 
 	for itemsRows.Next() {
-		var item model.Item
-		err := itemsRows.Scan(
-			&item.ChrtID,
-			&item.TrackNumber,
-			&item.Price,
-			&item.Rid,
-			&item.Name,
-			&item.Sale,
-			&item.Size,
-			&item.TotalPrice,
-			&item.NmID,
-			&item.Brand,
-			&item.Status,
-		)
-		if err != nil {
+		var row itemRow
+		if err := itemsRows.Scan(row.scanTargets()...); err != nil {
 			logger.Errorf("Error scanning item: %v", err)
 			continue
 		}
-		order.Items = append(order.Items, item)
+		order.Items = append(order.Items, row.toItem(uid))
 	}
 
 	if err := itemsRows.Err(); err != nil {
 		return nil, fmt.Errorf("items rows iteration error: %w", err)
 	}
 
-	return &order, nil
+	return order, nil
 }
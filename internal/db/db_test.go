@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// countRows возвращает число строк таблицы для заказа
+func countRows(tb testing.TB, db *Database, table, uid string) int {
+	tb.Helper()
+	var count int
+	err := db.pool.QueryRow(context.Background(),
+		`SELECT count(*) FROM `+table+` WHERE order_uid = $1`, uid).Scan(&count)
+	if err != nil {
+		tb.Fatalf("count %s rows: %v", table, err)
+	}
+	return count
+}
+
+func TestInsertOrderCompletesPartialOrder(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("partial1")
+	order.Items = append(order.Items, order.Items[0])
+	order.Items[1].ChrtID = 9934931
+
+	// Строка заказа осталась после сбоя, дочерних строк нет
+	exec(t, db, `INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature,
+		customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID,
+		order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated.Time, order.OofShard)
+	// И один из товаров
+	exec(t, db, `INSERT INTO items (order_uid, chrt_id, track_number, price, rid, name, sale, size,
+		total_price, nm_id, brand, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		order.OrderUID, order.Items[0].ChrtID, order.Items[0].TrackNumber, order.Items[0].Price,
+		order.Items[0].Rid, order.Items[0].Name, order.Items[0].Sale, order.Items[0].Size,
+		order.Items[0].TotalPrice, order.Items[0].NmID, order.Items[0].Brand, order.Items[0].Status)
+
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+
+	for table, want := range map[string]int{"delivery": 1, "payment": 1, "items": 2} {
+		if got := countRows(t, db, table, order.OrderUID); got != want {
+			t.Errorf("%s rows = %d, want %d", table, got, want)
+		}
+	}
+	got, err := db.GetOrderByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetOrderByUID: %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Errorf("order = %+v, want %+v", got, order)
+	}
+}
+
+func TestInsertOrderKeepsExistingRows(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("existing1")
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+
+	redelivered := testutil.Order(order.OrderUID)
+	redelivered.Delivery.City = "Moscow"
+	redelivered.Payment.Bank = "sber"
+	redelivered.Items[0].Price = 1
+	if err := db.InsertOrder(ctx, redelivered); err != nil {
+		t.Fatalf("second InsertOrder: %v", err)
+	}
+
+	got, err := db.GetOrderByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetOrderByUID: %v", err)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Errorf("redelivery changed the stored order: got %+v, want %+v", got, order)
+	}
+}
+
+func TestUpsertOrderReplacesItems(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("upsert1")
+	order.Items = append(order.Items, order.Items[0])
+	order.Items[1].ChrtID = 9934931
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+
+	// Цена первого товара изменилась, второй товар удален из заказа
+	updated := testutil.Order(order.OrderUID)
+	updated.Items[0].Price = 500
+	updated.Items[0].Sale = 10
+	updated.Items[0].TotalPrice = 450
+	updated.Items[0].Status = 203
+	if err := db.UpsertOrder(ctx, updated); err != nil {
+		t.Fatalf("UpsertOrder: %v", err)
+	}
+
+	if got := countRows(t, db, "items", order.OrderUID); got != 1 {
+		t.Errorf("items rows = %d, want 1 after the removed item is deleted", got)
+	}
+	got, err := db.GetOrderByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetOrderByUID: %v", err)
+	}
+	if !reflect.DeepEqual(got.Items, updated.Items) {
+		t.Errorf("items = %+v, want %+v", got.Items, updated.Items)
+	}
+}
+
+// orderWithBadItem возвращает заказ из трех товаров, третий из которых БД
+// отвергает: size длиннее VARCHAR(10)
+func orderWithBadItem(uid string) *model.Order {
+	order := testutil.Order(uid)
+	for i := 1; i < 3; i++ {
+		item := order.Items[0]
+		item.ChrtID += i
+		order.Items = append(order.Items, item)
+	}
+	order.Items[2].Size = "much-too-long-size"
+	return order
+}
+
+func TestInsertOrderNamesFailingItem(t *testing.T) {
+	db := newTestDB(t, Options{})
+	order := orderWithBadItem("baditem1")
+
+	err := db.InsertOrder(context.Background(), order)
+	var itemErr *ItemError
+	if !errors.As(err, &itemErr) {
+		t.Fatalf("InsertOrder error = %v, want *ItemError", err)
+	}
+	if itemErr.Index != 2 || itemErr.ChrtID != order.Items[2].ChrtID {
+		t.Errorf("failing item = %d (chrt_id %d), want 2 (chrt_id %d)", itemErr.Index, itemErr.ChrtID, order.Items[2].ChrtID)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || !IsPermanent(err) {
+		t.Errorf("error = %v, want the wrapped permanent PostgreSQL error", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "item 2") || !strings.Contains(msg, strconv.Itoa(order.Items[2].ChrtID)) {
+		t.Errorf("error = %q, want it to name item 2 and its chrt_id", msg)
+	}
+
+	// Ошибка товара откатывает весь заказ
+	if got := countRows(t, db, "orders", order.OrderUID); got != 0 {
+		t.Errorf("orders rows = %d, want 0 after rollback", got)
+	}
+}
+
+func TestUpsertOrderSkipBadItems(t *testing.T) {
+	db := newTestDB(t, Options{SkipBadItems: true})
+	order := orderWithBadItem("baditem2")
+
+	if err := db.UpsertOrder(context.Background(), order); err != nil {
+		t.Fatalf("UpsertOrder: %v", err)
+	}
+	if got := countRows(t, db, "items", order.OrderUID); got != 2 {
+		t.Errorf("items rows = %d, want 2 without the rejected item", got)
+	}
+
+	// Без SkipBadItems в InsertOrder плохой товар по-прежнему откатывает заказ
+	if err := db.InsertOrder(context.Background(), orderWithBadItem("baditem3")); err == nil {
+		t.Error("InsertOrder with a bad item succeeded, want error")
+	}
+}
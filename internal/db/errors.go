@@ -0,0 +1,29 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Классы ошибок PostgreSQL, которые не исправляются повтором запроса:
+// некорректные данные (22) и нарушение ограничений целостности (23)
+const (
+	pgClassDataException       = "22"
+	pgClassIntegrityConstraint = "23"
+)
+
+// IsPermanent сообщает, что запись отклонена самой БД из-за содержимого заказа
+// и повтор с теми же данными завершится той же ошибкой. Ошибки соединения,
+// таймауты и прочие сбои считаются временными.
+func IsPermanent(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return false
+	}
+	switch pgErr.Code[:2] {
+	case pgClassDataException, pgClassIntegrityConstraint:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "check violation", err: &pgconn.PgError{Code: "23514"}, want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: true},
+		{name: "value too long", err: &pgconn.PgError{Code: "22001"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("insert order error: %w", &pgconn.PgError{Code: "22P02"}), want: true},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}},
+		{name: "empty code", err: &pgconn.PgError{}},
+		{name: "deadline", err: context.DeadlineExceeded},
+		{name: "plain error", err: errors.New("connection reset")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermanent(tt.err); got != tt.want {
+				t.Errorf("IsPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
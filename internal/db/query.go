@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// orderSelectQuery базовый запрос заказа вместе с доставкой и оплатой
+const orderSelectQuery = `SELECT ` + orderColumns + orderFrom
+
+// orderColumns колонки заказа, доставки и оплаты в порядке orderRow.scanTargets
+const orderColumns = `
+			o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+			o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+			d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+			p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
+			p.bank, p.delivery_cost, p.goods_total, p.custom_fee`
+
+// orderFrom соединение заказа с доставкой и оплатой для orderColumns
+const orderFrom = `
+		FROM orders o
+		LEFT JOIN delivery d ON o.order_uid = d.order_uid
+		LEFT JOIN payment p ON o.order_uid = p.order_uid
+	`
+
+// MaxRangeOrders максимальное число заказов, возвращаемых выборкой по диапазону дат
+const MaxRangeOrders = 1000
+
+// GetOrdersByDateRange извлекает заказы, созданные в интервале [from, to] включительно.
+// Возвращается не более MaxRangeOrders заказов, отсортированных по дате создания.
+func (db *Database) GetOrdersByDateRange(ctx context.Context, from, to time.Time) ([]*model.Order, error) {
+	if from.After(to) {
+		return nil, fmt.Errorf("invalid date range: from %v is after to %v", from, to)
+	}
+
+	query := orderSelectQuery + `
+		WHERE o.date_created BETWEEN $1 AND $2 AND ` + notDeleted(ctx) + `
+		ORDER BY o.date_created, o.order_uid
+		LIMIT $3`
+
+	return db.queryOrders(ctx, query, from, to, MaxRangeOrders)
+}
+
+// CountOrders возвращает общее число заказов
+func (db *Database) CountOrders(ctx context.Context) (int64, error) {
+	var count int64
+	if err := db.pool.QueryRow(ctx, `SELECT count(*) FROM orders o WHERE `+notDeleted(ctx)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count orders error: %w", err)
+	}
+	return count, nil
+}
+
+// CountOrdersByDateRange возвращает число заказов, созданных в интервале [from, to] включительно
+func (db *Database) CountOrdersByDateRange(ctx context.Context, from, to time.Time) (int64, error) {
+	if from.After(to) {
+		return 0, fmt.Errorf("invalid date range: from %v is after to %v", from, to)
+	}
+
+	var count int64
+	query := `SELECT count(*) FROM orders o WHERE o.date_created BETWEEN $1 AND $2 AND ` + notDeleted(ctx)
+	err := db.pool.QueryRow(ctx, query, from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count orders error: %w", err)
+	}
+	return count, nil
+}
+
+// Cursor позиция в списке заказов, упорядоченном по (date_created, order_uid)
+type Cursor struct {
+	DateCreated time.Time
+	OrderUID    string
+}
+
+// ListOrdersAfter извлекает до limit заказов, следующих за cursor в порядке
+// (date_created, order_uid); nil cursor означает начало списка. В отличие от
+// OFFSET страницы не пересекаются и не теряют строки при вставке новых заказов.
+func (db *Database) ListOrdersAfter(ctx context.Context, cursor *Cursor, limit int) ([]*model.Order, error) {
+	if limit <= 0 || limit > MaxRangeOrders {
+		return nil, fmt.Errorf("invalid limit %d, expected 1..%d", limit, MaxRangeOrders)
+	}
+
+	if cursor == nil {
+		query := orderSelectQuery + `
+		WHERE o.date_created IS NOT NULL AND ` + notDeleted(ctx) + `
+		ORDER BY o.date_created, o.order_uid
+		LIMIT $1`
+		return db.queryOrders(ctx, query, limit)
+	}
+
+	query := orderSelectQuery + `
+		WHERE (o.date_created, o.order_uid) > ($1, $2) AND ` + notDeleted(ctx) + `
+		ORDER BY o.date_created, o.order_uid
+		LIMIT $3`
+	return db.queryOrders(ctx, query, cursor.DateCreated, cursor.OrderUID, limit)
+}
+
+// GetOrderByTrackNumber извлекает заказ по трек-номеру. Если трек-номер
+// встречается у нескольких заказов, возвращается самый новый.
+func (db *Database) GetOrderByTrackNumber(ctx context.Context, trackNumber string) (*model.Order, error) {
+	query := orderSelectQuery + `
+		WHERE o.track_number = $1 AND ` + notDeleted(ctx) + `
+		ORDER BY o.date_created DESC NULLS LAST, o.order_uid
+		LIMIT 2`
+
+	orders, err := db.queryOrders(ctx, query, trackNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	if len(orders) > 1 {
+		logger.Warnf("Track number %s belongs to several orders, returning the most recent %s",
+			trackNumber, orders[0].OrderUID)
+	}
+	return orders[0], nil
+}
+
+// GetDeliveryByUID извлекает только данные доставки заказа. Если заказа нет,
+// возвращается ErrOrderNotFound; если у заказа нет строки доставки — пустая доставка.
+func (db *Database) GetDeliveryByUID(ctx context.Context, uid string) (*model.Delivery, error) {
+	query := `
+		SELECT d.name, d.phone, d.zip, d.city, d.address, d.region, d.email
+		FROM orders o
+		LEFT JOIN delivery d ON o.order_uid = d.order_uid
+		WHERE o.order_uid = $1 AND ` + notDeleted(ctx)
+
+	var row orderRow
+	err := db.pool.QueryRow(ctx, query, uid).Scan(
+		&row.deliveryName,
+		&row.deliveryPhone,
+		&row.deliveryZip,
+		&row.deliveryCity,
+		&row.deliveryAddress,
+		&row.deliveryRegion,
+		&row.deliveryEmail,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("query delivery error: %w", err)
+	}
+
+	delivery := row.toOrder().Delivery
+	return &delivery, nil
+}
+
+// GetOrdersByUIDs извлекает заказы по списку UID одним запросом.
+// Отсутствующие UID в результате не представлены.
+func (db *Database) GetOrdersByUIDs(ctx context.Context, uids []string) (map[string]*model.Order, error) {
+	result := make(map[string]*model.Order, len(uids))
+	if len(uids) == 0 {
+		return result, nil
+	}
+
+	query := orderSelectQuery + ` WHERE o.order_uid = ANY($1) AND ` + notDeleted(ctx)
+
+	orders, err := db.queryOrders(ctx, query, uids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		result[order.OrderUID] = order
+	}
+	return result, nil
+}
+
+// queryOrders выполняет запрос заказов (orderSelectQuery с условиями)
+// и подгружает товары одним дополнительным запросом. Порядок строк сохраняется.
+func (db *Database) queryOrders(ctx context.Context, query string, args ...any) ([]*model.Order, error) {
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query orders error: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*model.Order
+	ordersMap := make(map[string]*model.Order)
+	for rows.Next() {
+		var row orderRow
+		if err := rows.Scan(row.scanTargets()...); err != nil {
+			logger.Errorf("Error scanning order: %v", err)
+			continue
+		}
+
+		order := row.toOrder()
+		orders = append(orders, order)
+		ordersMap[order.OrderUID] = order
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	if err := db.attachItems(ctx, ordersMap); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// attachItems загружает товары для переданных заказов
+func (db *Database) attachItems(ctx context.Context, ordersMap map[string]*model.Order) error {
+	uids := make([]string, 0, len(ordersMap))
+	for uid := range ordersMap {
+		uids = append(uids, uid)
+	}
+
+	itemsQuery := `SELECT order_uid, ` + itemColumns + ` FROM items WHERE order_uid = ANY($1)`
+	itemsRows, err := db.pool.Query(ctx, itemsQuery, uids)
+	if err != nil {
+		return fmt.Errorf("query items error: %w", err)
+	}
+	defer itemsRows.Close()
+
+	for itemsRows.Next() {
+		var row itemRow
+		var orderUID string
+
+		if err := itemsRows.Scan(append([]any{&orderUID}, row.scanTargets()...)...); err != nil {
+			logger.Errorf("Error scanning item: %v", err)
+			continue
+		}
+
+		if order, exists := ordersMap[orderUID]; exists {
+			order.Items = append(order.Items, row.toItem(orderUID))
+		}
+	}
+
+	if err := itemsRows.Err(); err != nil {
+		return fmt.Errorf("items rows iteration error: %w", err)
+	}
+
+	return nil
+}
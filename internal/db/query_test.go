@@ -0,0 +1,224 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// baseTime дата создания первого заказа в тестах выборок
+var baseTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// ordersAt возвращает заказы prefix0000..prefixN-1, созданные с шагом step начиная с baseTime
+func ordersAt(prefix string, n int, step time.Duration) []*model.Order {
+	orders := make([]*model.Order, n)
+	for i := range orders {
+		orders[i] = testutil.Order(fmt.Sprintf("%s%04d", prefix, i))
+		orders[i].DateCreated = model.NewTimestamp(baseTime.Add(time.Duration(i) * step))
+	}
+	return orders
+}
+
+// insertOrdersAt вставляет заказы ordersAt одной пачкой
+func insertOrdersAt(tb testing.TB, db *Database, prefix string, n int, step time.Duration) []*model.Order {
+	tb.Helper()
+	orders := ordersAt(prefix, n, step)
+	if _, err := db.InsertOrders(context.Background(), orders, BatchOptions{Size: 500}); err != nil {
+		tb.Fatalf("InsertOrders: %v", err)
+	}
+	return orders
+}
+
+// orderUIDs возвращает UID заказов в порядке следования
+func orderUIDs(orders []*model.Order) []string {
+	uids := make([]string, len(orders))
+	for i, order := range orders {
+		uids[i] = order.OrderUID
+	}
+	return uids
+}
+
+func TestGetOrdersByDateRangeInclusive(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	insertOrdersAt(t, db, "range", 4, time.Second)
+
+	orders, err := db.GetOrdersByDateRange(ctx, baseTime.Add(time.Second), baseTime.Add(2*time.Second))
+	if err != nil {
+		t.Fatalf("GetOrdersByDateRange: %v", err)
+	}
+	if got := fmt.Sprint(orderUIDs(orders)); got != "[range0001 range0002]" {
+		t.Errorf("orders = %s, want both boundary orders [range0001 range0002]", got)
+	}
+	for _, order := range orders {
+		if len(order.Items) != 1 {
+			t.Errorf("order %s has %d items, want 1", order.OrderUID, len(order.Items))
+		}
+	}
+
+	// Точка: from == to
+	orders, err = db.GetOrdersByDateRange(ctx, baseTime.Add(3*time.Second), baseTime.Add(3*time.Second))
+	if err != nil || len(orders) != 1 || orders[0].OrderUID != "range0003" {
+		t.Errorf("single-point range = %v, %v; want [range0003]", orderUIDs(orders), err)
+	}
+
+	count, err := db.CountOrdersByDateRange(ctx, baseTime, baseTime.Add(time.Second))
+	if err != nil || count != 2 {
+		t.Errorf("CountOrdersByDateRange = %d, %v; want 2", count, err)
+	}
+
+	if _, err := db.GetOrdersByDateRange(ctx, baseTime.Add(time.Second), baseTime); err == nil {
+		t.Error("GetOrdersByDateRange with from after to succeeded, want error")
+	}
+}
+
+func TestGetOrdersByDateRangeCap(t *testing.T) {
+	db := newTestDB(t, Options{})
+	insertOrdersAt(t, db, "cap", MaxRangeOrders+1, time.Millisecond)
+
+	orders, err := db.GetOrdersByDateRange(context.Background(), baseTime, baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetOrdersByDateRange: %v", err)
+	}
+	if len(orders) != MaxRangeOrders {
+		t.Errorf("got %d orders, want the cap %d", len(orders), MaxRangeOrders)
+	}
+}
+
+func TestGetOrdersByUIDs(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	orders := insertOrdersAt(t, db, "batch", 3, time.Second)
+	// Второй товар у batch0001
+	exec(t, db, `INSERT INTO items (order_uid, chrt_id, track_number, price, rid, name, sale, size,
+		total_price, nm_id, brand, status) SELECT order_uid, 1, track_number, price, rid, name, sale, size,
+		total_price, nm_id, brand, status FROM items WHERE order_uid = $1`, orders[1].OrderUID)
+
+	got, err := db.GetOrdersByUIDs(ctx, []string{"batch0000", "missing", "batch0001"})
+	if err != nil {
+		t.Fatalf("GetOrdersByUIDs: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d orders, want 2: %v", len(got), got)
+	}
+	if _, ok := got["missing"]; ok {
+		t.Error("missing uid is present in the result")
+	}
+	if order := got["batch0000"]; order == nil || len(order.Items) != 1 {
+		t.Errorf("batch0000 = %+v, want the order with 1 item", order)
+	}
+	if order := got["batch0001"]; order == nil || len(order.Items) != 2 {
+		t.Errorf("batch0001 = %+v, want the order with 2 items", order)
+	}
+
+	empty, err := db.GetOrdersByUIDs(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("GetOrdersByUIDs(nil) = %v, %v; want an empty map", empty, err)
+	}
+}
+
+func TestListOrdersAfterPages(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	want := orderUIDs(insertOrdersAt(t, db, "page", 5, time.Second))
+
+	var got []string
+	var cursor *Cursor
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatalf("pagination did not finish after %d pages", pages)
+		}
+		orders, err := db.ListOrdersAfter(ctx, cursor, 2)
+		if err != nil {
+			t.Fatalf("ListOrdersAfter: %v", err)
+		}
+		got = append(got, orderUIDs(orders)...)
+		if len(orders) < 2 {
+			break
+		}
+		last := orders[len(orders)-1]
+		cursor = &Cursor{DateCreated: last.DateCreated.Time, OrderUID: last.OrderUID}
+
+		if pages == 0 {
+			// Заказы, вставленные между страницами: до курсора, с его датой и после последнего
+			early := testutil.Order("early")
+			early.DateCreated = model.NewTimestamp(baseTime.Add(-time.Hour))
+			tie := testutil.Order(last.OrderUID + "b")
+			tie.DateCreated = last.DateCreated
+			late := testutil.Order("late")
+			late.DateCreated = model.NewTimestamp(baseTime.Add(time.Hour))
+			if _, err := db.InsertOrders(ctx, []*model.Order{early, tie, late}, BatchOptions{Size: 500}); err != nil {
+				t.Fatalf("InsertOrders: %v", err)
+			}
+			want = slices.Concat(want[:2], []string{tie.OrderUID}, want[2:], []string{late.OrderUID})
+		}
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("paged orders = %v, want %v without overlaps or gaps", got, want)
+	}
+}
+
+func TestGetOrderByTrackNumber(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	orders := insertOrdersAt(t, db, "track", 3, time.Second)
+	logs := observeLogs(t, zapcore.WarnLevel)
+
+	got, err := db.GetOrderByTrackNumber(ctx, orders[0].TrackNumber)
+	if err != nil {
+		t.Fatalf("GetOrderByTrackNumber: %v", err)
+	}
+	if got.OrderUID != orders[2].OrderUID {
+		t.Errorf("order_uid = %q, want the most recent %q", got.OrderUID, orders[2].OrderUID)
+	}
+	if len(got.Items) != 1 {
+		t.Errorf("items = %d, want 1", len(got.Items))
+	}
+	if logs.FilterMessageSnippet("several orders").Len() != 1 {
+		t.Error("shared track number was not logged")
+	}
+
+	if _, err := db.GetOrderByTrackNumber(ctx, "WBILMMISSING"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("GetOrderByTrackNumber(missing) error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestGetDeliveryByUID(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("delivery1")
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+
+	got, err := db.GetDeliveryByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetDeliveryByUID: %v", err)
+	}
+	if *got != order.Delivery {
+		t.Errorf("delivery = %+v, want %+v", *got, order.Delivery)
+	}
+
+	if _, err := db.GetDeliveryByUID(ctx, "missing"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("GetDeliveryByUID(missing) error = %v, want ErrOrderNotFound", err)
+	}
+
+	// Заказ без строки доставки существует: возвращается пустая доставка
+	exec(t, db, `DELETE FROM delivery WHERE order_uid = $1`, order.OrderUID)
+	got, err = db.GetDeliveryByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetDeliveryByUID without delivery row: %v", err)
+	}
+	if *got != (model.Delivery{}) {
+		t.Errorf("delivery = %+v, want zero value", *got)
+	}
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// orderRow строка результата запроса заказа с LEFT JOIN на delivery и payment.
+// Колонки присоединенных таблиц сканируются в nullable-типы, так как строки
+// доставки или оплаты может не быть.
+type orderRow struct {
+	order model.Order
+
+	deliveryName    pgtype.Text
+	deliveryPhone   pgtype.Text
+	deliveryZip     pgtype.Text
+	deliveryCity    pgtype.Text
+	deliveryAddress pgtype.Text
+	deliveryRegion  pgtype.Text
+	deliveryEmail   pgtype.Text
+
+	paymentTransaction  pgtype.Text
+	paymentRequestID    pgtype.Text
+	paymentCurrency     pgtype.Text
+	paymentProvider     pgtype.Text
+	paymentAmount       pgtype.Int4
+	paymentDt           pgtype.Int8
+	paymentBank         pgtype.Text
+	paymentDeliveryCost pgtype.Int4
+	paymentGoodsTotal   pgtype.Int4
+	paymentCustomFee    pgtype.Int4
+}
+
+// scanTargets возвращает указатели для Scan в порядке колонок запроса
+func (r *orderRow) scanTargets() []any {
+	return []any{
+		&r.order.OrderUID,
+		&r.order.TrackNumber,
+		&r.order.Entry,
+		&r.order.Locale,
+		&r.order.InternalSignature,
+		&r.order.CustomerID,
+		&r.order.DeliveryService,
+		&r.order.Shardkey,
+		&r.order.SmID,
+		&r.order.DateCreated.Time,
+		&r.order.OofShard,
+		&r.deliveryName,
+		&r.deliveryPhone,
+		&r.deliveryZip,
+		&r.deliveryCity,
+		&r.deliveryAddress,
+		&r.deliveryRegion,
+		&r.deliveryEmail,
+		&r.paymentTransaction,
+		&r.paymentRequestID,
+		&r.paymentCurrency,
+		&r.paymentProvider,
+		&r.paymentAmount,
+		&r.paymentDt,
+		&r.paymentBank,
+		&r.paymentDeliveryCost,
+		&r.paymentGoodsTotal,
+		&r.paymentCustomFee,
+	}
+}
+
+// toOrder собирает заказ; отсутствующие значения остаются нулевыми
+func (r *orderRow) toOrder() *model.Order {
+	order := r.order
+	// pgx возвращает timestamptz в локальной зоне процесса
+	order.DateCreated = model.NewTimestamp(order.DateCreated.Time)
+
+	order.Delivery = model.Delivery{
+		Name:    r.deliveryName.String,
+		Phone:   r.deliveryPhone.String,
+		Zip:     r.deliveryZip.String,
+		City:    r.deliveryCity.String,
+		Address: r.deliveryAddress.String,
+		Region:  r.deliveryRegion.String,
+		Email:   r.deliveryEmail.String,
+	}
+
+	order.Payment = model.Payment{
+		Transaction:  r.paymentTransaction.String,
+		RequestID:    r.paymentRequestID.String,
+		Currency:     r.paymentCurrency.String,
+		Provider:     r.paymentProvider.String,
+		Amount:       int(r.paymentAmount.Int32),
+		PaymentDt:    r.paymentDt.Int64,
+		Bank:         r.paymentBank.String,
+		DeliveryCost: int(r.paymentDeliveryCost.Int32),
+		GoodsTotal:   int(r.paymentGoodsTotal.Int32),
+		CustomFee:    int(r.paymentCustomFee.Int32),
+	}
+
+	return &order
+}
+
+// itemRow строка товара. Все колонки таблицы items допускают NULL, поэтому
+// сканируются в nullable-типы: NULL в одном поле не должен отбрасывать товар.
+type itemRow struct {
+	chrtID      pgtype.Int8
+	trackNumber pgtype.Text
+	price       pgtype.Int4
+	rid         pgtype.Text
+	name        pgtype.Text
+	sale        pgtype.Int4
+	size        pgtype.Text
+	totalPrice  pgtype.Int4
+	nmID        pgtype.Int8
+	brand       pgtype.Text
+	status      pgtype.Int4
+}
+
+// itemColumns колонки товара в порядке itemRow.scanTargets
+const itemColumns = `chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status`
+
+// scanTargets возвращает указатели для Scan в порядке itemColumns
+func (r *itemRow) scanTargets() []any {
+	return []any{
+		&r.chrtID,
+		&r.trackNumber,
+		&r.price,
+		&r.rid,
+		&r.name,
+		&r.sale,
+		&r.size,
+		&r.totalPrice,
+		&r.nmID,
+		&r.brand,
+		&r.status,
+	}
+}
+
+// toItem собирает товар, заменяя NULL нулевыми значениями
+func (r *itemRow) toItem(orderUID string) model.Item {
+	var nullFields []string
+	for _, field := range []struct {
+		name  string
+		valid bool
+	}{
+		{"chrt_id", r.chrtID.Valid},
+		{"track_number", r.trackNumber.Valid},
+		{"price", r.price.Valid},
+		{"rid", r.rid.Valid},
+		{"name", r.name.Valid},
+		{"sale", r.sale.Valid},
+		{"size", r.size.Valid},
+		{"total_price", r.totalPrice.Valid},
+		{"nm_id", r.nmID.Valid},
+		{"brand", r.brand.Valid},
+		{"status", r.status.Valid},
+	} {
+		if !field.valid {
+			nullFields = append(nullFields, field.name)
+		}
+	}
+	if len(nullFields) > 0 {
+		logger.Debug("Item has NULL fields, using zero values",
+			zap.String("order_uid", orderUID), zap.Int64("chrt_id", r.chrtID.Int64), zap.Strings("fields", nullFields))
+	}
+
+	return model.Item{
+		ChrtID:      int(r.chrtID.Int64),
+		TrackNumber: r.trackNumber.String,
+		Price:       int(r.price.Int32),
+		Rid:         r.rid.String,
+		Name:        r.name.String,
+		Sale:        int(r.sale.Int32),
+		Size:        r.size.String,
+		TotalPrice:  int(r.totalPrice.Int32),
+		NmID:        int(r.nmID.Int64),
+		Brand:       r.brand.String,
+		Status:      int(r.status.Int32),
+	}
+}
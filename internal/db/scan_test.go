@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestOrderRowWithoutJoinedRows(t *testing.T) {
+	var row orderRow
+	row.order.OrderUID = "b563feb7b2b84b6test"
+	row.order.DateCreated.Time = time.Date(2021, 11, 26, 9, 22, 19, 1500, time.FixedZone("MSK", 3*60*60))
+	row.deliveryCity = pgtype.Text{String: "Kiryat Mozkin", Valid: true}
+
+	order := row.toOrder()
+
+	if order.Payment != (model.Payment{}) {
+		t.Errorf("payment = %+v, want zero value", order.Payment)
+	}
+	if order.Delivery != (model.Delivery{City: "Kiryat Mozkin"}) {
+		t.Errorf("delivery = %+v, want only city", order.Delivery)
+	}
+	want := time.Date(2021, 11, 26, 6, 22, 19, 1000, time.UTC)
+	if !order.DateCreated.Equal(want) || order.DateCreated.Location() != time.UTC {
+		t.Errorf("date_created = %v, want %v", order.DateCreated.Time, want)
+	}
+}
+
+func TestGetOrderWithoutPayment(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("nopayment1")
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+	exec(t, db, `DELETE FROM payment WHERE order_uid = $1`, order.OrderUID)
+
+	got, err := db.GetOrderByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetOrderByUID: %v", err)
+	}
+	if got.Payment != (model.Payment{}) {
+		t.Errorf("payment = %+v, want zero value", got.Payment)
+	}
+	if got.Delivery != order.Delivery {
+		t.Errorf("delivery = %+v, want %+v", got.Delivery, order.Delivery)
+	}
+
+	all, err := db.GetAllOrders(ctx)
+	if err != nil {
+		t.Fatalf("GetAllOrders: %v", err)
+	}
+	if len(all) != 1 || all[0].OrderUID != order.OrderUID || all[0].Payment != (model.Payment{}) {
+		t.Errorf("GetAllOrders = %+v, want the order with zero payment", all)
+	}
+}
+
+func TestItemRowNullFields(t *testing.T) {
+	row := itemRow{
+		chrtID: pgtype.Int8{Int64: 9934930, Valid: true},
+		price:  pgtype.Int4{Int32: 453, Valid: true},
+		name:   pgtype.Text{String: "Mascaras", Valid: true},
+	}
+
+	item := row.toItem("b563feb7b2b84b6test")
+
+	want := model.Item{ChrtID: 9934930, Price: 453, Name: "Mascaras"}
+	if item != want {
+		t.Errorf("item = %+v, want %+v", item, want)
+	}
+}
+
+func TestGetOrderWithNullItemBrand(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("nullbrand1")
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+	exec(t, db, `UPDATE items SET brand = NULL, track_number = NULL WHERE order_uid = $1`, order.OrderUID)
+
+	got, err := db.GetOrderByUID(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("GetOrderByUID: %v", err)
+	}
+	if len(got.Items) != 1 {
+		t.Fatalf("items = %d, want 1", len(got.Items))
+	}
+	want := order.Items[0]
+	want.Brand = ""
+	want.TrackNumber = ""
+	if got.Items[0] != want {
+		t.Errorf("item = %+v, want %+v", got.Items[0], want)
+	}
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// Заказы удаляются мягко: SoftDeleteOrder заполняет orders.deleted_at, а все
+// запросы чтения по умолчанию пропускают такие заказы. Данные остаются в БД,
+// поэтому удаление обратимо (UndeleteOrder). Контекст, полученный из
+// WithDeleted, включает удаленные заказы в результаты чтения.
+
+type includeDeletedKey struct{}
+
+// WithDeleted возвращает контекст, в котором чтение заказов возвращает и мягко удаленные
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+// includeDeleted сообщает, нужно ли возвращать мягко удаленные заказы
+func includeDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return include
+}
+
+// notDeleted условие отбора заказов (таблица orders с псевдонимом o) для запросов чтения
+func notDeleted(ctx context.Context) string {
+	if includeDeleted(ctx) {
+		return "TRUE"
+	}
+	return "o.deleted_at IS NULL"
+}
+
+// SoftDeleteOrder помечает заказ удаленным. Если заказа нет или он уже удален,
+// возвращается ErrOrderNotFound.
+func (db *Database) SoftDeleteOrder(ctx context.Context, uid string) error {
+	tag, err := db.pool.Exec(ctx, `UPDATE orders SET deleted_at = now() WHERE order_uid = $1 AND deleted_at IS NULL`, uid)
+	if err != nil {
+		return fmt.Errorf("soft delete order error: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOrderNotFound
+	}
+	return nil
+}
+
+// UndeleteOrder снимает пометку удаления с заказа. Если заказа нет или он
+// не удален, возвращается ErrOrderNotFound.
+func (db *Database) UndeleteOrder(ctx context.Context, uid string) error {
+	tag, err := db.pool.Exec(ctx, `UPDATE orders SET deleted_at = NULL WHERE order_uid = $1 AND deleted_at IS NOT NULL`, uid)
+	if err != nil {
+		return fmt.Errorf("undelete order error: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOrderNotFound
+	}
+	return nil
+}
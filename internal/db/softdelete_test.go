@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestSoftDeleteHidesOrder(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	orders := insertOrdersAt(t, db, "deleted", 2, 0)
+	uid := orders[0].OrderUID
+
+	if err := db.SoftDeleteOrder(ctx, uid); err != nil {
+		t.Fatalf("SoftDeleteOrder: %v", err)
+	}
+	if err := db.SoftDeleteOrder(ctx, uid); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("second SoftDeleteOrder error = %v, want ErrOrderNotFound", err)
+	}
+
+	if _, err := db.GetOrderByUID(ctx, uid); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("GetOrderByUID error = %v, want ErrOrderNotFound", err)
+	}
+	all, err := db.GetAllOrders(ctx)
+	if err != nil {
+		t.Fatalf("GetAllOrders: %v", err)
+	}
+	if got := orderUIDs(all); len(got) != 1 || got[0] != orders[1].OrderUID {
+		t.Errorf("GetAllOrders = %v, want only %s", got, orders[1].OrderUID)
+	}
+
+	got, err := db.GetOrderByUID(WithDeleted(ctx), uid)
+	if err != nil {
+		t.Fatalf("GetOrderByUID with deleted: %v", err)
+	}
+	if got.OrderUID != uid || len(got.Items) != 1 {
+		t.Errorf("deleted order = %s with %d items, want %s with 1 item", got.OrderUID, len(got.Items), uid)
+	}
+	if all, err := db.GetAllOrders(WithDeleted(ctx)); err != nil || len(all) != 2 {
+		t.Errorf("GetAllOrders with deleted = %d orders, %v; want 2", len(all), err)
+	}
+}
+
+func TestUndeleteRestoresOrder(t *testing.T) {
+	db := newTestDB(t, Options{})
+	ctx := context.Background()
+	order := testutil.Order("undelete1")
+	if err := db.InsertOrder(ctx, order); err != nil {
+		t.Fatalf("InsertOrder: %v", err)
+	}
+	if err := db.UndeleteOrder(ctx, order.OrderUID); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("UndeleteOrder of a live order error = %v, want ErrOrderNotFound", err)
+	}
+
+	if err := db.SoftDeleteOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("SoftDeleteOrder: %v", err)
+	}
+	if err := db.UndeleteOrder(ctx, order.OrderUID); err != nil {
+		t.Fatalf("UndeleteOrder: %v", err)
+	}
+	if _, err := db.GetOrderByUID(ctx, order.OrderUID); err != nil {
+		t.Errorf("GetOrderByUID after undelete: %v", err)
+	}
+}
+
+func TestRedeliveredDeletedOrderStaysHidden(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*Database) func(context.Context, *model.Order) error
+	}{
+		{name: "insert", write: func(db *Database) func(context.Context, *model.Order) error { return db.InsertOrder }},
+		{name: "upsert", write: func(db *Database) func(context.Context, *model.Order) error { return db.UpsertOrder }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, Options{})
+			ctx := context.Background()
+			order := testutil.Order("redelivered1")
+			write := tt.write(db)
+			if err := write(ctx, order); err != nil {
+				t.Fatalf("first write: %v", err)
+			}
+			if err := db.SoftDeleteOrder(ctx, order.OrderUID); err != nil {
+				t.Fatalf("SoftDeleteOrder: %v", err)
+			}
+
+			if err := write(ctx, order); !errors.Is(err, ErrOrderDeleted) {
+				t.Errorf("redelivery error = %v, want ErrOrderDeleted", err)
+			}
+			if _, err := db.GetOrderByUID(ctx, order.OrderUID); !errors.Is(err, ErrOrderNotFound) {
+				t.Errorf("GetOrderByUID after redelivery error = %v, want ErrOrderNotFound", err)
+			}
+		})
+	}
+}
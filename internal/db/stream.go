@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// streamPageSize число заказов, читаемых из БД за один запрос StreamOrders
+const streamPageSize = 500
+
+// StreamOrders передает все заказы в fn по одному в порядке order_uid, не
+// загружая их в память целиком: заказы читаются страницами по streamPageSize.
+// Ошибка fn прерывает перебор и возвращается вызывающему.
+func (db *Database) StreamOrders(ctx context.Context, fn func(*model.Order) error) error {
+	var after string
+	for {
+		query := orderSelectQuery + `
+		WHERE o.order_uid > $1 AND ` + notDeleted(ctx) + `
+		ORDER BY o.order_uid
+		LIMIT $2`
+
+		orders, err := db.queryOrders(ctx, query, after, streamPageSize)
+		if err != nil {
+			return fmt.Errorf("stream orders error: %w", err)
+		}
+
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+
+		if len(orders) < streamPageSize {
+			return nil
+		}
+		after = orders[len(orders)-1].OrderUID
+	}
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Интеграционные тесты пакета работают с настоящим PostgreSQL, адрес которого
+// задает TEST_DATABASE_URL; без него тесты пропускаются. Каждый тест получает
+// свою схему с примененными миграциями, которая удаляется по его завершении.
+
+// newTestDB возвращает Database, работающую в отдельной схеме тестовой БД
+func newTestDB(tb testing.TB, opts Options) *Database {
+	tb.Helper()
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, connString)
+	if err != nil {
+		tb.Fatalf("connect to test database: %v", err)
+	}
+	defer admin.Close(ctx)
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, `CREATE SCHEMA `+schema); err != nil {
+		tb.Fatalf("create schema: %v", err)
+	}
+	tb.Cleanup(func() {
+		conn, err := pgx.Connect(context.Background(), connString)
+		if err != nil {
+			tb.Errorf("connect to drop schema: %v", err)
+			return
+		}
+		defer conn.Close(context.Background())
+		if _, err := conn.Exec(context.Background(), `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			tb.Errorf("drop schema: %v", err)
+		}
+	})
+
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		tb.Fatalf("parse test database url: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	if opts.LogQueries {
+		config.ConnConfig.Tracer = queryLogger{}
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		tb.Fatalf("create pool: %v", err)
+	}
+	tb.Cleanup(pool.Close)
+
+	migrations, err := filepath.Glob(filepath.Join("..", "..", "migrations", "*.up.sql"))
+	if err != nil || len(migrations) == 0 {
+		tb.Fatalf("find migrations: %v", err)
+	}
+	sort.Strings(migrations)
+	for _, path := range migrations {
+		sql, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("read migration %s: %v", path, err)
+		}
+		if _, err := pool.Exec(ctx, string(sql)); err != nil {
+			tb.Fatalf("apply migration %s: %v", path, err)
+		}
+	}
+
+	return &Database{pool: pool, aggregateItems: opts.AggregateItems, skipBadItems: opts.SkipBadItems}
+}
+
+// exec выполняет служебный запрос тестовой БД
+func exec(tb testing.TB, db *Database, sql string, args ...any) {
+	tb.Helper()
+	if _, err := db.pool.Exec(context.Background(), sql, args...); err != nil {
+		tb.Fatalf("exec %q: %v", sql, err)
+	}
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// queryLogger реализует pgx.QueryTracer и пишет каждый SQL-запрос
+// и его длительность в лог на уровне debug
+type queryLogger struct{}
+
+type queryStartKey struct{}
+
+// queryStart данные начала запроса, передаваемые через контекст
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+func (queryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, start: time.Now()})
+}
+
+func (queryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("sql", strings.Join(strings.Fields(start.sql), " ")),
+		zap.Duration("duration", time.Since(start.start)),
+		zap.Int64("rows", data.CommandTag.RowsAffected()),
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	}
+	logger.Debug("SQL query", fields...)
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-kafka-postgres/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogs перенаправляет логгер в память до конца теста
+func observeLogs(tb testing.TB, level zapcore.Level) *observer.ObservedLogs {
+	tb.Helper()
+	core, logs := observer.New(level)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	tb.Cleanup(func() { logger.Logger = previous })
+	return logs
+}
+
+func TestQueryLoggerLogsQuery(t *testing.T) {
+	logs := observeLogs(t, zapcore.DebugLevel)
+	tracer := queryLogger{}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT count(*)\n\t\tFROM orders"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	entries := logs.FilterMessage("SQL query").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d queries, want 1", len(entries))
+	}
+	if entries[0].Level != zapcore.DebugLevel {
+		t.Errorf("level = %v, want debug", entries[0].Level)
+	}
+	fields := entries[0].ContextMap()
+	if fields["sql"] != "SELECT count(*) FROM orders" || fields["rows"] != int64(1) {
+		t.Errorf("fields = %v, want the normalized SQL and 1 row", fields)
+	}
+	if _, ok := fields["duration"]; !ok {
+		t.Error("query log has no duration")
+	}
+}
+
+func TestQueryLoggerLogsError(t *testing.T) {
+	logs := observeLogs(t, zapcore.DebugLevel)
+	tracer := queryLogger{}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	if fields := logs.All()[0].ContextMap(); fields["error"] != "connection reset" {
+		t.Errorf("fields = %v, want the query error", fields)
+	}
+}
+
+func TestQueryLoggerWithDatabase(t *testing.T) {
+	db := newTestDB(t, Options{LogQueries: true})
+	logs := observeLogs(t, zapcore.DebugLevel)
+
+	if _, err := db.CountOrders(context.Background()); err != nil {
+		t.Fatalf("CountOrders: %v", err)
+	}
+	if n := logs.FilterMessage("SQL query").Len(); n != 1 {
+		t.Errorf("logged %d queries for CountOrders, want 1", n)
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/validator"
+
+	"go.uber.org/zap"
+)
+
+// adminTokenHeader заголовок с общим секретом для административных эндпоинтов
+const adminTokenHeader = "X-Admin-Token"
+
+// ConsumerController управление потреблением сообщений
+type ConsumerController interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// consumerStateResponse содержимое ответа /admin/consumer/...
+type consumerStateResponse struct {
+	Paused bool `json:"paused"`
+}
+
+// restoreResponse содержимое ответа /admin/cache/restore
+type restoreResponse struct {
+	Size int `json:"size"`
+}
+
+// authorizeAdmin проверяет доступ к административному эндпоинту и при отказе
+// сам пишет ответ. Эндпоинты доступны только при DebugEndpoints, а если задан
+// AdminToken — только с совпадающим заголовком X-Admin-Token.
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if !h.opts.DebugEndpoints {
+		http.NotFound(w, r)
+		return false
+	}
+	if h.opts.AdminToken != "" &&
+		subtle.ConstantTimeCompare([]byte(r.Header.Get(adminTokenHeader)), []byte(h.opts.AdminToken)) != 1 {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "Invalid admin token")
+		return false
+	}
+	return true
+}
+
+// RestoreCache заново загружает все заказы из БД в кэш: POST /admin/cache/restore.
+// Одновременные вызовы выполняются по очереди.
+func (h *Handler) RestoreCache(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if h.cache == nil || h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "unavailable", "Cache or database is not configured")
+		return
+	}
+
+	h.restoreMu.Lock()
+	defer h.restoreMu.Unlock()
+
+	orders, err := h.db.GetAllOrders(r.Context())
+	if err != nil {
+		logger.Errorf("Failed to load orders for cache restore: %v", err)
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to load orders")
+		return
+	}
+
+	h.cache.Restore(orders)
+	size := h.cache.Size()
+	logger.Infof("Restored %d orders into cache on admin request", size)
+	h.writeJSON(w, r, http.StatusOK, restoreResponse{Size: size})
+}
+
+// ConsumerControl приостанавливает и возобновляет потребление:
+// POST /admin/consumer/pause, POST /admin/consumer/resume, GET /admin/consumer — текущее состояние
+func (h *Handler) ConsumerControl(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	if h.opts.Consumer == nil {
+		writeError(w, http.StatusServiceUnavailable, "unavailable", "Consumer is not configured")
+		return
+	}
+
+	action := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/admin/consumer"), "/")
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+	case action == "pause" && r.Method == http.MethodPost:
+		h.opts.Consumer.Pause()
+	case action == "resume" && r.Method == http.MethodPost:
+		h.opts.Consumer.Resume()
+	case action == "" || action == "pause" || action == "resume":
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, consumerStateResponse{Paused: h.opts.Consumer.Paused()})
+}
+
+// AdminOrder управление мягким удалением заказов:
+// GET /admin/orders/{uid} — заказ из БД, в том числе удаленный,
+// DELETE /admin/orders/{uid} — мягкое удаление, POST /admin/orders/{uid}/restore — восстановление
+func (h *Handler) AdminOrder(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "unavailable", "Database is not configured")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/admin/orders/")
+	uid, restore := strings.CutSuffix(path, "/restore")
+	if !validator.ValidOrderUID(uid) {
+		writeError(w, http.StatusBadRequest, "invalid_uid", "Invalid order uid")
+		return
+	}
+
+	var (
+		order *model.Order
+		err   error
+	)
+	switch {
+	case restore && r.Method == http.MethodPost:
+		order, err = h.store.Undelete(r.Context(), uid)
+	case !restore && r.Method == http.MethodGet:
+		order, err = h.db.GetOrderByUID(db.WithDeleted(r.Context()), uid)
+	case !restore && r.Method == http.MethodDelete:
+		err = h.store.Delete(r.Context(), uid)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if errors.Is(err, db.ErrOrderNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Admin order request failed", zap.String("order_uid", uid), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to process order")
+		return
+	}
+
+	if order == nil {
+		logger.Info("Order soft-deleted on admin request", zap.String("order_uid", uid))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, order)
+}
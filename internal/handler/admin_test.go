@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// restore вызывает POST /admin/cache/restore с токеном администратора
+func restore(h *Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/restore", nil)
+	if token != "" {
+		req.Header.Set(adminTokenHeader, token)
+	}
+	rec := httptest.NewRecorder()
+	h.RestoreCache(rec, req)
+	return rec
+}
+
+func TestRestoreCache(t *testing.T) {
+	database := newFakeDB(testutil.Order("order1"), testutil.Order("order2"), testutil.Order("order3"))
+	orderCache := cache.New(10)
+	orderCache.Set(testutil.Order("stale"))
+	h := New(orderCache, database, Options{DebugEndpoints: true, AdminToken: "secret"})
+
+	rec := restore(h, "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var body restoreResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Size != 3 {
+		t.Errorf("size = %d, want 3", body.Size)
+	}
+	keys := orderCache.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"order1", "order2", "order3"}) {
+		t.Errorf("cache keys = %v, want exactly the orders from the DB", keys)
+	}
+}
+
+func TestRestoreCacheConcurrent(t *testing.T) {
+	database := newFakeDB(testutil.Order("order1"), testutil.Order("order2"))
+	h := New(cache.New(10), database, Options{DebugEndpoints: true})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := restore(h, ""); rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+	if size := h.cache.Size(); size != 2 {
+		t.Errorf("cache size after concurrent restores = %d, want 2", size)
+	}
+}
+
+func TestRestoreCacheErrors(t *testing.T) {
+	failing := newFakeDB()
+	failing.err = errors.New("connection refused")
+	tests := []struct {
+		name       string
+		h          *Handler
+		method     string
+		token      string
+		wantStatus int
+	}{
+		{name: "debug endpoints disabled", h: New(cache.New(10), newFakeDB(), Options{}), wantStatus: http.StatusNotFound},
+		{name: "wrong token", h: New(cache.New(10), newFakeDB(), Options{DebugEndpoints: true, AdminToken: "secret"}),
+			token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "GET", h: New(cache.New(10), newFakeDB(), Options{DebugEndpoints: true}), method: http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed},
+		{name: "no database", h: New(cache.New(10), nil, Options{DebugEndpoints: true}), wantStatus: http.StatusServiceUnavailable},
+		{name: "DB error", h: New(cache.New(10), failing, Options{DebugEndpoints: true}), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/admin/cache/restore", nil)
+			if tt.token != "" {
+				req.Header.Set(adminTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			tt.h.RestoreCache(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// fakeController ConsumerController, запоминающий состояние
+type fakeController struct {
+	paused bool
+}
+
+func (c *fakeController) Pause()       { c.paused = true }
+func (c *fakeController) Resume()      { c.paused = false }
+func (c *fakeController) Paused() bool { return c.paused }
+
+func TestConsumerControl(t *testing.T) {
+	controller := &fakeController{}
+	h := New(nil, nil, Options{DebugEndpoints: true, Consumer: controller})
+
+	steps := []struct {
+		method, path string
+		wantStatus   int
+		wantPaused   bool
+	}{
+		{method: http.MethodGet, path: "/admin/consumer", wantStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/admin/consumer/pause", wantStatus: http.StatusOK, wantPaused: true},
+		{method: http.MethodGet, path: "/admin/consumer", wantStatus: http.StatusOK, wantPaused: true},
+		{method: http.MethodGet, path: "/admin/consumer/resume", wantStatus: http.StatusMethodNotAllowed, wantPaused: true},
+		{method: http.MethodPost, path: "/admin/consumer/resume", wantStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/admin/consumer/restart", wantStatus: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rec := httptest.NewRecorder()
+		h.ConsumerControl(rec, httptest.NewRequest(step.method, step.path, nil))
+		if rec.Code != step.wantStatus {
+			t.Errorf("%s %s: status = %d, want %d", step.method, step.path, rec.Code, step.wantStatus)
+			continue
+		}
+		if controller.paused != step.wantPaused {
+			t.Errorf("%s %s: paused = %v, want %v", step.method, step.path, controller.paused, step.wantPaused)
+		}
+		if rec.Code == http.StatusOK {
+			var body consumerStateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Paused != step.wantPaused {
+				t.Errorf("%s %s: body %s, want paused %v", step.method, step.path, rec.Body.String(), step.wantPaused)
+			}
+		}
+	}
+}
+
+// adminOrder вызывает /admin/orders/{path} методом method с токеном администратора
+func adminOrder(h *Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/admin/orders/"+path, nil)
+	req.Header.Set(adminTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	h.AdminOrder(rec, req)
+	return rec
+}
+
+// getOrderStatus возвращает код ответа GET /order/{uid}
+func getOrderStatus(h *Handler, uid string) int {
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+uid, nil))
+	return rec.Code
+}
+
+func TestAdminOrderSoftDelete(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	h := New(cache.New(10), newFakeDB(order), Options{DebugEndpoints: true, AdminToken: "secret"})
+	if got := getOrderStatus(h, order.OrderUID); got != http.StatusOK {
+		t.Fatalf("GET before delete = %d, want 200", got)
+	}
+
+	if rec := adminOrder(h, http.MethodDelete, order.OrderUID); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d, want 204: %s", rec.Code, rec.Body)
+	}
+	if got := getOrderStatus(h, order.OrderUID); got != http.StatusNotFound {
+		t.Errorf("GET after delete = %d, want 404: the cached copy must be evicted", got)
+	}
+	if rec := adminOrder(h, http.MethodDelete, order.OrderUID); rec.Code != http.StatusNotFound {
+		t.Errorf("second DELETE status = %d, want 404", rec.Code)
+	}
+
+	rec := adminOrder(h, http.MethodPost, order.OrderUID+"/restore")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := getOrderStatus(h, order.OrderUID); got != http.StatusOK {
+		t.Errorf("GET after restore = %d, want 200", got)
+	}
+}
+
+func TestAdminOrderRequiresAdmin(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	h := New(nil, newFakeDB(order), Options{DebugEndpoints: true, AdminToken: "secret"})
+
+	rec := httptest.NewRecorder()
+	h.AdminOrder(rec, httptest.NewRequest(http.MethodDelete, "/admin/orders/"+order.OrderUID, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+	if got := getOrderStatus(h, order.OrderUID); got != http.StatusOK {
+		t.Errorf("GET after unauthorized delete = %d, want 200", got)
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/validator"
+)
+
+// cacheDebugResponse содержимое ответа /debug/cache
+type cacheDebugResponse struct {
+	cache.Stats
+	Keys []string `json:"keys"`
+}
+
+// DebugCache возвращает статистику кэша и список UID в порядке LRU
+func (h *Handler) DebugCache(w http.ResponseWriter, r *http.Request) {
+	if !h.opts.DebugEndpoints || h.cache == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	resp := cacheDebugResponse{
+		Stats: h.cache.Stats(),
+		Keys:  h.cache.Keys(),
+	}
+
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// cacheEntryResponse содержимое ответа /debug/cache/{uid}
+type cacheEntryResponse struct {
+	OrderUID       string     `json:"order_uid"`
+	InsertedAt     time.Time  `json:"inserted_at"`
+	Age            string     `json:"age"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	AccessCount    uint64     `json:"access_count"`
+}
+
+// DebugCacheEntry возвращает сведения об использовании закэшированного заказа:
+// когда он попал в кэш, когда и сколько раз был запрошен. Запрос не считается
+// обращением к заказу и не меняет его позицию в LRU.
+func (h *Handler) DebugCacheEntry(w http.ResponseWriter, r *http.Request) {
+	entries, ok := h.cache.(cache.EntryGetter)
+	if !h.opts.DebugEndpoints || !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	uid := strings.TrimPrefix(r.URL.Path, "/debug/cache/")
+	if !validator.ValidOrderUID(uid) {
+		writeError(w, http.StatusBadRequest, "invalid_uid", "Invalid order uid")
+		return
+	}
+
+	entry, ok := entries.GetEntry(uid)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_cached", "Order is not in cache")
+		return
+	}
+
+	resp := cacheEntryResponse{
+		OrderUID:    uid,
+		InsertedAt:  entry.InsertedAt,
+		Age:         time.Since(entry.InsertedAt).Round(time.Millisecond).String(),
+		AccessCount: entry.AccessCount,
+	}
+	if !entry.LastAccessedAt.IsZero() {
+		resp.LastAccessedAt = &entry.LastAccessedAt
+	}
+	h.writeJSON(w, r, http.StatusOK, resp)
+}
+
+// RefreshOrder перечитывает заказ из БД и перезаписывает его в кэше:
+// POST /order/{uid}/refresh. Если заказа нет в БД, он удаляется из кэша.
+func (h *Handler) RefreshOrder(w http.ResponseWriter, r *http.Request, uid string) {
+	if !h.opts.DebugEndpoints {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if !validator.ValidOrderUID(uid) {
+		writeError(w, http.StatusBadRequest, "invalid_uid", "Invalid order uid")
+		return
+	}
+
+	order, err := h.store.Refresh(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, db.ErrOrderNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "Order not found")
+			return
+		}
+		logger.Errorf("Failed to refresh order %s: %v", uid, err)
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to refresh order")
+		return
+	}
+
+	logger.Infof("Order %s refreshed from database", uid)
+	h.writeJSON(w, r, http.StatusOK, order)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestRefreshOrder(t *testing.T) {
+	stale := testutil.Order("b563feb7b2b84b6test")
+	fresh := testutil.Order(stale.OrderUID)
+	fresh.Delivery.City = "Moscow"
+	orderCache := cache.New(10)
+	orderCache.Set(stale)
+	h := New(orderCache, newFakeDB(fresh), Options{DebugEndpoints: true})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodPost, "/order/"+stale.OrderUID+"/refresh", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var got model.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if got.Delivery.City != "Moscow" {
+		t.Errorf("response city = %q, want the fresh DB value", got.Delivery.City)
+	}
+	if cached, _ := orderCache.Peek(stale.OrderUID); cached != fresh {
+		t.Error("cache entry was not overwritten with the fresh order")
+	}
+}
+
+func TestRefreshMissingOrderEvictsCache(t *testing.T) {
+	stale := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(stale)
+	h := New(orderCache, newFakeDB(), Options{DebugEndpoints: true})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodPost, "/order/"+stale.OrderUID+"/refresh", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	decodeError(t, rec)
+	if _, ok := orderCache.Peek(stale.OrderUID); ok {
+		t.Error("stale cache entry survived a refresh of a missing order")
+	}
+}
+
+func TestRefreshOrderGated(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+
+	rec := httptest.NewRecorder()
+	New(nil, database, Options{}).GetOrder(rec, httptest.NewRequest(http.MethodPost, "/order/"+order.OrderUID+"/refresh", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("with debug endpoints off: status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	New(nil, database, Options{DebugEndpoints: true}).GetOrder(rec,
+		httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID+"/refresh", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET refresh: status = %d, want 405", rec.Code)
+	}
+
+	if reads := database.Reads(); reads != 0 {
+		t.Errorf("DB read %d times for rejected refreshes, want 0", reads)
+	}
+}
+
+// getCacheEntry запрашивает /debug/cache/{uid}
+func getCacheEntry(h *Handler, uid string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.DebugCacheEntry(rec, httptest.NewRequest(http.MethodGet, "/debug/cache/"+uid, nil))
+	return rec
+}
+
+func TestDebugCacheEntry(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(order)
+	h := New(orderCache, newFakeDB(), Options{DebugEndpoints: true})
+	orderCache.Get(order.OrderUID)
+	orderCache.Get(order.OrderUID)
+
+	rec := getCacheEntry(h, order.OrderUID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var got cacheEntryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	if got.OrderUID != order.OrderUID || got.AccessCount != 2 || got.LastAccessedAt == nil || got.InsertedAt.IsZero() {
+		t.Errorf("entry = %+v, want 2 accesses with timestamps", got)
+	}
+
+	// Запрос к отладочному эндпоинту сам обращением не считается
+	rec = getCacheEntry(h, order.OrderUID)
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode entry: %v", err)
+	}
+	if got.AccessCount != 2 {
+		t.Errorf("AccessCount after a debug request = %d, want 2", got.AccessCount)
+	}
+}
+
+func TestDebugCacheEntryErrors(t *testing.T) {
+	orderCache := cache.New(10)
+	orderCache.Set(testutil.Order("b563feb7b2b84b6test"))
+
+	tests := []struct {
+		name       string
+		h          *Handler
+		uid        string
+		wantStatus int
+	}{
+		{name: "not cached", h: New(orderCache, newFakeDB(), Options{DebugEndpoints: true}), uid: "missing", wantStatus: http.StatusNotFound},
+		{name: "invalid uid", h: New(orderCache, newFakeDB(), Options{DebugEndpoints: true}), uid: "a-b", wantStatus: http.StatusBadRequest},
+		{name: "debug endpoints off", h: New(orderCache, newFakeDB(), Options{}), uid: "b563feb7b2b84b6test", wantStatus: http.StatusNotFound},
+		{name: "cache without entries", h: New(cache.NewNoop(), newFakeDB(), Options{DebugEndpoints: true}), uid: "b563feb7b2b84b6test", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := getCacheEntry(tt.h, tt.uid); rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/validator"
+
+	"go.uber.org/zap"
+)
+
+// GetDelivery возвращает только данные доставки заказа: GET /order/{uid}/delivery.
+// Закэшированный заказ отдается из кэша, иначе из БД читается одна таблица delivery.
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request, uid string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if !validator.ValidOrderUID(uid) {
+		writeError(w, http.StatusBadRequest, "invalid_uid", "Invalid order uid")
+		return
+	}
+
+	// Peek не меняет порядок LRU: кэш не должен заполняться заказами только ради доставки
+	if h.cache != nil {
+		if order, ok := h.cache.Peek(uid); ok {
+			h.writeJSON(w, r, http.StatusOK, order.Delivery)
+			return
+		}
+	}
+
+	if h.db == nil {
+		writeError(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+
+	delivery, err := h.db.GetDeliveryByUID(r.Context(), uid)
+	if errors.Is(err, db.ErrOrderNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get delivery from DB", zap.String("order_uid", uid), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to get delivery")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, delivery)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// getDelivery выполняет GET /order/{uid}/delivery
+func getDelivery(h *Handler, uid string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+uid+"/delivery", nil))
+	return rec
+}
+
+func TestGetDeliveryFromDB(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+	h := New(nil, database, Options{})
+
+	rec := getDelivery(h, order.OrderUID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var got model.Delivery
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode delivery: %v", err)
+	}
+	if got != order.Delivery {
+		t.Errorf("delivery = %+v, want %+v", got, order.Delivery)
+	}
+	// Тело содержит только доставку, без оплаты и товаров
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"payment", "items", "order_uid"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("delivery response has field %q", key)
+		}
+	}
+	if database.Reads() != 0 {
+		t.Errorf("full order was read %d times, want only the delivery query", database.Reads())
+	}
+}
+
+func TestGetDeliveryFromCache(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(order)
+	database := newFakeDB()
+	h := New(orderCache, database, Options{})
+
+	rec := getDelivery(h, order.OrderUID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var got model.Delivery
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode delivery: %v", err)
+	}
+	if got != order.Delivery {
+		t.Errorf("delivery = %+v, want %+v", got, order.Delivery)
+	}
+	if database.deliveryReads != 0 {
+		t.Errorf("delivery was read from the DB %d times, want the cached order", database.deliveryReads)
+	}
+}
+
+func TestGetDeliveryErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		uid        string
+		method     string
+		deleted    bool
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", uid: "missing", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "soft deleted", uid: "b563feb7b2b84b6test", deleted: true, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "invalid uid", uid: "bad%20uid", wantStatus: http.StatusBadRequest, wantCode: "invalid_uid"},
+		{name: "wrong method", uid: "b563feb7b2b84b6test", method: http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed"},
+		{name: "database error", uid: "b563feb7b2b84b6test", err: errors.New("connection reset"),
+			wantStatus: http.StatusInternalServerError, wantCode: "db_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := newFakeDB(testutil.Order("b563feb7b2b84b6test"))
+			database.err = tt.err
+			database.deleted["b563feb7b2b84b6test"] = tt.deleted
+			h := New(nil, database, Options{})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			h.GetOrder(rec, httptest.NewRequest(method, "/order/"+tt.uid+"/delivery", nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if body := decodeError(t, rec); body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
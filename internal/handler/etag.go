@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// orderETag вычисляет слабый ETag по содержимому заказа и варианту
+// представления (JSON, расширенный JSON, текст): разные представления одного
+// заказа по одному адресу получают разные ETag. Отступы pretty=true на ETag не
+// влияют, поэтому он слабый. Пустая строка — ETag вычислить не удалось.
+func orderETag(order *model.Order, variant string) string {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.New()
+	hash.Write([]byte(variant))
+	hash.Write([]byte{0})
+	hash.Write(data)
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// notModified выставляет ETag и Cache-Control и, если клиент прислал совпадающий
+// If-None-Match, отвечает 304 без тела. Возвращает true, если ответ уже записан.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	// Кэшировать можно, но перед использованием нужно перепроверить по ETag
+	w.Header().Set("Cache-Control", "no-cache")
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches сравнивает список If-None-Match с ETag по слабому сравнению
+// (RFC 9110, 13.1.2): префикс W/ не учитывается
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
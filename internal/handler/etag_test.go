@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+)
+
+// getOrderIfNoneMatch запрашивает заказ с заголовками Accept и If-None-Match
+func getOrderIfNoneMatch(h *Handler, target, accept, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, req)
+	return rec
+}
+
+func TestGetOrderNotModified(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{name: "json", target: "/order/b563feb7b2b84b6test"},
+		{name: "full view", target: "/order/b563feb7b2b84b6test?view=full"},
+		{name: "text", target: "/order/b563feb7b2b84b6test", accept: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(nil, newFakeDB(testutil.Order("b563feb7b2b84b6test")), Options{})
+
+			first := getOrderIfNoneMatch(h, tt.target, tt.accept, "")
+			if first.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", first.Code)
+			}
+			etag := first.Header().Get("ETag")
+			if etag == "" {
+				t.Fatal("ETag header is missing")
+			}
+			if got := first.Header().Get("Cache-Control"); got != "no-cache" {
+				t.Errorf("Cache-Control = %q, want no-cache", got)
+			}
+
+			rec := getOrderIfNoneMatch(h, tt.target, tt.accept, etag)
+			if rec.Code != http.StatusNotModified {
+				t.Fatalf("status with If-None-Match = %d, want 304", rec.Code)
+			}
+			if rec.Body.Len() != 0 {
+				t.Errorf("304 body = %q, want empty", rec.Body.String())
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("304 ETag = %q, want %q", got, etag)
+			}
+		})
+	}
+}
+
+func TestGetOrderETagChanges(t *testing.T) {
+	database := newFakeDB(testutil.Order("b563feb7b2b84b6test"))
+	h := New(nil, database, Options{})
+	const target = "/order/b563feb7b2b84b6test"
+
+	etag := getOrderIfNoneMatch(h, target, "", "").Header().Get("ETag")
+
+	if other := getOrderIfNoneMatch(h, target+"?view=full", "", "").Header().Get("ETag"); other == etag {
+		t.Errorf("full view has the same ETag %q as the plain JSON", etag)
+	}
+	if other := getOrderIfNoneMatch(h, target, "text/plain", "").Header().Get("ETag"); other == etag {
+		t.Errorf("text representation has the same ETag %q as JSON", etag)
+	}
+	// ETag JSON-представления не подходит для текстового, иначе клиент получит 304 на чужой вариант
+	if rec := getOrderIfNoneMatch(h, target, "text/plain", etag); rec.Code != http.StatusOK {
+		t.Errorf("text with JSON ETag: status = %d, want 200", rec.Code)
+	}
+
+	changed := testutil.Order("b563feb7b2b84b6test")
+	changed.Delivery.City = "Haifa"
+	database.Add(changed)
+
+	rec := getOrderIfNoneMatch(h, target, "", etag)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status after the order changed = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("ETag"); got == etag {
+		t.Errorf("ETag = %q did not change with the order", got)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	const etag = `W/"abc"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: `W/"abc"`, want: true},
+		{header: `"abc"`, want: true},
+		{header: `"other", W/"abc"`, want: true},
+		{header: `"other"`, want: false},
+		{header: `"abcd"`, want: false},
+		{header: "*", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, etag, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/model"
+)
+
+// fakeDB хранит заказы в памяти и считает обращения к ним. Методы
+// DatabaseInterface, которые не нужны тестам, не реализованы.
+type fakeDB struct {
+	db.DatabaseInterface
+
+	mu     sync.Mutex
+	orders map[string]*model.Order
+	// reads число запросов заказа по UID
+	reads int
+	// deliveryReads число запросов доставки по UID
+	deliveryReads int
+	// err возвращается всеми запросами чтения, если задана
+	err error
+	// from и to параметры последнего запроса по диапазону дат
+	from, to time.Time
+	// uids параметр последнего запроса по списку UID
+	uids []string
+	// writes число записей заказов
+	writes int
+	// deleted мягко удаленные заказы, которые чтение по UID не возвращает
+	deleted map[string]bool
+	// streamHang после первого заказа выгрузка ждет отмены контекста, как зависший запрос к БД
+	streamHang bool
+}
+
+func newFakeDB(orders ...*model.Order) *fakeDB {
+	f := &fakeDB{orders: make(map[string]*model.Order), deleted: make(map[string]bool)}
+	for _, order := range orders {
+		f.orders[order.OrderUID] = order
+	}
+	return f
+}
+
+func (f *fakeDB) GetOrderByUID(_ context.Context, uid string) (*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	order, ok := f.orders[uid]
+	if !ok || f.deleted[uid] {
+		return nil, db.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (f *fakeDB) SoftDeleteOrder(_ context.Context, uid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.orders[uid]; !ok || f.deleted[uid] {
+		return db.ErrOrderNotFound
+	}
+	f.deleted[uid] = true
+	return nil
+}
+
+func (f *fakeDB) UndeleteOrder(_ context.Context, uid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.deleted[uid] {
+		return db.ErrOrderNotFound
+	}
+	delete(f.deleted, uid)
+	return nil
+}
+
+// GetDeliveryByUID возвращает доставку заказа; deliveryReads считает обращения
+func (f *fakeDB) GetDeliveryByUID(_ context.Context, uid string) (*model.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveryReads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	order, ok := f.orders[uid]
+	if !ok || f.deleted[uid] {
+		return nil, db.ErrOrderNotFound
+	}
+	delivery := order.Delivery
+	return &delivery, nil
+}
+
+// GetOrderByTrackNumber возвращает самый новый заказ с трек-номером trackNumber
+func (f *fakeDB) GetOrderByTrackNumber(_ context.Context, trackNumber string) (*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var latest *model.Order
+	for _, order := range f.orders {
+		if order.TrackNumber == trackNumber && (latest == nil || orderLess(latest, order)) {
+			latest = order
+		}
+	}
+	if latest == nil {
+		return nil, db.ErrOrderNotFound
+	}
+	return latest, nil
+}
+
+func (f *fakeDB) GetOrdersByDateRange(_ context.Context, from, to time.Time) ([]*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.from, f.to = from, to
+	if f.err != nil {
+		return nil, f.err
+	}
+	var orders []*model.Order
+	for _, order := range f.orders {
+		if !order.DateCreated.Before(from) && !order.DateCreated.After(to) {
+			orders = append(orders, order)
+		}
+	}
+	sortOrders(orders)
+	return orders, nil
+}
+
+func (f *fakeDB) GetOrdersByUIDs(_ context.Context, uids []string) (map[string]*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uids = uids
+	if f.err != nil {
+		return nil, f.err
+	}
+	orders := make(map[string]*model.Order)
+	for _, uid := range uids {
+		if order, ok := f.orders[uid]; ok {
+			orders[uid] = order
+		}
+	}
+	return orders, nil
+}
+
+func (f *fakeDB) InsertOrder(_ context.Context, order *model.Order) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes++
+	if f.err != nil {
+		return f.err
+	}
+	if _, ok := f.orders[order.OrderUID]; !ok {
+		f.orders[order.OrderUID] = order
+	}
+	return nil
+}
+
+// Writes возвращает число записей заказов
+func (f *fakeDB) Writes() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writes
+}
+
+func (f *fakeDB) GetAllOrders(context.Context) ([]*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var orders []*model.Order
+	for _, order := range f.orders {
+		orders = append(orders, order)
+	}
+	sortOrders(orders)
+	return orders, nil
+}
+
+func (f *fakeDB) CountOrders(context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	return int64(len(f.orders)), nil
+}
+
+func (f *fakeDB) CountOrdersByDateRange(ctx context.Context, from, to time.Time) (int64, error) {
+	orders, err := f.GetOrdersByDateRange(ctx, from, to)
+	return int64(len(orders)), err
+}
+
+func (f *fakeDB) ListOrdersAfter(_ context.Context, cursor *db.Cursor, limit int) ([]*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var orders []*model.Order
+	for _, order := range f.orders {
+		orders = append(orders, order)
+	}
+	sortOrders(orders)
+	if cursor != nil {
+		after := &model.Order{OrderUID: cursor.OrderUID, DateCreated: model.NewTimestamp(cursor.DateCreated)}
+		orders = slices.DeleteFunc(orders, func(order *model.Order) bool { return !orderLess(after, order) })
+	}
+	return orders[:min(limit, len(orders))], nil
+}
+
+// StreamOrders передает заказы в fn в порядке order_uid
+func (f *fakeDB) StreamOrders(ctx context.Context, fn func(*model.Order) error) error {
+	f.mu.Lock()
+	if f.err != nil {
+		f.mu.Unlock()
+		return f.err
+	}
+	var orders []*model.Order
+	for _, order := range f.orders {
+		orders = append(orders, order)
+	}
+	hang := f.streamHang
+	f.mu.Unlock()
+
+	slices.SortFunc(orders, func(a, b *model.Order) int { return strings.Compare(a.OrderUID, b.OrderUID) })
+	for i, order := range orders {
+		if hang && i > 0 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add добавляет заказ, как если бы его записал потребитель
+func (f *fakeDB) Add(order *model.Order) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.orders[order.OrderUID] = order
+}
+
+// Reads возвращает число запросов заказа по UID
+func (f *fakeDB) Reads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reads
+}
+
+// sortOrders упорядочивает заказы по (date_created, order_uid), как выборки БД
+func sortOrders(orders []*model.Order) {
+	sort.Slice(orders, func(i, j int) bool { return orderLess(orders[i], orders[j]) })
+}
+
+// orderLess сравнивает заказы по (date_created, order_uid)
+func orderLess(a, b *model.Order) bool {
+	if !a.DateCreated.Equal(b.DateCreated.Time) {
+		return a.DateCreated.Before(b.DateCreated.Time)
+	}
+	return a.OrderUID < b.OrderUID
+}
+
+// decodeError разбирает JSON-тело ответа с ошибкой
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorResponse {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body %q is not JSON: %v", rec.Body.String(), err)
+	}
+	if body.Error == "" {
+		t.Errorf("error body %q has no error message", rec.Body.String())
+	}
+	return body
+}
@@ -1,58 +1,209 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"go-kafka-postgres/internal/cache"
 	"go-kafka-postgres/internal/db"
 	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/store"
+	"go-kafka-postgres/internal/validator"
+
+	"go.uber.org/zap"
 )
 
+// Options настройки обработчика
+type Options struct {
+	// DebugEndpoints включает отладочные эндпоинты (/debug/...)
+	DebugEndpoints bool
+	// Store общее хранилище заказов; nil означает хранилище по умолчанию поверх cache и db
+	Store *store.OrderStore
+	// Ready сообщает о готовности сервиса для /readyz; nil — всегда готов
+	Ready func() bool
+	// Consumer управление потребителем для /admin/consumer/...; nil отключает эндпоинт
+	Consumer ConsumerController
+	// Validator валидатор заказов, принимаемых через POST /order; nil — проверка по умолчанию
+	Validator *validator.Validator
+	// IdempotencyTTL сколько хранится результат запроса с Idempotency-Key; 0 — 24 часа
+	IdempotencyTTL time.Duration
+	// PrettyJSON выводит все JSON-ответы с отступами
+	PrettyJSON bool
+	// ServeStale при ошибке БД отдает последнюю известную версию заказа
+	// из резервного кэша хранилища с заголовком X-Stale: true
+	ServeStale bool
+	// AdminToken общий секрет для /admin/...; пустое значение не требует заголовка
+	AdminToken string
+	// StreamTimeout ограничивает время одной выгрузки /orders/stream; 0 — 10 минут
+	StreamTimeout time.Duration
+	// StreamMaxOrders максимальное число заказов в одной выгрузке; 0 — без ограничения
+	StreamMaxOrders int
+}
+
 // Handler обрабатывает HTTP запросы
 type Handler struct {
 	cache cache.Cache
 	db    db.DatabaseInterface
+	store *store.OrderStore
+	opts  Options
+
+	validator   *validator.Validator
+	idempotency *idempotencyStore
+
+	restoreMu sync.Mutex
 }
 
-// New создает новый обработчик
-func New(cache cache.Cache, db db.DatabaseInterface) *Handler {
-	return &Handler{cache: cache, db: db}
+const (
+	defaultIdempotencyTTL = 24 * time.Hour
+	maxIdempotencyKeys    = 10000
+	defaultStreamTimeout  = 10 * time.Minute
+)
+
+// New создает новый обработчик. cache или db могут быть nil:
+// без кэша заказы читаются из БД, без БД — только из кэша.
+func New(cache cache.Cache, db db.DatabaseInterface, opts Options) *Handler {
+	orderStore := opts.Store
+	if orderStore == nil {
+		orderStore = store.New(cache, db, store.Options{})
+	}
+	orderValidator := opts.Validator
+	if orderValidator == nil {
+		orderValidator = validator.New(validator.Options{FutureSkew: validator.DefaultFutureSkew})
+	}
+	idempotencyTTL := opts.IdempotencyTTL
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+	if opts.StreamTimeout <= 0 {
+		opts.StreamTimeout = defaultStreamTimeout
+	}
+	return &Handler{
+		cache:       cache,
+		db:          db,
+		store:       orderStore,
+		opts:        opts,
+		validator:   orderValidator,
+		idempotency: newIdempotencyStore(idempotencyTTL, maxIdempotencyKeys),
+	}
 }
 
-// GetOrder обрабатывает запрос на получение заказа
+// GetOrder обрабатывает запрос на получение заказа: GET /order/{uid} или
+// GET /order?uid={uid}, а также вложенные /order/{uid}/refresh и /order/{uid}/delivery
 func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
-	uid := r.URL.Query().Get("uid")
+	uid, action, ok := parseOrderPath(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_path", "Unexpected path segments after order uid")
+		return
+	}
+	switch action {
+	case "":
+	case "refresh":
+		h.RefreshOrder(w, r, uid)
+		return
+	case "delivery":
+		h.GetDelivery(w, r, uid)
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_path", "Unknown order resource: "+action)
+		return
+	}
 
-	if uid == "" {
-		uid = strings.TrimPrefix(r.URL.Path, "/order/")
+	if queryUID := r.URL.Query().Get("uid"); queryUID != "" {
+		if uid != "" && uid != queryUID {
+			writeError(w, http.StatusBadRequest, "conflicting_uid", "Order uid in path and query differ")
+			return
+		}
+		uid = queryUID
 	}
 
 	if uid == "" {
-		http.Error(w, "Missing order uid", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "missing_uid", "Missing order uid")
+		return
+	}
+
+	if !validator.ValidOrderUID(uid) {
+		writeError(w, http.StatusBadRequest, "invalid_uid", "Invalid order uid")
 		return
 	}
 
-	order, found := h.cache.Get(uid)
-	if found {
-		logger.Infof("Order %s получен из кэша", uid)
-	} else {
-		var err error
-		order, err = h.db.GetOrderByUID(uid)
-		if err != nil {
-			logger.Errorf("Failed to get order from DB: %v", err)
-			http.Error(w, "Order not found", http.StatusNotFound)
+	order, err := h.store.Get(r.Context(), uid)
+	if err != nil {
+		stale, ok := h.staleOrder(uid, err)
+		if !ok {
+			logger.Error("Failed to get order from DB", zap.String("order_uid", uid), zap.Error(err))
+			writeError(w, http.StatusNotFound, "not_found", "Order not found")
+			return
+		}
+		logger.Warn("Serving stale order after DB error", zap.String("order_uid", uid), zap.Error(err))
+		w.Header().Set("X-Stale", "true")
+		order = stale
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if wantsText(r) {
+		if notModified(w, r, orderETag(order, "text")) {
 			return
 		}
-		h.cache.Set(order)
-		logger.Infof("Order %s получен из базы данных", uid)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := renderOrderText(w, order); err != nil {
+			logger.Errorf("Error writing response: %v", err)
+		}
+		return
+	}
+
+	var body any = order
+	view := r.URL.Query().Get("view")
+	switch view {
+	case "":
+	case "full":
+		body = model.NewOrderView(order)
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_view", "Unknown view: "+view)
+		return
+	}
+
+	if notModified(w, r, orderETag(order, "json:"+view)) {
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, body)
+}
+
+// parseOrderPath разбирает путь /order[/{uid}[/{action}]] с не более чем одним
+// завершающим слэшем. ok равен false, если сегментов больше или какой-то из них пуст.
+func parseOrderPath(path string) (uid, action string, ok bool) {
+	rest, found := strings.CutPrefix(path, "/order/")
+	if !found {
+		return "", "", path == "/order"
+	}
+	rest = strings.TrimSuffix(rest, "/")
+	if rest == "" {
+		return "", "", true
+	}
+	segments := strings.Split(rest, "/")
+	for _, segment := range segments {
+		if segment == "" {
+			return "", "", false
+		}
 	}
+	switch len(segments) {
+	case 1:
+		return segments[0], "", true
+	case 2:
+		return segments[0], segments[1], true
+	default:
+		return "", "", false
+	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	if err := json.NewEncoder(w).Encode(order); err != nil {
-		logger.Errorf("Error encoding response: %v", err)
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+// staleOrder возвращает последнюю известную версию заказа, если чтение
+// завершилось ошибкой БД (а не отсутствием заказа) и это разрешено ServeStale
+func (h *Handler) staleOrder(uid string, err error) (*model.Order, bool) {
+	if !h.opts.ServeStale || errors.Is(err, db.ErrOrderNotFound) {
+		return nil, false
 	}
+	return h.store.Stale(uid)
 }
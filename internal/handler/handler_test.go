@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestGetOrderErrorBodies(t *testing.T) {
+	h := New(nil, newFakeDB(), Options{})
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", target: "/order/b563feb7b2b84b6test", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "missing uid", target: "/order", wantStatus: http.StatusBadRequest, wantCode: "missing_uid"},
+		{name: "missing uid with slash", target: "/order/", wantStatus: http.StatusBadRequest, wantCode: "missing_uid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetOrder(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if body := decodeError(t, rec); body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestGetOrderRejectsMalformedUID(t *testing.T) {
+	database := newFakeDB()
+	h := New(nil, database, Options{})
+
+	for _, target := range []string{
+		"/order/b563feb7-b2b8",
+		"/order?uid=b563%20feb7",
+		"/order/" + strings.Repeat("a", 65),
+		"/order?uid=%27%3B--",
+	} {
+		t.Run(target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetOrder(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+			if body := decodeError(t, rec); body.Code != "invalid_uid" {
+				t.Errorf("code = %q, want invalid_uid", body.Code)
+			}
+		})
+	}
+
+	if reads := database.Reads(); reads != 0 {
+		t.Errorf("database queried %d times for malformed uids, want 0", reads)
+	}
+}
+
+func TestGetOrderValidUID(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	h := New(nil, newFakeDB(order), Options{})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/b563feb7b2b84b6test", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var got model.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if !reflect.DeepEqual(&got, order) {
+		t.Errorf("order = %+v, want %+v", got, order)
+	}
+}
+
+func TestGetOrderCacheDisabled(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+	h := New(nil, database, Options{})
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+	}
+	if reads := database.Reads(); reads != 2 {
+		t.Errorf("DB read %d times, want 2: without a cache every request goes to the DB", reads)
+	}
+}
+
+func TestGetOrderDBDisabled(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(order)
+	h := New(orderCache, nil, Options{})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("cached order: status = %d, want 200", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("uncached order: status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ListOrders(rec, httptest.NewRequest(http.MethodGet, "/orders?uids=missing", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("list without DB: status = %d, want 503", rec.Code)
+	}
+}
+
+func TestGetOrderWithoutCacheAndDB(t *testing.T) {
+	h := New(nil, nil, Options{})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/b563feb7b2b84b6test", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestGetOrderFullView(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(order)
+	h := New(orderCache, nil, Options{})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID+"?view=full", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode view: %v", err)
+	}
+	if body["order_uid"] != order.OrderUID || body["item_count"] != 1.0 || body["items_total"] != 317.0 ||
+		body["amount_reconciled"] != true {
+		t.Errorf("full view = %v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil))
+	if strings.Contains(rec.Body.String(), "item_count") {
+		t.Error("default response contains computed view fields")
+	}
+
+	rec = httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID+"?view=compact", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown view: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestGetOrderPrettyJSON(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(order)
+
+	tests := []struct {
+		name   string
+		opts   Options
+		query  string
+		pretty bool
+	}{
+		{name: "compact by default"},
+		{name: "query parameter", query: "?pretty=true", pretty: true},
+		{name: "other parameter value", query: "?pretty=1"},
+		{name: "option", opts: Options{PrettyJSON: true}, pretty: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			New(orderCache, nil, tt.opts).GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID+tt.query, nil))
+
+			body := strings.TrimSuffix(rec.Body.String(), "\n")
+			indented := strings.Contains(body, "\n  \"order_uid\": ")
+			if indented != tt.pretty || (!tt.pretty && strings.Contains(body, "\n")) {
+				t.Errorf("pretty = %v, body:\n%s", tt.pretty, body)
+			}
+			var got model.Order
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.OrderUID != order.OrderUID {
+				t.Errorf("body is not the order JSON: %v", err)
+			}
+		})
+	}
+}
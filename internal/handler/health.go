@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// Healthz проверка живости: процесс запущен и обрабатывает запросы
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz проверка готовности: 503, пока сервис не готов принимать трафик
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Ready != nil && !h.opts.Ready() {
+		writeError(w, http.StatusServiceUnavailable, "not_ready", "Service is not ready")
+		return
+	}
+	h.writeJSON(w, r, http.StatusOK, map[string]string{"status": "ready"})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// idempotencyKeyHeader заголовок с ключом идемпотентности запроса
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength ограничивает длину ключа идемпотентности
+const maxIdempotencyKeyLength = 255
+
+// idempotencyEntry сохраненный результат запроса с ключом идемпотентности
+type idempotencyEntry struct {
+	key         string
+	payloadHash [32]byte
+	done        bool
+	status      int
+	body        []byte
+	expires     time.Time
+}
+
+// idempotencyStore хранит результаты запросов по ключу идемпотентности
+// в течение ttl. Размер ограничен, при переполнении вытесняется самая старая запись.
+type idempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newIdempotencyStore(ttl time.Duration, maxSize int) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Begin резервирует ключ для нового запроса. Если ключ уже использован,
+// возвращается сохраненная запись и false; запись с done == false означает,
+// что первый запрос с этим ключом еще выполняется.
+func (s *idempotencyStore) Begin(key string, payloadHash [32]byte) (idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		entry := elem.Value.(*idempotencyEntry)
+		if time.Now().Before(entry.expires) {
+			return *entry, false
+		}
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+
+	entry := &idempotencyEntry{key: key, payloadHash: payloadHash, expires: time.Now().Add(s.ttl)}
+	s.entries[key] = s.order.PushFront(entry)
+	for s.order.Len() > s.maxSize {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*idempotencyEntry).key)
+	}
+	return *entry, true
+}
+
+// Complete сохраняет результат запроса для повторов с тем же ключом
+func (s *idempotencyStore) Complete(key string, status int, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		entry := elem.Value.(*idempotencyEntry)
+		entry.done = true
+		entry.status = status
+		entry.body = body
+	}
+}
+
+// Release освобождает ключ запроса, завершившегося ошибкой, чтобы клиент мог повторить его
+func (s *idempotencyStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, key)
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/store"
+
+	"go.uber.org/zap"
+)
+
+// maxOrderBodySize ограничивает размер тела запроса POST /order
+const maxOrderBodySize = 1 << 20
+
+// Order обрабатывает /order: GET — получение заказа по ?uid=, POST — прием заказа
+func (h *Handler) Order(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		h.CreateOrder(w, r)
+		return
+	}
+	h.GetOrder(w, r)
+}
+
+// CreateOrder принимает заказ в обход Kafka: POST /order.
+// С заголовком Idempotency-Key повторный запрос с тем же ключом в течение
+// IdempotencyTTL возвращает первоначальный ответ без повторной записи,
+// а запрос с тем же ключом и другим телом получает 409. Эндпоинт пишет в БД
+// в обход Kafka, поэтому доступ к нему проверяет authorizeAdmin.
+func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxOrderBodySize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large")
+		return
+	}
+
+	key := r.Header.Get(idempotencyKeyHeader)
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, "invalid_idempotency_key", "Idempotency-Key is too long")
+		return
+	}
+	if key != "" {
+		entry, ok := h.idempotency.Begin(key, sha256.Sum256(body))
+		if !ok {
+			switch {
+			case entry.payloadHash != sha256.Sum256(body):
+				writeError(w, http.StatusConflict, "idempotency_key_reused", "Idempotency-Key was used with a different payload")
+			case !entry.done:
+				writeError(w, http.StatusConflict, "request_in_progress", "A request with this Idempotency-Key is in progress")
+			default:
+				logger.Info("Replaying idempotent response", zap.String("idempotency_key", key))
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(entry.status)
+				_, _ = w.Write(entry.body)
+			}
+			return
+		}
+	}
+
+	status, response := h.createOrder(r, body)
+	if key != "" {
+		if status >= http.StatusInternalServerError {
+			h.idempotency.Release(key)
+		} else {
+			h.idempotency.Complete(key, status, response)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(response)
+}
+
+// createOrder разбирает, валидирует и сохраняет заказ и возвращает код и тело ответа
+func (h *Handler) createOrder(r *http.Request, body []byte) (int, []byte) {
+	var order model.Order
+	if err := json.Unmarshal(body, &order); err != nil {
+		return errorBody(http.StatusBadRequest, "invalid_json", "Invalid order JSON")
+	}
+
+	if err := h.validator.Validate(&order); err != nil {
+		return errorBody(http.StatusUnprocessableEntity, "invalid_order", err.Error())
+	}
+
+	if err := h.store.Save(r.Context(), &order); err != nil {
+		if errors.Is(err, store.ErrNoDatabase) {
+			return errorBody(http.StatusServiceUnavailable, "db_unavailable", "Database is not configured")
+		}
+		logger.Error("Failed to save order", zap.String("order_uid", order.OrderUID), zap.Error(err))
+		return errorBody(http.StatusInternalServerError, "db_error", "Failed to save order")
+	}
+
+	logger.Info("Order accepted via HTTP", zap.String("order_uid", order.OrderUID))
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(order); err != nil {
+		return errorBody(http.StatusInternalServerError, "encoding_error", "Error encoding response")
+	}
+	return http.StatusCreated, buf.Bytes()
+}
+
+// errorBody возвращает код и тело ответа с ошибкой в формате writeError
+func errorBody(status int, code, message string) (int, []byte) {
+	body, _ := json.Marshal(errorResponse{Error: message, Code: code})
+	return status, append(body, '\n')
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+)
+
+// postOrder отправляет POST /order с токеном администратора и ключом идемпотентности
+func postOrder(h *Handler, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(body))
+	req.Header.Set(adminTokenHeader, "secret")
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.Order(rec, req)
+	return rec
+}
+
+// orderJSON кодирует тестовый заказ
+func orderJSON(t *testing.T, uid string) string {
+	t.Helper()
+	body, err := json.Marshal(testutil.Order(uid))
+	if err != nil {
+		t.Fatalf("marshal order: %v", err)
+	}
+	return string(body)
+}
+
+func TestCreateOrderIdempotentReplay(t *testing.T) {
+	database := newFakeDB()
+	h := New(nil, database, Options{DebugEndpoints: true, AdminToken: "secret"})
+	body := orderJSON(t, "b563feb7b2b84b6test")
+
+	first := postOrder(h, body, "key-1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first POST: status = %d, want 201: %s", first.Code, first.Body.String())
+	}
+
+	replay := postOrder(h, body, "key-1")
+	if replay.Code != http.StatusCreated || replay.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want the original 201 response", replay.Code, replay.Body.String())
+	}
+	if replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replayed response has no Idempotent-Replayed header")
+	}
+	if writes := database.Writes(); writes != 1 {
+		t.Errorf("order written %d times, want 1", writes)
+	}
+
+	conflict := postOrder(h, orderJSON(t, "othertest"), "key-1")
+	if conflict.Code != http.StatusConflict {
+		t.Errorf("same key with another payload: status = %d, want 409", conflict.Code)
+	}
+	decodeError(t, conflict)
+
+	if rec := postOrder(h, body, "key-2"); rec.Code != http.StatusCreated || database.Writes() != 2 {
+		t.Errorf("new key: status = %d, writes = %d; want a fresh write", rec.Code, database.Writes())
+	}
+}
+
+func TestCreateOrderRequiresAdmin(t *testing.T) {
+	body := orderJSON(t, "b563feb7b2b84b6test")
+	tests := []struct {
+		name       string
+		opts       Options
+		token      string
+		wantStatus int
+	}{
+		{name: "debug endpoints disabled", opts: Options{}, wantStatus: http.StatusNotFound},
+		{name: "wrong token", opts: Options{DebugEndpoints: true, AdminToken: "secret"}, token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", opts: Options{DebugEndpoints: true, AdminToken: "secret"}, token: "secret", wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := newFakeDB()
+			req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(body))
+			if tt.token != "" {
+				req.Header.Set(adminTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			New(nil, database, tt.opts).Order(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			wantWrites := 0
+			if tt.wantStatus == http.StatusCreated {
+				wantWrites = 1
+			}
+			if writes := database.Writes(); writes != wantWrites {
+				t.Errorf("writes = %d, want %d", writes, wantWrites)
+			}
+		})
+	}
+}
+
+func TestCreateOrderInvalid(t *testing.T) {
+	h := New(nil, newFakeDB(), Options{DebugEndpoints: true, AdminToken: "secret"})
+
+	if rec := postOrder(h, "{", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed JSON: status = %d, want 400", rec.Code)
+	}
+	if rec := postOrder(h, `{"order_uid":"b563feb7b2b84b6test"}`, ""); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid order: status = %d, want 422", rec.Code)
+	}
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/validator"
+)
+
+// MaxBatchUIDs максимальное число UID в одном запросе /orders?uids=
+const MaxBatchUIDs = 100
+
+// DefaultPageLimit размер страницы /orders?after= по умолчанию
+const DefaultPageLimit = 50
+
+// ordersPage ответ постраничной выборки заказов
+type ordersPage struct {
+	Orders []*model.Order `json:"orders"`
+	Next   string         `json:"next,omitempty"`
+}
+
+// ListOrders обрабатывает запросы списка заказов:
+// GET /orders?from=<RFC3339>&to=<RFC3339> — заказы за период;
+// GET /orders?uids=a,b,c — заказы по списку UID;
+// GET /orders?after=<cursor>&limit=50 — страница заказов после курсора
+// (административный список, доступ проверяет authorizeAdmin).
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "db_unavailable", "Database is not configured")
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Has("uids") {
+		h.getOrdersByUIDs(w, r, query.Get("uids"))
+		return
+	}
+	if query.Has("after") || query.Has("limit") {
+		h.listOrdersPage(w, r, query.Get("after"), query.Get("limit"))
+		return
+	}
+
+	from, to, ok := parseDateRange(w, query)
+	if !ok {
+		return
+	}
+
+	orders, err := h.db.GetOrdersByDateRange(r.Context(), from, to)
+	if err != nil {
+		logger.Errorf("Failed to get orders by date range: %v", err)
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to get orders")
+		return
+	}
+
+	if len(orders) >= db.MaxRangeOrders {
+		w.Header().Set("X-Result-Truncated", "true")
+	}
+
+	h.writeJSON(w, r, http.StatusOK, orders)
+}
+
+// CountOrders возвращает число заказов: GET /orders/count,
+// с параметрами from и to — только созданных за период
+func (h *Handler) CountOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "db_unavailable", "Database is not configured")
+		return
+	}
+
+	var count int64
+	var err error
+	query := r.URL.Query()
+	if query.Has("from") || query.Has("to") {
+		from, to, ok := parseDateRange(w, query)
+		if !ok {
+			return
+		}
+		count, err = h.db.CountOrdersByDateRange(r.Context(), from, to)
+	} else {
+		count, err = h.db.CountOrders(r.Context())
+	}
+	if err != nil {
+		logger.Errorf("Failed to count orders: %v", err)
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to count orders")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, countResponse{Count: count})
+}
+
+// countResponse содержимое ответа /orders/count
+type countResponse struct {
+	Count int64 `json:"count"`
+}
+
+// parseDateRange разбирает параметры from и to в формате RFC3339.
+// При ошибке пишет ответ 400 и возвращает false.
+func parseDateRange(w http.ResponseWriter, query url.Values) (time.Time, time.Time, bool) {
+	if query.Get("from") == "" || query.Get("to") == "" {
+		writeError(w, http.StatusBadRequest, "missing_range", "Both from and to are required")
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_from", "Invalid from timestamp, expected RFC3339")
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.Parse(time.RFC3339, query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_to", "Invalid to timestamp, expected RFC3339")
+		return time.Time{}, time.Time{}, false
+	}
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, "invalid_range", "from must not be after to")
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// getOrdersByUIDs отдает заказы по списку UID в виде объекта uid -> заказ
+func (h *Handler) getOrdersByUIDs(w http.ResponseWriter, r *http.Request, value string) {
+	seen := make(map[string]struct{})
+	var uids []string
+	for _, uid := range strings.Split(value, ",") {
+		uid = strings.TrimSpace(uid)
+		if uid == "" {
+			continue
+		}
+		if !validator.ValidOrderUID(uid) {
+			writeError(w, http.StatusBadRequest, "invalid_uid", "Invalid order uid: "+uid)
+			return
+		}
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		uids = append(uids, uid)
+	}
+
+	if len(uids) == 0 {
+		writeError(w, http.StatusBadRequest, "missing_uid", "Missing order uids")
+		return
+	}
+	if len(uids) > MaxBatchUIDs {
+		writeError(w, http.StatusBadRequest, "too_many_uids", fmt.Sprintf("At most %d uids allowed", MaxBatchUIDs))
+		return
+	}
+
+	orders, err := h.db.GetOrdersByUIDs(r.Context(), uids)
+	if err != nil {
+		logger.Errorf("Failed to get orders by uids: %v", err)
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to get orders")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, orders)
+}
+
+// listOrdersPage отдает страницу заказов после курсора и курсор следующей страницы
+func (h *Handler) listOrdersPage(w http.ResponseWriter, r *http.Request, after, limitValue string) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	limit := DefaultPageLimit
+	if limitValue != "" {
+		parsed, err := strconv.Atoi(limitValue)
+		if err != nil || parsed <= 0 || parsed > db.MaxRangeOrders {
+			writeError(w, http.StatusBadRequest, "invalid_limit", fmt.Sprintf("limit must be between 1 and %d", db.MaxRangeOrders))
+			return
+		}
+		limit = parsed
+	}
+
+	var cursor *db.Cursor
+	if after != "" {
+		parsed, err := parseCursor(after)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", "Invalid after cursor, expected <order_uid>|<RFC3339 date_created>")
+			return
+		}
+		cursor = parsed
+	}
+
+	orders, err := h.db.ListOrdersAfter(r.Context(), cursor, limit)
+	if err != nil {
+		logger.Errorf("Failed to list orders: %v", err)
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to get orders")
+		return
+	}
+
+	page := ordersPage{Orders: orders}
+	if page.Orders == nil {
+		page.Orders = []*model.Order{}
+	}
+	if len(orders) == limit {
+		page.Next = formatCursor(orders[len(orders)-1])
+	}
+	h.writeJSON(w, r, http.StatusOK, page)
+}
+
+// parseCursor разбирает курсор вида <order_uid>|<RFC3339 date_created>
+func parseCursor(value string) (*db.Cursor, error) {
+	uid, date, ok := strings.Cut(value, "|")
+	if !ok || !validator.ValidOrderUID(uid) {
+		return nil, fmt.Errorf("invalid cursor %q", value)
+	}
+	dateCreated, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor date: %w", err)
+	}
+	return &db.Cursor{DateCreated: dateCreated, OrderUID: uid}, nil
+}
+
+// formatCursor возвращает курсор, указывающий на заказ
+func formatCursor(order *model.Order) string {
+	return order.OrderUID + "|" + order.DateCreated.UTC().Format(time.RFC3339Nano)
+}
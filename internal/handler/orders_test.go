@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// orderAt возвращает заказ, созданный в момент at
+func orderAt(uid string, at time.Time) *model.Order {
+	order := testutil.Order(uid)
+	order.DateCreated = model.NewTimestamp(at)
+	return order
+}
+
+func TestListOrdersByDateRange(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	database := newFakeDB(orderAt("before", base.Add(-time.Second)), orderAt("from", base),
+		orderAt("to", base.Add(time.Hour)), orderAt("after", base.Add(time.Hour+time.Second)))
+	h := New(nil, database, Options{})
+
+	rec := httptest.NewRecorder()
+	h.ListOrders(rec, httptest.NewRequest(http.MethodGet,
+		"/orders?from=2024-03-01T15:00:00%2B03:00&to=2024-03-01T13:00:00Z", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if !database.from.Equal(base) || !database.to.Equal(base.Add(time.Hour)) {
+		t.Errorf("queried range [%v, %v], want [%v, %v]", database.from, database.to, base, base.Add(time.Hour))
+	}
+	var orders []*model.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &orders); err != nil {
+		t.Fatalf("decode orders: %v", err)
+	}
+	if len(orders) != 2 || orders[0].OrderUID != "from" || orders[1].OrderUID != "to" {
+		t.Errorf("orders = %v, want [from to]", orders)
+	}
+	if rec.Header().Get("X-Result-Truncated") != "" {
+		t.Error("X-Result-Truncated is set for a short result")
+	}
+}
+
+func TestListOrdersInvalidDateRange(t *testing.T) {
+	database := newFakeDB()
+	h := New(nil, database, Options{})
+
+	tests := []struct {
+		query    string
+		wantCode string
+	}{
+		{query: "", wantCode: "missing_range"},
+		{query: "from=2024-03-01T00:00:00Z", wantCode: "missing_range"},
+		{query: "from=yesterday&to=2024-03-01T00:00:00Z", wantCode: "invalid_from"},
+		{query: "from=2024-03-01T00:00:00Z&to=2024-03-01", wantCode: "invalid_to"},
+		{query: "from=2024-03-02T00:00:00Z&to=2024-03-01T00:00:00Z", wantCode: "invalid_range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode+"/"+tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ListOrders(rec, httptest.NewRequest(http.MethodGet, "/orders?"+tt.query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+			if body := decodeError(t, rec); body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+	if !database.from.IsZero() {
+		t.Error("database was queried for an invalid range")
+	}
+}
+
+func TestListOrdersDBError(t *testing.T) {
+	database := newFakeDB()
+	database.err = errors.New("connection refused")
+	h := New(nil, database, Options{})
+
+	rec := httptest.NewRecorder()
+	h.ListOrders(rec, httptest.NewRequest(http.MethodGet,
+		"/orders?from=2024-03-01T00:00:00Z&to=2024-03-02T00:00:00Z", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	decodeError(t, rec)
+}
+
+func TestListOrdersByUIDs(t *testing.T) {
+	database := newFakeDB(testutil.Order("first"), testutil.Order("second"))
+	h := New(nil, database, Options{})
+
+	rec := httptest.NewRecorder()
+	h.ListOrders(rec, httptest.NewRequest(http.MethodGet, "/orders?uids=first,missing,%20first%20,,second", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if want := []string{"first", "missing", "second"}; !slices.Equal(database.uids, want) {
+		t.Errorf("queried uids = %v, want deduplicated %v", database.uids, want)
+	}
+	var orders map[string]*model.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &orders); err != nil {
+		t.Fatalf("decode orders: %v", err)
+	}
+	if len(orders) != 2 || orders["first"] == nil || orders["second"] == nil {
+		t.Errorf("orders = %v, want first and second only", orders)
+	}
+}
+
+func TestListOrdersByUIDsInvalid(t *testing.T) {
+	database := newFakeDB()
+	h := New(nil, database, Options{})
+
+	tooMany := make([]string, MaxBatchUIDs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("uid%d", i)
+	}
+
+	tests := []struct {
+		uids     string
+		wantCode string
+	}{
+		{uids: "", wantCode: "missing_uid"},
+		{uids: ",,", wantCode: "missing_uid"},
+		{uids: "first,bad-uid", wantCode: "invalid_uid"},
+		{uids: strings.Join(tooMany, ","), wantCode: "too_many_uids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ListOrders(rec, httptest.NewRequest(http.MethodGet, "/orders?uids="+tt.uids, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+			if body := decodeError(t, rec); body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+	if database.uids != nil {
+		t.Errorf("database was queried with %v for invalid input", database.uids)
+	}
+
+	// Ровно MaxBatchUIDs допустимо
+	rec := httptest.NewRecorder()
+	h.ListOrders(rec, httptest.NewRequest(http.MethodGet, "/orders?uids="+strings.Join(tooMany[:MaxBatchUIDs], ","), nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status for %d uids = %d, want 200", MaxBatchUIDs, rec.Code)
+	}
+}
+
+// getPage запрашивает страницу административного списка заказов
+func getPage(t *testing.T, h *Handler, query string) ordersPage {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/orders?"+query, nil)
+	req.Header.Set(adminTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	h.ListOrders(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /orders?%s: status = %d, want 200: %s", query, rec.Code, rec.Body.String())
+	}
+	var page ordersPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode page: %v", err)
+	}
+	return page
+}
+
+func TestListOrdersPages(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var want []string
+	database := newFakeDB()
+	for i := range 5 {
+		uid := fmt.Sprintf("order%d", i)
+		database.Add(orderAt(uid, base.Add(time.Duration(i)*time.Minute)))
+		want = append(want, uid)
+	}
+	// Заказ с той же датой, что и у конца первой страницы, но большим UID
+	database.Add(orderAt("order1b", base.Add(time.Minute)))
+	want = slices.Insert(want, 2, "order1b")
+	h := New(nil, database, Options{DebugEndpoints: true, AdminToken: "secret"})
+
+	var got []string
+	page := getPage(t, h, "limit=2")
+	for pages := 1; ; pages++ {
+		for _, order := range page.Orders {
+			got = append(got, order.OrderUID)
+		}
+		if page.Next == "" {
+			break
+		}
+		if pages == 1 {
+			// Новые заказы между запросами: ранний остается позади курсора, поздний попадает в конец
+			database.Add(orderAt("early", base.Add(-time.Hour)))
+			database.Add(orderAt("late", base.Add(time.Hour)))
+			want = append(want, "late")
+		}
+		if pages > len(want) {
+			t.Fatalf("pagination did not finish after %d pages", pages)
+		}
+		page = getPage(t, h, "limit=2&after="+url.QueryEscape(page.Next))
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("paged orders = %v, want %v without overlaps or gaps", got, want)
+	}
+}
+
+func TestListOrdersPagesRequireAdmin(t *testing.T) {
+	database := newFakeDB(testutil.Order("b563feb7b2b84b6test"))
+	tests := []struct {
+		name       string
+		opts       Options
+		token      string
+		wantStatus int
+	}{
+		{name: "debug endpoints disabled", opts: Options{}, wantStatus: http.StatusNotFound},
+		{name: "wrong token", opts: Options{DebugEndpoints: true, AdminToken: "secret"}, token: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", opts: Options{DebugEndpoints: true, AdminToken: "secret"}, token: "secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders?limit=10", nil)
+			if tt.token != "" {
+				req.Header.Set(adminTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			New(nil, database, tt.opts).ListOrders(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCountOrders(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	database := newFakeDB(orderAt("before", base.Add(-time.Second)), orderAt("from", base),
+		orderAt("to", base.Add(time.Hour)), orderAt("after", base.Add(time.Hour+time.Second)))
+	h := New(nil, database, Options{})
+
+	tests := []struct {
+		query string
+		want  int64
+	}{
+		{query: "", want: 4},
+		{query: "?from=2024-03-01T12:00:00Z&to=2024-03-01T13:00:00Z", want: 2},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.CountOrders(rec, httptest.NewRequest(http.MethodGet, "/orders/count"+tt.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /orders/count%s: status = %d, want 200: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		var body countResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode count: %v", err)
+		}
+		if body.Count != tt.want {
+			t.Errorf("GET /orders/count%s: count = %d, want %d", tt.query, body.Count, tt.want)
+		}
+	}
+}
+
+func TestCountOrdersErrors(t *testing.T) {
+	failing := newFakeDB()
+	failing.err = errors.New("connection refused")
+	tests := []struct {
+		name       string
+		h          *Handler
+		target     string
+		wantStatus int
+	}{
+		{name: "invalid range", h: New(nil, newFakeDB(), Options{}), target: "/orders/count?from=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "no database", h: New(nil, nil, Options{}), target: "/orders/count", wantStatus: http.StatusServiceUnavailable},
+		{name: "DB error", h: New(nil, failing, Options{}), target: "/orders/count", wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.h.CountOrders(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			decodeError(t, rec)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestParseOrderPath(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantUID    string
+		wantAction string
+		wantOK     bool
+	}{
+		{path: "/order", wantOK: true},
+		{path: "/order/", wantOK: true},
+		{path: "/order/abc", wantUID: "abc", wantOK: true},
+		{path: "/order/abc/", wantUID: "abc", wantOK: true},
+		{path: "/order/abc/delivery", wantUID: "abc", wantAction: "delivery", wantOK: true},
+		{path: "/order/abc/x", wantUID: "abc", wantAction: "x", wantOK: true},
+		{path: "/order/abc/x/y"},
+		{path: "/order//abc"},
+		{path: "/order/abc//"},
+		{path: "/orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			uid, action, ok := parseOrderPath(tt.path)
+			if uid != tt.wantUID || action != tt.wantAction || ok != tt.wantOK {
+				t.Errorf("parseOrderPath(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.path, uid, action, ok, tt.wantUID, tt.wantAction, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetOrderPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "path", target: "/order/abc", wantStatus: http.StatusOK},
+		{name: "trailing slash", target: "/order/abc/", wantStatus: http.StatusOK},
+		{name: "query", target: "/order?uid=abc", wantStatus: http.StatusOK},
+		{name: "same uid in path and query", target: "/order/abc?uid=abc", wantStatus: http.StatusOK},
+		{name: "unknown resource", target: "/order/abc/x", wantStatus: http.StatusBadRequest, wantCode: "invalid_path"},
+		{name: "extra segments", target: "/order/abc/x/y", wantStatus: http.StatusBadRequest, wantCode: "invalid_path"},
+		{name: "conflicting uids", target: "/order/abc?uid=other", wantStatus: http.StatusBadRequest, wantCode: "conflicting_uid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := newFakeDB(testutil.Order("abc"))
+			h := New(nil, database, Options{})
+
+			rec := httptest.NewRecorder()
+			h.GetOrder(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if body := decodeError(t, rec); body.Code != tt.wantCode {
+					t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+				}
+				if reads := database.Reads(); reads != 0 {
+					t.Errorf("database queried %d times for a rejected path, want 0", reads)
+				}
+				return
+			}
+			var got model.Order
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode order: %v", err)
+			}
+			if got.OrderUID != "abc" {
+				t.Errorf("order_uid = %q, want abc", got.OrderUID)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-kafka-postgres/internal/logger"
+)
+
+// errorResponse тело ответа с ошибкой
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// writeJSON пишет значение в формате JSON с указанным статусом.
+// С отступами JSON выводится при PrettyJSON или параметре запроса pretty=true.
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, status int, value any) {
+	pretty := h.opts.PrettyJSON || r.URL.Query().Get("pretty") == "true"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(value); err != nil {
+		logger.Errorf("Error encoding response: %v", err)
+	}
+}
+
+// writeError пишет ошибку в формате JSON с указанным статусом
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(errorResponse{Error: message, Code: code}); err != nil {
+		logger.Errorf("Error encoding error response: %v", err)
+	}
+}
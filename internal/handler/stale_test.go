@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/store"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// staleHandler возвращает обработчик без основного кэша, который уже один раз
+// прочитал order из БД, после чего все чтения БД завершаются ошибкой err
+func staleHandler(t *testing.T, order *model.Order, serveStale bool, err error) *Handler {
+	t.Helper()
+	database := newFakeDB(order)
+	orderStore := store.New(nil, database, store.Options{StaleTTL: time.Hour, StaleMaxSize: 10})
+	h := New(nil, database, Options{Store: orderStore, ServeStale: serveStale})
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("priming GET status = %d, want 200", rec.Code)
+	}
+	database.err = err
+	return h
+}
+
+func TestGetOrderServesStaleOnDBError(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	h := staleHandler(t, order, true, errors.New("connection refused"))
+
+	rec := httptest.NewRecorder()
+	h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 from the stale cache", rec.Code)
+	}
+	if got := rec.Header().Get("X-Stale"); got != "true" {
+		t.Errorf("X-Stale = %q, want true", got)
+	}
+	var got model.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if got.OrderUID != order.OrderUID {
+		t.Errorf("order_uid = %q, want %q", got.OrderUID, order.OrderUID)
+	}
+}
+
+func TestGetOrderStaleNotServed(t *testing.T) {
+	tests := []struct {
+		name       string
+		serveStale bool
+		err        error
+	}{
+		{name: "disabled", err: errors.New("connection refused")},
+		{name: "order deleted", serveStale: true, err: db.ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			h := staleHandler(t, order, tt.serveStale, tt.err)
+
+			rec := httptest.NewRecorder()
+			h.GetOrder(rec, httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", rec.Code)
+			}
+			if got := rec.Header().Get("X-Stale"); got != "" {
+				t.Errorf("X-Stale = %q, want no header", got)
+			}
+		})
+	}
+}
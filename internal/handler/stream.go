@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+)
+
+// streamFlushEvery через сколько заказов ответ /orders/stream отправляется клиенту
+const streamFlushEvery = 100
+
+// errStreamLimit прерывает выгрузку по достижении StreamMaxOrders
+var errStreamLimit = errors.New("stream order limit reached")
+
+// StreamOrders отдает все заказы в формате NDJSON (один JSON-объект на строку):
+// GET /orders/stream. Заказы читаются из БД и пишутся по мере получения, поэтому
+// выгрузка не требует памяти под весь набор данных. Выгрузка содержит
+// персональные данные и доступна только администраторам (см. authorizeAdmin);
+// она длится не дольше StreamTimeout и прекращается после StreamMaxOrders заказов.
+func (h *Handler) StreamOrders(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "db_unavailable", "Database is not configured")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.opts.StreamTimeout)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+
+	var count int
+	err := h.db.StreamOrders(ctx, func(order *model.Order) error {
+		if h.opts.StreamMaxOrders > 0 && count >= h.opts.StreamMaxOrders {
+			return errStreamLimit
+		}
+		if err := encoder.Encode(order); err != nil {
+			return err
+		}
+		count++
+		if count%streamFlushEvery == 0 {
+			// Не все ResponseWriter поддерживают Flush; тогда данные уйдут при заполнении буфера
+			_ = controller.Flush()
+		}
+		return nil
+	})
+	if errors.Is(err, errStreamLimit) {
+		logger.Warnf("Orders stream stopped at the limit of %d orders", count)
+		err = nil
+	}
+	if err != nil {
+		// Заголовки уже могли быть отправлены, поэтому ошибка только логируется:
+		// клиент увидит оборванный поток без завершающей строки
+		logger.Errorf("Failed to stream orders after %d orders: %v", count, err)
+		if count == 0 {
+			writeError(w, http.StatusInternalServerError, "db_error", "Failed to stream orders")
+		}
+		return
+	}
+	_ = controller.Flush()
+	logger.Infof("Streamed %d orders", count)
+}
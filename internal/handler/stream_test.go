@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// streamOrders вызывает GET /orders/stream с токеном администратора
+func streamOrders(h *Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orders/stream", nil)
+	req.Header.Set(adminTokenHeader, "secret")
+	rec := httptest.NewRecorder()
+	h.StreamOrders(rec, req)
+	return rec
+}
+
+// readNDJSON разбирает тело ответа по одному заказу на строку
+func readNDJSON(t *testing.T, rec *httptest.ResponseRecorder) []*model.Order {
+	t.Helper()
+	var orders []*model.Order
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var order model.Order
+		if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+			t.Fatalf("line %d %q is not an order: %v", len(orders)+1, scanner.Text(), err)
+		}
+		orders = append(orders, &order)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	return orders
+}
+
+func TestStreamOrders(t *testing.T) {
+	want := []*model.Order{testutil.Order("stream1"), testutil.Order("stream2"), testutil.Order("stream3")}
+	want[1].Items = append(want[1].Items, want[1].Items[0])
+	want[1].Items[1].ChrtID = 9934931
+	// Порядок добавления не совпадает с порядком выгрузки
+	h := New(nil, newFakeDB(want[2], want[0], want[1]), Options{DebugEndpoints: true, AdminToken: "secret"})
+
+	rec := streamOrders(h)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", ct)
+	}
+	got := readNDJSON(t, rec)
+	if len(got) != len(want) {
+		t.Fatalf("streamed %d orders, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("line %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestStreamOrdersRequiresAdmin(t *testing.T) {
+	database := newFakeDB(testutil.Order("stream1"))
+	tests := []struct {
+		name       string
+		h          *Handler
+		token      string
+		wantStatus int
+	}{
+		{name: "debug endpoints disabled", h: New(nil, database, Options{}), token: "secret", wantStatus: http.StatusNotFound},
+		{name: "missing token", h: New(nil, database, Options{DebugEndpoints: true, AdminToken: "secret"}),
+			wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", h: New(nil, database, Options{DebugEndpoints: true, AdminToken: "secret"}),
+			token: "guess", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders/stream", nil)
+			if tt.token != "" {
+				req.Header.Set(adminTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			tt.h.StreamOrders(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct == "application/x-ndjson" {
+				t.Error("orders were streamed without admin access")
+			}
+		})
+	}
+}
+
+func TestStreamOrdersMaxOrders(t *testing.T) {
+	database := newFakeDB(testutil.Order("stream1"), testutil.Order("stream2"), testutil.Order("stream3"))
+	h := New(nil, database, Options{DebugEndpoints: true, AdminToken: "secret", StreamMaxOrders: 2})
+
+	got := readNDJSON(t, streamOrders(h))
+	if len(got) != 2 || got[0].OrderUID != "stream1" || got[1].OrderUID != "stream2" {
+		t.Errorf("streamed %d orders, want the first 2", len(got))
+	}
+}
+
+func TestStreamOrdersTimeout(t *testing.T) {
+	database := newFakeDB(testutil.Order("stream1"), testutil.Order("stream2"))
+	database.streamHang = true
+	h := New(nil, database, Options{DebugEndpoints: true, AdminToken: "secret", StreamTimeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	got := readNDJSON(t, streamOrders(h))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stream took %v, want it cut off by the 20ms timeout", elapsed)
+	}
+	if len(got) != 1 {
+		t.Errorf("streamed %d orders before the timeout, want 1", len(got))
+	}
+}
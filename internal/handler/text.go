@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// wantsText сообщает, предпочитает ли клиент text/plain ответу в JSON.
+// JSON остается форматом по умолчанию, в том числе для */* и application/json:
+// text/plain выбирается, только если его вес в Accept строго больше.
+func wantsText(r *http.Request) bool {
+	var textQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/plain":
+			textQ = max(textQ, q)
+		case "application/json", "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return textQ > 0 && textQ > jsonQ
+}
+
+// renderOrderText пишет краткую сводку заказа в человекочитаемом виде
+func renderOrderText(w io.Writer, order *model.Order) error {
+	_, err := fmt.Fprintf(w,
+		"Order:    %s\nCustomer: %s\nTotal:    %d %s\nItems:    %d\nCity:     %s\n",
+		order.OrderUID,
+		order.CustomerID,
+		order.Payment.Amount, order.Payment.Currency,
+		len(order.Items),
+		order.Delivery.City,
+	)
+	return err
+}
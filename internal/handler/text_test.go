@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestWantsText(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "", want: false},
+		{accept: "*/*", want: false},
+		{accept: "application/json", want: false},
+		{accept: "text/plain", want: true},
+		{accept: "text/plain, */*", want: false},
+		{accept: "text/plain, */*;q=0.5", want: true},
+		{accept: "application/json;q=0.9, text/plain", want: true},
+		{accept: "text/plain;q=0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/order/x", nil)
+			req.Header.Set("Accept", tt.accept)
+			if got := wantsText(req); got != tt.want {
+				t.Errorf("wantsText(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderOrderText(t *testing.T) {
+	var b strings.Builder
+	if err := renderOrderText(&b, testutil.Order("b563feb7b2b84b6test")); err != nil {
+		t.Fatalf("renderOrderText: %v", err)
+	}
+	want := "Order:    b563feb7b2b84b6test\n" +
+		"Customer: test\n" +
+		"Total:    1817 USD\n" +
+		"Items:    1\n" +
+		"City:     Kiryat Mozkin\n"
+	if b.String() != want {
+		t.Errorf("text =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestGetOrderContentTypes(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := cache.New(10)
+	orderCache.Set(order)
+	h := New(orderCache, nil, Options{})
+
+	tests := []struct {
+		accept, wantType string
+	}{
+		{accept: "", wantType: "application/json"},
+		{accept: "application/json", wantType: "application/json"},
+		{accept: "text/plain", wantType: "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/order/"+order.OrderUID, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			h.GetOrder(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.wantType)
+			}
+			if vary := rec.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Vary = %q, want Accept", vary)
+			}
+			if strings.HasPrefix(tt.wantType, "text/plain") {
+				if !strings.HasPrefix(rec.Body.String(), "Order:    "+order.OrderUID+"\n") {
+					t.Errorf("text body = %q", rec.Body.String())
+				}
+				return
+			}
+			var got model.Order
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.OrderUID != order.OrderUID {
+				t.Errorf("JSON body %q: %v", rec.Body.String(), err)
+			}
+		})
+	}
+}
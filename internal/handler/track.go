@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/validator"
+
+	"go.uber.org/zap"
+)
+
+// TrackOrder возвращает заказ по трек-номеру: GET /track/{trackNumber}
+func (h *Handler) TrackOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	if h.db == nil {
+		writeError(w, http.StatusServiceUnavailable, "db_unavailable", "Database is not configured")
+		return
+	}
+
+	trackNumber := strings.TrimPrefix(r.URL.Path, "/track/")
+	if trackNumber == "" {
+		writeError(w, http.StatusBadRequest, "missing_track_number", "Missing track number")
+		return
+	}
+	if !validator.ValidTrackNumber(trackNumber) {
+		writeError(w, http.StatusBadRequest, "invalid_track_number", "Invalid track number")
+		return
+	}
+
+	order, err := h.db.GetOrderByTrackNumber(r.Context(), trackNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrOrderNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "Order not found")
+			return
+		}
+		logger.Error("Failed to get order by track number", zap.String("track_number", trackNumber), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "db_error", "Failed to get order")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, order)
+}
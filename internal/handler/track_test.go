@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestTrackOrder(t *testing.T) {
+	older := testutil.Order("trackolder")
+	newer := testutil.Order("tracknewer")
+	newer.DateCreated = model.NewTimestamp(older.DateCreated.Add(time.Hour))
+	other := testutil.Order("trackother")
+	other.TrackNumber = "WBILMOTHERTRACK"
+	h := New(nil, newFakeDB(older, newer, other), Options{})
+
+	rec := httptest.NewRecorder()
+	h.TrackOrder(rec, httptest.NewRequest(http.MethodGet, "/track/WBILMTESTTRACK", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var got model.Order
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if got.OrderUID != newer.OrderUID {
+		t.Errorf("order_uid = %q, want the most recent %q", got.OrderUID, newer.OrderUID)
+	}
+	if len(got.Items) != 1 {
+		t.Errorf("items = %d, want 1", len(got.Items))
+	}
+}
+
+func TestTrackOrderErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "not found", target: "/track/WBILMMISSING", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "missing track number", target: "/track/", wantStatus: http.StatusBadRequest, wantCode: "missing_track_number"},
+		{name: "invalid track number", target: "/track/WBIL%20TEST", wantStatus: http.StatusBadRequest, wantCode: "invalid_track_number"},
+		{name: "database error", target: "/track/WBILMTESTTRACK", err: errors.New("connection reset"),
+			wantStatus: http.StatusInternalServerError, wantCode: "db_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := newFakeDB(testutil.Order("b563feb7b2b84b6test"))
+			database.err = tt.err
+			h := New(nil, database, Options{})
+
+			rec := httptest.NewRecorder()
+			h.TrackOrder(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if body := decodeError(t, rec); body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
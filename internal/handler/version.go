@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-kafka-postgres/internal/version"
+)
+
+// Version возвращает сведения о сборке: коммит, время сборки и версию Go
+func (h *Handler) Version(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, r, http.StatusOK, version.Get())
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"go-kafka-postgres/internal/version"
+)
+
+// getVersion запрашивает GET /version и разбирает ответ
+func getVersion(t *testing.T) map[string]string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	New(nil, nil, Options{}).Version(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode version: %v", err)
+	}
+	return body
+}
+
+func TestVersionDefaults(t *testing.T) {
+	body := getVersion(t)
+	want := map[string]string{"commit": "unknown", "build_time": "unknown", "go_version": runtime.Version()}
+	for field, value := range want {
+		if got, ok := body[field]; !ok || got != value {
+			t.Errorf("%s = %q (present %v), want %q", field, got, ok, value)
+		}
+	}
+}
+
+func TestVersionInjected(t *testing.T) {
+	commit, buildTime := version.Commit, version.BuildTime
+	t.Cleanup(func() { version.Commit, version.BuildTime = commit, buildTime })
+	version.Commit, version.BuildTime = "4bb2649", "2026-10-14T11:19:43Z"
+
+	body := getVersion(t)
+	if body["commit"] != "4bb2649" || body["build_time"] != "2026-10-14T11:19:43Z" {
+		t.Errorf("version = %v, want the injected commit and build time", body)
+	}
+}
@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+)
+
+// Поддерживаемые механизмы SASL
+const (
+	MechanismPlain       = "PLAIN"
+	MechanismSCRAMSHA256 = "SCRAM-SHA-256"
+)
+
+// Security параметры безопасного подключения к Kafka
+type Security struct {
+	SASLUser      string
+	SASLPassword  string
+	SASLMechanism string
+	TLSEnable     bool
+	TLSCAFile     string
+}
+
+// NewConfig создает конфигурацию sarama с учетом параметров безопасности.
+// Для пустых параметров используется plaintext-подключение.
+func NewConfig(sec Security) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	if err := ApplySecurity(config, sec); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ApplySecurity настраивает SASL и TLS в конфигурации sarama
+func ApplySecurity(config *sarama.Config, sec Security) error {
+	if sec.SASLUser != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = sec.SASLUser
+		config.Net.SASL.Password = sec.SASLPassword
+		config.Net.SASL.Handshake = true
+
+		switch sec.SASLMechanism {
+		case "", MechanismPlain:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case MechanismSCRAMSHA256:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hashGenerator: sha256.New}
+			}
+		default:
+			return fmt.Errorf("unsupported SASL mechanism: %s", sec.SASLMechanism)
+		}
+	}
+
+	if sec.TLSEnable {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if sec.TLSCAFile != "" {
+			caCert, err := os.ReadFile(sec.TLSCAFile)
+			if err != nil {
+				return fmt.Errorf("read TLS CA file error: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caCert) {
+				return fmt.Errorf("no certificates found in %s", sec.TLSCAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	return nil
+}
+
+// scramClient реализует sarama.SCRAMClient поверх xdg-go/scram
+type scramClient struct {
+	hashGenerator scram.HashGeneratorFcn
+	conversation  *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGenerator.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conversation.Done()
+}
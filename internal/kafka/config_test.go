@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestNewConfigSecurity(t *testing.T) {
+	tests := []struct {
+		name          string
+		sec           Security
+		wantSASL      bool
+		wantMechanism sarama.SASLMechanism
+		wantSCRAM     bool
+		wantTLS       bool
+	}{
+		{name: "plaintext", sec: Security{}},
+		{
+			name:          "default mechanism",
+			sec:           Security{SASLUser: "user", SASLPassword: "secret"},
+			wantSASL:      true,
+			wantMechanism: sarama.SASLTypePlaintext,
+		},
+		{
+			name:          "plain with tls",
+			sec:           Security{SASLUser: "user", SASLPassword: "secret", SASLMechanism: MechanismPlain, TLSEnable: true},
+			wantSASL:      true,
+			wantMechanism: sarama.SASLTypePlaintext,
+			wantTLS:       true,
+		},
+		{
+			name:          "scram-sha-256",
+			sec:           Security{SASLUser: "user", SASLPassword: "secret", SASLMechanism: MechanismSCRAMSHA256},
+			wantSASL:      true,
+			wantMechanism: sarama.SASLTypeSCRAMSHA256,
+			wantSCRAM:     true,
+		},
+		{name: "tls only", sec: Security{TLSEnable: true}, wantTLS: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewConfig(tt.sec)
+			if err != nil {
+				t.Fatalf("NewConfig: %v", err)
+			}
+
+			sasl := config.Net.SASL
+			if sasl.Enable != tt.wantSASL {
+				t.Fatalf("SASL.Enable = %v, want %v", sasl.Enable, tt.wantSASL)
+			}
+			if tt.wantSASL {
+				if sasl.User != tt.sec.SASLUser || sasl.Password != tt.sec.SASLPassword {
+					t.Errorf("SASL credentials = %q/%q, want %q/%q", sasl.User, sasl.Password, tt.sec.SASLUser, tt.sec.SASLPassword)
+				}
+				if sasl.Mechanism != tt.wantMechanism {
+					t.Errorf("SASL.Mechanism = %q, want %q", sasl.Mechanism, tt.wantMechanism)
+				}
+				if !sasl.Handshake {
+					t.Error("SASL.Handshake = false, want true")
+				}
+			}
+			if tt.wantSCRAM {
+				if sasl.SCRAMClientGeneratorFunc == nil {
+					t.Fatal("SCRAMClientGeneratorFunc is nil")
+				}
+				if _, ok := sasl.SCRAMClientGeneratorFunc().(*scramClient); !ok {
+					t.Errorf("SCRAM client has type %T, want *scramClient", sasl.SCRAMClientGeneratorFunc())
+				}
+			} else if sasl.SCRAMClientGeneratorFunc != nil {
+				t.Error("SCRAMClientGeneratorFunc is set for a non-SCRAM mechanism")
+			}
+
+			if config.Net.TLS.Enable != tt.wantTLS {
+				t.Fatalf("TLS.Enable = %v, want %v", config.Net.TLS.Enable, tt.wantTLS)
+			}
+			if tt.wantTLS && config.Net.TLS.Config == nil {
+				t.Error("TLS.Config is nil")
+			}
+			if err := config.Validate(); err != nil {
+				t.Errorf("config.Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	emptyCA := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(emptyCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		sec  Security
+	}{
+		{name: "unsupported mechanism", sec: Security{SASLUser: "user", SASLMechanism: "GSSAPI"}},
+		{name: "missing CA file", sec: Security{TLSEnable: true, TLSCAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "CA file without certificates", sec: Security{TLSEnable: true, TLSCAFile: emptyCA}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConfig(tt.sec); err == nil {
+				t.Error("NewConfig succeeded, want error")
+			}
+		})
+	}
+}
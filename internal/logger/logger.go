@@ -5,7 +5,9 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var Logger *zap.Logger
+// Logger до вызова Init ничего не пишет, поэтому пакеты можно использовать без
+// инициализации логгера (например, в тестах)
+var Logger = zap.NewNop()
 
 func Init(level string) error {
 	var zapLevel zapcore.Level
@@ -47,6 +49,10 @@ func Sync() {
 	_ = Logger.Sync()
 }
 
+func Debug(msg string, fields ...zap.Field) {
+	Logger.Debug(msg, fields...)
+}
+
 func Info(msg string, fields ...zap.Field) {
 	Logger.Info(msg, fields...)
 }
@@ -55,6 +61,14 @@ func Infof(template string, args ...interface{}) {
 	Logger.Sugar().Infof(template, args...)
 }
 
+func Warn(msg string, fields ...zap.Field) {
+	Logger.Warn(msg, fields...)
+}
+
+func Warnf(template string, args ...interface{}) {
+	Logger.Sugar().Warnf(template, args...)
+}
+
 func Error(msg string, fields ...zap.Field) {
 	Logger.Error(msg, fields...)
 }
@@ -0,0 +1,288 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// collector метрика, которую можно вывести в текстовом формате Prometheus
+type collector interface {
+	write(b *strings.Builder)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// vec общая часть метрик с метками
+type vec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	kind   string
+	labels []string
+	values map[string]float64
+	keys   map[string][]string
+}
+
+func newVec(name, help, kind string, labels []string) *vec {
+	return &vec{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		values: make(map[string]float64),
+		keys:   make(map[string][]string),
+	}
+}
+
+func (v *vec) key(labelValues []string) string {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := v.keys[key]; !ok {
+		v.keys[key] = append([]string(nil), labelValues...)
+	}
+	return key
+}
+
+func (v *vec) set(value float64, labelValues []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[v.key(labelValues)] = value
+}
+
+func (v *vec) add(delta float64, labelValues []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[v.key(labelValues)] += delta
+}
+
+func (v *vec) get(labelValues []string) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.values[v.key(labelValues)]
+}
+
+func (v *vec) write(b *strings.Builder) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", v.name, v.kind)
+
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s%s %g\n", v.name, formatLabels(v.labels, v.keys[k], nil), v.values[k])
+	}
+}
+
+// formatLabels форматирует метки в виде {a="1",b="2"}
+func formatLabels(names, values []string, extra []string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+len(extra)/2)
+	for i, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%q", name, values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", extra[i], extra[i+1]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// GaugeVec метрика-значение с метками
+type GaugeVec struct {
+	v *vec
+}
+
+// NewGaugeVec создает и регистрирует gauge с метками
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{v: newVec(name, help, "gauge", labels)}
+	register(g.v)
+	return g
+}
+
+// Set устанавливает значение для набора меток
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.v.set(value, labelValues)
+}
+
+// Get возвращает текущее значение для набора меток
+func (g *GaugeVec) Get(labelValues ...string) float64 {
+	return g.v.get(labelValues)
+}
+
+// CounterVec монотонно растущий счетчик с метками
+type CounterVec struct {
+	v *vec
+}
+
+// NewCounterVec создает и регистрирует счетчик с метками
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{v: newVec(name, help, "counter", labels)}
+	register(c.v)
+	return c
+}
+
+// Inc увеличивает счетчик на 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.v.add(1, labelValues)
+}
+
+// Add увеличивает счетчик на delta (delta должен быть неотрицательным)
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.v.add(delta, labelValues)
+}
+
+// Get возвращает текущее значение для набора меток
+func (c *CounterVec) Get(labelValues ...string) float64 {
+	return c.v.get(labelValues)
+}
+
+// Handler возвращает HTTP обработчик, отдающий метрики в текстовом формате Prometheus
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registryMu.Lock()
+		collectors := append([]collector(nil), registry...)
+		registryMu.Unlock()
+
+		var b strings.Builder
+		for _, c := range collectors {
+			c.write(&b)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+// DefaultBuckets границы корзин гистограммы длительностей в секундах
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogramSeries значения гистограммы для одного набора меток
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// HistogramVec гистограмма распределения значений с метками
+type HistogramVec struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+// NewHistogramVec создает и регистрирует гистограмму с метками;
+// buckets — возрастающие верхние границы корзин (nil означает DefaultBuckets)
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: append([]float64(nil), buckets...),
+		series:  make(map[string]*histogramSeries),
+	}
+	register(h)
+	return h
+}
+
+// Observe добавляет значение для набора меток
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", h.name, len(h.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Count возвращает число наблюдений для набора меток
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[strings.Join(labelValues, "\xff")]; ok {
+		return s.count
+	}
+	return 0
+}
+
+// Sum возвращает сумму наблюдений для набора меток
+func (h *HistogramVec) Sum(labelValues ...string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[strings.Join(labelValues, "\xff")]; ok {
+		return s.sum
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", h.name, h.help)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", h.name)
+
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := h.series[k]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name,
+				formatLabels(h.labels, s.labelValues, []string{"le", fmt.Sprintf("%g", bound)}), s.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, []string{"le", "+Inf"}), s.count)
+		fmt.Fprintf(b, "%s_sum%s %g\n", h.name, formatLabels(h.labels, s.labelValues, nil), s.sum)
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, formatLabels(h.labels, s.labelValues, nil), s.count)
+	}
+}
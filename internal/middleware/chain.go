@@ -0,0 +1,15 @@
+package middleware
+
+import "net/http"
+
+// Middleware оборачивает HTTP обработчик
+type Middleware func(http.Handler) http.Handler
+
+// Chain применяет middleware к обработчику так, что первый в списке
+// выполняется первым (оказывается внешним)
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
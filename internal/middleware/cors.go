@@ -0,0 +1,64 @@
+package middleware
+
+import "net/http"
+
+const (
+	corsAllowMethods = "GET, POST, DELETE, OPTIONS"
+	// corsAllowHeaders заголовки запроса: X-Admin-Token для административных
+	// эндпоинтов, If-None-Match для условного GET заказа
+	corsAllowHeaders = "Content-Type, X-Request-ID, Idempotency-Key, X-Admin-Token, If-None-Match"
+	// corsExposeHeaders заголовки ответа API, которые без этого браузер скрыл
+	// бы от скрипта с другого источника
+	corsExposeHeaders = "ETag, X-Request-ID, X-Stale, X-Result-Truncated, Idempotent-Replayed"
+	corsMaxAge        = "600"
+)
+
+// CORS добавляет CORS-заголовки для запросов с разрешенных источников.
+// Origin запроса возвращается только если он входит в allowedOrigins;
+// значение "*" разрешает любой источник. Пустой список отключает CORS.
+func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	allowAll := false
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		allowed[origin] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(allowed) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			_, ok := allowed[origin]
+			if !ok && !allowAll {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", corsExposeHeaders)
+
+			// Preflight-запрос
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+				w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				w.Header().Set("Access-Control-Max-Age", corsMaxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// okHandler отвечает 200 и считает вызовы
+type okHandler struct {
+	calls int
+}
+
+func (h *okHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	h.calls++
+	w.WriteHeader(http.StatusOK)
+}
+
+func corsRequest(method, origin string) *http.Request {
+	r := httptest.NewRequest(method, "/order/b563feb7b2b84b6test", nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	return r
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	next := &okHandler{}
+	h := CORS([]string{"https://a.example", "https://b.example"})(next)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, corsRequest(http.MethodGet, "https://b.example"))
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://b.example" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+	if got := rec.Header().Values("Vary"); !slices.Contains(got, "Origin") {
+		t.Errorf("Vary = %v, want Origin", got)
+	}
+	if rec.Code != http.StatusOK || next.calls != 1 {
+		t.Errorf("status %d, handler calls %d; want 200 and 1", rec.Code, next.calls)
+	}
+	exposed := rec.Header().Get("Access-Control-Expose-Headers")
+	for _, header := range []string{"ETag", "X-Request-ID", "X-Stale", "X-Result-Truncated"} {
+		if !listContains(exposed, header) {
+			t.Errorf("Access-Control-Expose-Headers = %q, want %s", exposed, header)
+		}
+	}
+}
+
+// listContains проверяет, что в списке заголовков через запятую есть name
+func listContains(list, name string) bool {
+	for _, item := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(item), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	next := &okHandler{}
+	h := CORS([]string{"https://a.example"})(next)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, corsRequest(http.MethodGet, "https://evil.example"))
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "" {
+		t.Errorf("Access-Control-Expose-Headers = %q, want none", got)
+	}
+	if next.calls != 1 {
+		t.Errorf("handler calls = %d, want 1", next.calls)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	next := &okHandler{}
+	h := CORS([]string{"https://a.example"})(next)
+
+	r := corsRequest(http.MethodOptions, "https://a.example")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", rec.Code)
+	}
+	if next.calls != 0 {
+		t.Errorf("preflight reached the handler %d times, want 0", next.calls)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://a.example",
+		"Access-Control-Allow-Methods": corsAllowMethods,
+		"Access-Control-Allow-Headers": corsAllowHeaders,
+		"Access-Control-Max-Age":       corsMaxAge,
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	// Административные эндпоинты и условный GET шлют собственные заголовки
+	allowed := rec.Header().Get("Access-Control-Allow-Headers")
+	for _, header := range []string{"Content-Type", "X-Admin-Token", "If-None-Match", "Idempotency-Key"} {
+		if !listContains(allowed, header) {
+			t.Errorf("Access-Control-Allow-Headers = %q, want %s", allowed, header)
+		}
+	}
+
+	// Preflight с чужого источника не обрабатывается
+	r = corsRequest(http.MethodOptions, "https://evil.example")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Header().Get("Access-Control-Allow-Methods") != "" || next.calls != 1 {
+		t.Errorf("disallowed preflight was answered: headers %v", rec.Header())
+	}
+}
+
+func TestCORSDisabledByDefault(t *testing.T) {
+	for _, origins := range [][]string{nil, {}} {
+		rec := httptest.NewRecorder()
+		CORS(origins)(&okHandler{}).ServeHTTP(rec, corsRequest(http.MethodGet, "https://a.example"))
+		if len(rec.Header()) != 0 {
+			t.Errorf("CORS(%q) set headers %v, want none", origins, rec.Header())
+		}
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	rec := httptest.NewRecorder()
+	CORS([]string{"*"})(&okHandler{}).ServeHTTP(rec, corsRequest(http.MethodGet, "https://any.example"))
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://any.example" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the request origin", got)
+	}
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultGzipMinSize размер ответа, начиная с которого он сжимается: меньшие
+// ответы почти не выигрывают от сжатия, а заголовок gzip добавляет ~20 байт
+const DefaultGzipMinSize = 1024
+
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// Gzip сжимает ответы, если клиент принимает Accept-Encoding: gzip, а тело не
+// меньше minSize байт. Тело накапливается до minSize, поэтому решение о сжатии
+// принимается по фактическому размеру; Flush до этого момента (потоковые
+// ответы) включает сжатие сразу. Не сжимаются ответы с уже заданным
+// Content-Encoding, частичные (206), без тела и с уже сжатыми форматами
+// (изображения, архивы). minSize 0 означает DefaultGzipMinSize.
+func Gzip(minSize int) Middleware {
+	if minSize <= 0 {
+		minSize = DefaultGzipMinSize
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+			defer gw.finish()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip проверяет, что gzip есть в Accept-Encoding и не запрещен q=0
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter откладывает заголовки и начало тела, пока не станет ясно,
+// сжимать ли ответ
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	// wroteHeader обработчик вызвал WriteHeader или Write
+	wroteHeader bool
+	// decided заголовки отправлены клиенту, buf больше не используется
+	decided bool
+	buf     []byte
+	gz      *gzip.Writer
+}
+
+// WriteHeader запоминает код ответа; ответы, которые не сжимаются, отправляются сразу
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	// Информационные ответы (1xx) не завершают заголовки
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	if !w.compressible() {
+		w.decide(false)
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush отправляет накопленное тело клиенту. Потоковый ответ сжимается сразу,
+// не дожидаясь minSize.
+func (w *gzipResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if err := w.start(true); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return
+		}
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap возвращает исходный writer для http.ResponseController
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish дописывает короткий ответ без сжатия или завершает поток gzip
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		if !w.wroteHeader {
+			// Обработчик ничего не записал: net/http сам отправит 200 без тела
+			return
+		}
+		_ = w.start(false)
+	}
+	if w.gz != nil {
+		_ = w.gz.Close()
+		w.gz.Reset(nil)
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+// start отправляет заголовки и накопленное тело; сжатие включается, только
+// если want и ответ допускает сжатие
+func (w *gzipResponseWriter) start(want bool) error {
+	header := w.Header()
+	// Без явного Content-Type net/http определил бы его по уже сжатым байтам
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	w.decide(want && w.compressible())
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// decide отправляет заголовки ответа, сжатого или нет
+func (w *gzipResponseWriter) decide(compress bool) {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		// Длина несжатого тела больше не верна
+		header.Del("Content-Length")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// compressible проверяет по коду и заголовкам, имеет ли смысл сжимать ответ
+func (w *gzipResponseWriter) compressible() bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	return compressibleType(header.Get("Content-Type"))
+}
+
+// compressibleType отсеивает форматы, которые уже сжаты
+func compressibleType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "font/woff"):
+		return false
+	}
+	switch mediaType {
+	case "application/gzip", "application/x-gzip", "application/zip",
+		"application/zstd", "application/x-bzip2", "application/x-7z-compressed",
+		"application/pdf", "application/octet-stream":
+		return false
+	}
+	return true
+}
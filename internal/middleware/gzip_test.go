@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/testutil"
+)
+
+// bodyHandler отвечает телом body с типом contentType; status 0 означает 200
+func bodyHandler(contentType string, status int, body []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if status != 0 {
+			w.WriteHeader(status)
+		}
+		w.Write(body)
+	})
+}
+
+// serveGzip выполняет запрос через Gzip(minSize) с заголовком Accept-Encoding
+func serveGzip(minSize int, acceptEncoding string, next http.Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/order/b563feb7b2b84b6test", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	Gzip(minSize)(next).ServeHTTP(rec, req)
+	return rec
+}
+
+// responseBody возвращает тело ответа, распаковывая его, если оно сжато
+func responseBody(t *testing.T, rec *httptest.ResponseRecorder) []byte {
+	t.Helper()
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		return rec.Body.Bytes()
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("decompress body: %v", err)
+	}
+	return body
+}
+
+// largeOrderJSON заказ с большим числом товаров, заведомо длиннее DefaultGzipMinSize
+func largeOrderJSON(t *testing.T) []byte {
+	t.Helper()
+	order := testutil.Order("b563feb7b2b84b6test")
+	for i := 1; i < 50; i++ {
+		item := order.Items[0]
+		item.ChrtID += i
+		order.Items = append(order.Items, item)
+	}
+	data, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("marshal order: %v", err)
+	}
+	return data
+}
+
+func TestGzipNegotiation(t *testing.T) {
+	body := largeOrderJSON(t)
+	tests := []struct {
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{acceptEncoding: "", wantGzip: false},
+		{acceptEncoding: "gzip", wantGzip: true},
+		{acceptEncoding: "deflate, GZIP", wantGzip: true},
+		{acceptEncoding: "gzip;q=0.5", wantGzip: true},
+		{acceptEncoding: "*", wantGzip: true},
+		{acceptEncoding: "gzip;q=0", wantGzip: false},
+		{acceptEncoding: "identity", wantGzip: false},
+		{acceptEncoding: "br, deflate", wantGzip: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			rec := serveGzip(0, tt.acceptEncoding, bodyHandler("application/json", 0, body))
+
+			if gzipped := rec.Header().Get("Content-Encoding") == "gzip"; gzipped != tt.wantGzip {
+				t.Errorf("Content-Encoding = %q, want gzip %v", rec.Header().Get("Content-Encoding"), tt.wantGzip)
+			}
+			if tt.wantGzip && rec.Body.Len() >= len(body) {
+				t.Errorf("gzipped body is %d bytes, want less than %d", rec.Body.Len(), len(body))
+			}
+			if got := responseBody(t, rec); !bytes.Equal(got, body) {
+				t.Errorf("body = %.80q..., want the order JSON", got)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+		})
+	}
+}
+
+func TestGzipMinSize(t *testing.T) {
+	const minSize = 64
+	tests := []struct {
+		name     string
+		minSize  int
+		writes   []int
+		wantGzip bool
+	}{
+		{name: "below min size", minSize: minSize, writes: []int{minSize - 1}},
+		{name: "at min size", minSize: minSize, writes: []int{minSize}, wantGzip: true},
+		{name: "small writes reaching min size", minSize: minSize, writes: []int{10, 20, 34}, wantGzip: true},
+		{name: "small writes below min size", minSize: minSize, writes: []int{10, 20, 33}},
+		{name: "default min size", writes: []int{DefaultGzipMinSize - 1}},
+		{name: "empty body", minSize: minSize, writes: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []byte
+			rec := serveGzip(tt.minSize, "gzip", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				for _, n := range tt.writes {
+					chunk := []byte(strings.Repeat("a", n))
+					want = append(want, chunk...)
+					w.Write(chunk)
+				}
+			}))
+
+			if gzipped := rec.Header().Get("Content-Encoding") == "gzip"; gzipped != tt.wantGzip {
+				t.Errorf("Content-Encoding = %q, want gzip %v", rec.Header().Get("Content-Encoding"), tt.wantGzip)
+			}
+			if got := responseBody(t, rec); !bytes.Equal(got, want) {
+				t.Errorf("body = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGzipSkipsIncompressible(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 2*DefaultGzipMinSize)
+	tests := []struct {
+		name string
+		next http.Handler
+	}{
+		{name: "image", next: bodyHandler("image/png", 0, body)},
+		{name: "archive", next: bodyHandler("application/zip", 0, body)},
+		{name: "partial content", next: bodyHandler("text/plain", http.StatusPartialContent, body)},
+		{name: "already encoded", next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			w.Write(body)
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveGzip(0, "gzip", tt.next)
+
+			if got := rec.Header().Get("Content-Encoding"); got == "gzip" {
+				t.Error("incompressible response was gzipped")
+			}
+			if !bytes.Equal(rec.Body.Bytes(), body) {
+				t.Errorf("body is %d bytes, want the original %d", rec.Body.Len(), len(body))
+			}
+		})
+	}
+}
+
+func TestGzipNotModified(t *testing.T) {
+	rec := serveGzip(0, "gzip", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q on 304, want none", got)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("304 body = %q, want empty", rec.Body.String())
+	}
+}
+
+func TestGzipFlushCompressesStream(t *testing.T) {
+	rec := serveGzip(0, "gzip", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte("{\"order_uid\":\"a\"}\n"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("{\"order_uid\":\"b\"}\n"))
+	}))
+
+	// Поток сжимается сразу при Flush, хотя тело меньше DefaultGzipMinSize
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if !rec.Flushed {
+		t.Error("Flush was not passed to the underlying writer")
+	}
+	if got, want := string(responseBody(t, rec)), "{\"order_uid\":\"a\"}\n{\"order_uid\":\"b\"}\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestGzipDetectsContentType(t *testing.T) {
+	body := []byte("<html><body>" + strings.Repeat("order ", DefaultGzipMinSize) + "</body></html>")
+	rec := serveGzip(0, "gzip", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+
+	// Тип определяется по несжатому телу, а не по байтам gzip
+	if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/html; charset=utf-8", got)
+	}
+	if got := responseBody(t, rec); !bytes.Equal(got, body) {
+		t.Error("decompressed body differs from the original")
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"go-kafka-postgres/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// AccessLog пишет в лог метод, путь, код ответа и длительность каждого запроса
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r)
+
+		logger.Info("HTTP request",
+			zap.String("request_id", RequestIDFromContext(r.Context())),
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", rw.Status()),
+			zap.Int("bytes", rw.bytes),
+			zap.Duration("duration", time.Since(start)),
+		)
+	})
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-kafka-postgres/internal/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLogs перенаправляет логгер в память до конца теста
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	previous := logger.Logger
+	logger.Logger = zap.New(core)
+	t.Cleanup(func() { logger.Logger = previous })
+	return logs
+}
+
+func TestRecovery(t *testing.T) {
+	logs := observeLogs(t)
+	h := Chain(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("nil map write")
+	}), RequestID, Recovery)
+
+	req := httptest.NewRequest(http.MethodGet, "/order/b563feb7b2b84b6test", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	entries := logs.FilterMessage("Panic in HTTP handler").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d panics, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["panic"] != "nil map write" || fields["request_id"] != "req-1" || fields["path"] != "/order/b563feb7b2b84b6test" {
+		t.Errorf("panic log fields = %v", fields)
+	}
+	if stack, _ := fields["stack"].(string); !strings.Contains(stack, "TestRecovery") {
+		t.Errorf("stack does not point at the panicking handler: %q", stack)
+	}
+}
+
+func TestRecoveryRepanicsAbortHandler(t *testing.T) {
+	observeLogs(t)
+	h := Recovery(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler to propagate", rec)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestAccessLog(t *testing.T) {
+	logs := observeLogs(t)
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}), RequestID, AccessLog)
+
+	req := httptest.NewRequest(http.MethodPost, "/order", nil)
+	req.Header.Set(RequestIDHeader, "req-2")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.FilterMessage("HTTP request").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d requests, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["method"] != "POST" || fields["path"] != "/order" || fields["request_id"] != "req-2" {
+		t.Errorf("access log fields = %v", fields)
+	}
+	if fields["status"] != int64(http.StatusCreated) || fields["bytes"] != int64(15) {
+		t.Errorf("status/bytes = %v/%v, want 201/15", fields["status"], fields["bytes"])
+	}
+	if _, ok := fields["duration"]; !ok {
+		t.Error("access log has no duration")
+	}
+}
+
+func TestAccessLogDefaultStatus(t *testing.T) {
+	logs := observeLogs(t)
+	h := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if status := logs.All()[0].ContextMap()["status"]; status != int64(http.StatusOK) {
+		t.Errorf("status = %v, want 200 without an explicit WriteHeader", status)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "from header", incoming: "req-3", keep: true},
+		{name: "generated", incoming: ""},
+		{name: "too long", incoming: strings.Repeat("x", maxRequestIDLength+1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(RequestIDHeader)
+			if got == "" || got != seen {
+				t.Fatalf("response id %q, context id %q, want the same non-empty id", got, seen)
+			}
+			if (got == tt.incoming) != tt.keep {
+				t.Errorf("request id = %q for incoming %q", got, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	wrap := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	Chain(&okHandler{}, wrap("first"), wrap("second")).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("middleware order = %v, want [first second]", order)
+	}
+}
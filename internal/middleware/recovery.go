@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"go-kafka-postgres/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// Recovery перехватывает панику в обработчике, пишет стек в лог и отвечает 500
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				// http.ErrAbortHandler используется для намеренного обрыва ответа
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				logger.Error("Panic in HTTP handler",
+					zap.String("request_id", RequestIDFromContext(r.Context())),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Any("panic", rec),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import "net/http"
+
+// responseWriter запоминает код ответа, записанный обработчиком.
+// Если обработчик не вызвал WriteHeader, код считается равным 200.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+// newResponseWriter оборачивает http.ResponseWriter
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader запоминает код ответа; повторные вызовы игнорируются, как и в net/http
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write записывает тело ответа; без явного WriteHeader код ответа — 200
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Status возвращает записанный код ответа
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+// Flush передает Flush исходному writer, если он его поддерживает
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Unwrap возвращает исходный writer для http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWriterRecordsStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rw := newResponseWriter(rec)
+
+	rw.WriteHeader(http.StatusNotFound)
+	rw.WriteHeader(http.StatusInternalServerError)
+	n, err := rw.Write([]byte("not found"))
+
+	if err != nil || n != 9 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if rw.Status() != http.StatusNotFound || rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d (written %d), want the first WriteHeader 404", rw.Status(), rec.Code)
+	}
+	if rw.bytes != 9 {
+		t.Errorf("bytes = %d, want 9", rw.bytes)
+	}
+}
+
+func TestResponseWriterDefaultStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*responseWriter)
+	}{
+		{name: "write without WriteHeader", write: func(w *responseWriter) { _, _ = w.Write([]byte("ok")) }},
+		{name: "flush without WriteHeader", write: func(w *responseWriter) { w.Flush() }},
+		{name: "nothing written", write: func(*responseWriter) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rw := newResponseWriter(rec)
+			tt.write(rw)
+			if rw.Status() != http.StatusOK || rec.Code != http.StatusOK {
+				t.Errorf("status = %d (written %d), want 200", rw.Status(), rec.Code)
+			}
+		})
+	}
+}
+
+func TestResponseWriterUnwrap(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if got := newResponseWriter(rec).Unwrap(); got != rec {
+		t.Errorf("Unwrap = %T, want the original writer", got)
+	}
+}
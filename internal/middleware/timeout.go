@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+)
+
+// timeoutBody тело ответа 503 при превышении времени обработки, в формате ошибок API
+const timeoutBody = `{"error":"Request timed out","code":"timeout"}`
+
+// Timeout ограничивает общее время обработки запроса: если обработчик не успел
+// ответить за timeout, клиент получает 503, а контекст запроса отменяется.
+// Ответ буферизуется до завершения обработчика, поэтому потоковые пути
+// (exclude, точное совпадение) обслуживаются без ограничения. timeout 0 отключает middleware.
+func Timeout(timeout time.Duration, exclude ...string) Middleware {
+	excluded := make(map[string]struct{}, len(exclude))
+	for _, path := range exclude {
+		excluded[path] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		limited := http.TimeoutHandler(next, timeout, timeoutBody)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := excluded[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(timeoutResponseWriter{w}, r)
+		})
+	}
+}
+
+// timeoutResponseWriter задает Content-Type ответа 503, который TimeoutHandler
+// пишет без заголовков. Ответы обработчика передаются без изменений.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w timeoutResponseWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap возвращает исходный writer для http.ResponseController
+func (w timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
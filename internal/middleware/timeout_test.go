@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// slowHandler отвечает через delay или завершается раньше, если запрос отменен;
+// canceled закрывается при отмене контекста запроса
+func slowHandler(delay time.Duration, canceled chan<- struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("done"))
+		case <-r.Context().Done():
+			close(canceled)
+		}
+	})
+}
+
+func TestTimeoutSlowHandler(t *testing.T) {
+	canceled := make(chan struct{})
+	h := Timeout(20 * time.Millisecond)(slowHandler(time.Second, canceled))
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/order/b563feb7b2b84b6test", nil))
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request took %v, want the 20ms timeout to end it", elapsed)
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body struct{ Error, Code string }
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("timeout body %q is not JSON: %v", rec.Body, err)
+	}
+	if body.Code != "timeout" || body.Error == "" {
+		t.Errorf("timeout body = %+v, want code timeout with a message", body)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("request context was not canceled after the timeout")
+	}
+}
+
+func TestTimeoutFastHandler(t *testing.T) {
+	h := Timeout(time.Second)(slowHandler(0, make(chan struct{})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/order/b563feb7b2b84b6test", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "done" {
+		t.Errorf("response = %d %q, want 200 done", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("Content-Type = %q, want the handler's text/plain", ct)
+	}
+}
+
+func TestTimeoutNotApplied(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		path    string
+	}{
+		{name: "excluded path", timeout: 20 * time.Millisecond, path: "/orders/stream"},
+		{name: "disabled", path: "/order/b563feb7b2b84b6test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Timeout(tt.timeout, "/orders/stream")(slowHandler(50*time.Millisecond, make(chan struct{})))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusOK || rec.Body.String() != "done" {
+				t.Errorf("response = %d %q, want the handler to finish without a timeout", rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -1,10 +1,7 @@
 package model
 
-import (
-	"time"
-)
-
 type Order struct {
+	Version           int       `json:"version,omitempty"`
 	OrderUID          string    `json:"order_uid"`
 	TrackNumber       string    `json:"track_number"`
 	Entry             string    `json:"entry"`
@@ -17,7 +14,7 @@ type Order struct {
 	DeliveryService   string    `json:"delivery_service"`
 	Shardkey          string    `json:"shardkey"`
 	SmID              int       `json:"sm_id"`
-	DateCreated       time.Time `json:"date_created"`
+	DateCreated       Timestamp `json:"date_created"`
 	OofShard          string    `json:"oof_shard"`
 }
 
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// populatedOrder заказ, в котором заполнено каждое поле; date_created не в UTC
+// и точнее микросекунды, как могло бы прийти от продюсера
+func populatedOrder() Order {
+	return Order{
+		Version:     1,
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			RequestID:    "req-1",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    10,
+		},
+		Items: []Item{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Rid: "ab4219087a764ae0btest", Name: "Mascaras",
+				Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202},
+			{ChrtID: 9934931, TrackNumber: "WBILMTESTTRACK", Price: 100, Rid: "ab4219087a764ae0btes2", Name: "Brush",
+				Sale: 10, Size: "M", TotalPrice: 90, NmID: 2389213, Brand: "Vivienne Sabo", Status: 203},
+		},
+		Locale:            "en",
+		InternalSignature: "signature",
+		CustomerID:        "test",
+		DeliveryService:   "meest",
+		Shardkey:          "9",
+		SmID:              99,
+		DateCreated:       Timestamp{Time: time.Date(2021, 11, 26, 9, 22, 19, 123456789, time.FixedZone("MSK", 3*60*60))},
+		OofShard:          "1",
+	}
+}
+
+// zeroFields возвращает пути незаполненных полей value, чтобы новое поле модели
+// не выпало из проверки круговой сериализации незамеченным
+func zeroFields(value reflect.Value, path string) []string {
+	switch value.Kind() {
+	case reflect.Struct:
+		if timestamp, ok := value.Interface().(Timestamp); ok {
+			if timestamp.IsZero() {
+				return []string{path}
+			}
+			return nil
+		}
+		var zero []string
+		for i := range value.NumField() {
+			zero = append(zero, zeroFields(value.Field(i), path+"."+value.Type().Field(i).Name)...)
+		}
+		return zero
+	case reflect.Slice:
+		if value.Len() == 0 {
+			return []string{path}
+		}
+		var zero []string
+		for i := range value.Len() {
+			zero = append(zero, zeroFields(value.Index(i), path+"[]")...)
+		}
+		return zero
+	default:
+		if value.IsZero() {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+func TestOrderJSONRoundTrip(t *testing.T) {
+	order := populatedOrder()
+	if zero := zeroFields(reflect.ValueOf(order), "Order"); len(zero) > 0 {
+		t.Fatalf("test order leaves fields empty: %v", zero)
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got Order
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	// date_created приводится к UTC и точности PostgreSQL; остальные поля без изменений
+	want := populatedOrder()
+	want.DateCreated = Timestamp{Time: time.Date(2021, 11, 26, 6, 22, 19, 123456000, time.UTC)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the order:\n got %+v\nwant %+v", got, want)
+	}
+
+	// Повторная сериализация стабильна: заказ из сообщения, кэша и БД кодируется одинаково
+	again, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var twice Order
+	if err := json.Unmarshal(again, &twice); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(twice, want) {
+		t.Errorf("second round trip changed the order:\n got %+v\nwant %+v", twice, want)
+	}
+}
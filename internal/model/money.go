@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// minorUnitDigits число знаков дробной части для валют, у которых оно отличается от 2
+var minorUnitDigits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+// Money денежная сумма в минимальных единицах валюты (копейках, центах)
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// NewMoney создает сумму в минимальных единицах указанной валюты
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}
+}
+
+// digits возвращает число знаков дробной части валюты
+func (m Money) digits() int {
+	if digits, ok := minorUnitDigits[m.Currency]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Decimal форматирует сумму десятичной строкой, например 12345 копеек — "123.45"
+func (m Money) Decimal() string {
+	digits := m.digits()
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+
+	scale := int64(1)
+	for range digits {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, digits, minor%scale)
+}
+
+// String возвращает сумму с кодом валюты, например "123.45 RUB"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// MarshalJSON кодирует сумму как {"amount":"123.45","currency":"RUB"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{Amount: m.Decimal(), Currency: m.Currency})
+}
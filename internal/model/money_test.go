@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: NewMoney(12345, "rub"), want: `{"amount":"123.45","currency":"RUB"}`},
+		{money: NewMoney(12345, "USD"), want: `{"amount":"123.45","currency":"USD"}`},
+		{money: NewMoney(5, "EUR"), want: `{"amount":"0.05","currency":"EUR"}`},
+		{money: NewMoney(-150, "USD"), want: `{"amount":"-1.50","currency":"USD"}`},
+		{money: NewMoney(12345, "JPY"), want: `{"amount":"12345","currency":"JPY"}`},
+		{money: NewMoney(12345, "KWD"), want: `{"amount":"12.345","currency":"KWD"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal(%+v) = %s, want %s", tt.money, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	if got := NewMoney(12345, "rub").String(); got != "123.45 RUB" {
+		t.Errorf("String = %q, want 123.45 RUB", got)
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// epochMillisThreshold значения по модулю не меньше этого считаются
+// миллисекундами: 1e12 секунд — это 33658 год, а 1e12 миллисекунд — 2001 год
+const epochMillisThreshold = 1e12
+
+// TimestampPrecision точность хранения timestamptz в PostgreSQL
+const TimestampPrecision = time.Microsecond
+
+// Timestamp время, которое при разборе JSON принимает как строку RFC3339,
+// так и число — Unix-время в секундах или миллисекундах. Кодируется в JSON
+// строкой RFC3339, как time.Time.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp приводит время к UTC и точности PostgreSQL. Так заказ из
+// сообщения, из кэша и прочитанный из БД имеет одно и то же значение
+// date_created, и JSON-представление заказа не зависит от источника.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t.UTC().Truncate(TimestampPrecision)}
+}
+
+// UnmarshalJSON разбирает строку RFC3339 или число секунд/миллисекунд с начала
+// эпохи; результат приводится NewTimestamp
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var parsed time.Time
+		if err := parsed.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*t = NewTimestamp(parsed)
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("timestamp must be an RFC3339 string or a Unix epoch number: %s", data)
+	}
+	if epoch, err := number.Int64(); err == nil {
+		if epoch >= epochMillisThreshold || epoch <= -epochMillisThreshold {
+			*t = NewTimestamp(time.UnixMilli(epoch))
+		} else {
+			*t = NewTimestamp(time.Unix(epoch, 0))
+		}
+		return nil
+	}
+
+	// Дробное число секунд, например 1700000000.25
+	seconds, err := number.Float64()
+	if err != nil || math.IsInf(seconds, 0) || math.Abs(seconds) >= epochMillisThreshold {
+		return fmt.Errorf("invalid epoch timestamp: %s", data)
+	}
+	whole, frac := math.Modf(seconds)
+	*t = NewTimestamp(time.Unix(int64(whole), int64(math.Round(frac*1e9))))
+	return nil
+}
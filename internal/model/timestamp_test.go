@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	want := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		json string
+		want time.Time
+	}{
+		{name: "rfc3339 utc", json: `"2021-11-26T06:22:19Z"`, want: want},
+		{name: "rfc3339 offset", json: `"2021-11-26T09:22:19+03:00"`, want: want},
+		{name: "rfc3339 nanoseconds truncated", json: `"2021-11-26T06:22:19.123456789Z"`,
+			want: want.Add(123456 * time.Microsecond)},
+		{name: "epoch seconds", json: `1637907739`, want: want},
+		{name: "epoch millis", json: `1637907739000`, want: want},
+		{name: "epoch millis with fraction of second", json: `1637907739250`, want: want.Add(250 * time.Millisecond)},
+		{name: "fractional seconds", json: `1637907739.25`, want: want.Add(250 * time.Millisecond)},
+		{name: "exponent seconds", json: `1.637907739e9`, want: want},
+		{name: "before epoch", json: `-86400`, want: time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "zero", json: `0`, want: time.Unix(0, 0).UTC()},
+		{name: "null", json: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Timestamp
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.json, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.json, got.Time, tt.want)
+			}
+			if !got.IsZero() && got.Location() != time.UTC {
+				t.Errorf("Unmarshal(%s) location = %v, want UTC", tt.json, got.Location())
+			}
+		})
+	}
+}
+
+func TestTimestampUnmarshalJSONErrors(t *testing.T) {
+	for _, input := range []string{
+		`"2021-11-26"`,
+		`"yesterday"`,
+		`true`,
+		`{}`,
+		`1e13`,
+		`1e400`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			var got Timestamp
+			if err := json.Unmarshal([]byte(input), &got); err == nil {
+				t.Errorf("Unmarshal(%s) = %v, want error", input, got.Time)
+			}
+		})
+	}
+}
+
+func TestTimestampMarshalJSON(t *testing.T) {
+	ts := NewTimestamp(time.Date(2021, 11, 26, 9, 22, 19, 1500, time.FixedZone("MSK", 3*60*60)))
+
+	got, err := json.Marshal(ts)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := `"2021-11-26T06:22:19.000001Z"`; string(got) != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+}
+
+func TestOrderNumericDateCreated(t *testing.T) {
+	var order Order
+	if err := json.Unmarshal([]byte(`{"order_uid":"b563feb7b2b84b6test","date_created":1637907739000}`), &order); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if want := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC); !order.DateCreated.Equal(want) {
+		t.Errorf("date_created = %v, want %v", order.DateCreated.Time, want)
+	}
+}
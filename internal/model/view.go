@@ -0,0 +1,47 @@
+package model
+
+// OrderView заказ с вычисляемыми полями для отображения на фронтенде
+type OrderView struct {
+	*Order
+	// ItemCount число товаров в заказе
+	ItemCount int `json:"item_count"`
+	// ItemsTotal сумма total_price всех товаров
+	ItemsTotal int `json:"items_total"`
+	// AmountReconciled сходится ли payment.amount с goods_total + delivery_cost
+	AmountReconciled bool `json:"amount_reconciled"`
+	// Totals суммы оплаты в однозначном десятичном виде
+	Totals PaymentTotals `json:"totals"`
+}
+
+// PaymentTotals суммы оплаты заказа как Money. Суммы в Payment хранятся
+// целыми числами в минимальных единицах валюты.
+type PaymentTotals struct {
+	Amount       Money `json:"amount"`
+	GoodsTotal   Money `json:"goods_total"`
+	DeliveryCost Money `json:"delivery_cost"`
+	CustomFee    Money `json:"custom_fee"`
+	ItemsTotal   Money `json:"items_total"`
+}
+
+// NewOrderView вычисляет производные поля заказа
+func NewOrderView(order *Order) OrderView {
+	itemsTotal := 0
+	for _, item := range order.Items {
+		itemsTotal += item.TotalPrice
+	}
+
+	currency := order.Payment.Currency
+	return OrderView{
+		Order:            order,
+		ItemCount:        len(order.Items),
+		ItemsTotal:       itemsTotal,
+		AmountReconciled: order.Payment.Amount == order.Payment.GoodsTotal+order.Payment.DeliveryCost,
+		Totals: PaymentTotals{
+			Amount:       NewMoney(int64(order.Payment.Amount), currency),
+			GoodsTotal:   NewMoney(int64(order.Payment.GoodsTotal), currency),
+			DeliveryCost: NewMoney(int64(order.Payment.DeliveryCost), currency),
+			CustomFee:    NewMoney(int64(order.Payment.CustomFee), currency),
+			ItemsTotal:   NewMoney(int64(itemsTotal), currency),
+		},
+	}
+}
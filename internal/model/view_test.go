@@ -0,0 +1,56 @@
+package model_test
+
+import (
+	"testing"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestNewOrderView(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	second := order.Items[0]
+	second.ChrtID = 9934931
+	second.TotalPrice = 183
+	order.Items = append(order.Items, second)
+	order.Payment.GoodsTotal = 500
+	order.Payment.Amount = 2000
+
+	view := model.NewOrderView(order)
+
+	if view.Order != order {
+		t.Error("view does not embed the original order")
+	}
+	if view.ItemCount != 2 || view.ItemsTotal != 500 {
+		t.Errorf("item count/total = %d/%d, want 2/500", view.ItemCount, view.ItemsTotal)
+	}
+	if !view.AmountReconciled {
+		t.Error("amount 2000 = goods_total 500 + delivery_cost 1500 reported as not reconciled")
+	}
+	if got := view.Totals.Amount.String(); got != "20.00 USD" {
+		t.Errorf("totals.amount = %q, want 20.00 USD", got)
+	}
+	if got := view.Totals.ItemsTotal.String(); got != "5.00 USD" {
+		t.Errorf("totals.items_total = %q, want 5.00 USD", got)
+	}
+}
+
+func TestNewOrderViewNotReconciled(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	order.Payment.Amount++
+
+	if model.NewOrderView(order).AmountReconciled {
+		t.Errorf("amount %d with goods_total %d and delivery_cost %d reported as reconciled",
+			order.Payment.Amount, order.Payment.GoodsTotal, order.Payment.DeliveryCost)
+	}
+}
+
+func TestNewOrderViewWithoutItems(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	order.Items = nil
+
+	view := model.NewOrderView(order)
+	if view.ItemCount != 0 || view.ItemsTotal != 0 || view.Totals.ItemsTotal.Minor != 0 {
+		t.Errorf("view of an order without items = %+v", view)
+	}
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/model"
+)
+
+// fakeDB хранит заказы в памяти и считает обращения. Методы
+// DatabaseInterface, которые хранилище не вызывает, не реализованы.
+type fakeDB struct {
+	db.DatabaseInterface
+
+	mu     sync.Mutex
+	orders map[string]*model.Order
+	reads  int
+	writes int
+	// deleted мягко удаленные заказы: чтение их не возвращает, запись не восстанавливает
+	deleted map[string]bool
+	// err возвращается всеми запросами, если задана
+	err error
+}
+
+func newFakeDB(orders ...*model.Order) *fakeDB {
+	f := &fakeDB{orders: make(map[string]*model.Order), deleted: make(map[string]bool)}
+	for _, order := range orders {
+		f.orders[order.OrderUID] = order
+	}
+	return f
+}
+
+func (f *fakeDB) GetOrderByUID(_ context.Context, uid string) (*model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads++
+	if f.err != nil {
+		return nil, f.err
+	}
+	order, ok := f.orders[uid]
+	if !ok || f.deleted[uid] {
+		return nil, db.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (f *fakeDB) InsertOrder(_ context.Context, order *model.Order) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes++
+	if f.err != nil {
+		return f.err
+	}
+	if _, ok := f.orders[order.OrderUID]; !ok {
+		f.orders[order.OrderUID] = order
+	}
+	return f.deletedErr(order.OrderUID)
+}
+
+func (f *fakeDB) UpsertOrder(_ context.Context, order *model.Order) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writes++
+	if f.err != nil {
+		return f.err
+	}
+	f.orders[order.OrderUID] = order
+	return f.deletedErr(order.OrderUID)
+}
+
+// deletedErr возвращает ошибку записи мягко удаленного заказа, как Database
+func (f *fakeDB) deletedErr(uid string) error {
+	if f.deleted[uid] {
+		return db.ErrOrderDeleted
+	}
+	return nil
+}
+
+func (f *fakeDB) SoftDeleteOrder(_ context.Context, uid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.orders[uid]; !ok || f.deleted[uid] {
+		return db.ErrOrderNotFound
+	}
+	f.deleted[uid] = true
+	return nil
+}
+
+func (f *fakeDB) UndeleteOrder(_ context.Context, uid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.deleted[uid] {
+		return db.ErrOrderNotFound
+	}
+	delete(f.deleted, uid)
+	return nil
+}
+
+// Reads возвращает число запросов заказа по UID
+func (f *fakeDB) Reads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reads
+}
+
+// countingCache LRU-кэш, считающий вызовы Set
+type countingCache struct {
+	cache.Cache
+	sets int
+}
+
+func newCountingCache() *countingCache {
+	return &countingCache{Cache: cache.New(10)}
+}
+
+func (c *countingCache) Set(order *model.Order) {
+	c.sets++
+	c.Cache.Set(order)
+}
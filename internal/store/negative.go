@@ -0,0 +1,81 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// negativeCache запоминает UID отсутствующих заказов на ограниченное время.
+// Размер ограничен, при переполнении вытесняется самая старая запись.
+type negativeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type negativeEntry struct {
+	uid     string
+	expires time.Time
+}
+
+func newNegativeCache(ttl time.Duration, maxSize int) *negativeCache {
+	return &negativeCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Contains проверяет, что UID недавно не был найден
+func (n *negativeCache) Contains(uid string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	elem, ok := n.entries[uid]
+	if !ok {
+		return false
+	}
+	if time.Now().After(elem.Value.(*negativeEntry).expires) {
+		n.order.Remove(elem)
+		delete(n.entries, uid)
+		return false
+	}
+	return true
+}
+
+// Add запоминает отсутствующий UID
+func (n *negativeCache) Add(uid string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	expires := time.Now().Add(n.ttl)
+	if elem, ok := n.entries[uid]; ok {
+		elem.Value.(*negativeEntry).expires = expires
+		n.order.MoveToFront(elem)
+		return
+	}
+
+	if n.order.Len() >= n.maxSize {
+		if oldest := n.order.Back(); oldest != nil {
+			n.order.Remove(oldest)
+			delete(n.entries, oldest.Value.(*negativeEntry).uid)
+		}
+	}
+
+	n.entries[uid] = n.order.PushFront(&negativeEntry{uid: uid, expires: expires})
+}
+
+// Remove удаляет UID, например после сохранения заказа
+func (n *negativeCache) Remove(uid string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if elem, ok := n.entries[uid]; ok {
+		n.order.Remove(elem)
+		delete(n.entries, uid)
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestNegativeCacheSkipsRepeatedMisses(t *testing.T) {
+	database := newFakeDB()
+	s := New(newCountingCache(), database, Options{NegativeTTL: time.Minute, NegativeMaxSize: 10})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Get(ctx, "missing"); !errors.Is(err, db.ErrOrderNotFound) {
+			t.Fatalf("Get #%d = %v, want ErrOrderNotFound", i+1, err)
+		}
+	}
+	if reads := database.Reads(); reads != 1 {
+		t.Errorf("DB read %d times, want 1: repeated misses must not query the DB", reads)
+	}
+}
+
+func TestNegativeCacheDisabled(t *testing.T) {
+	database := newFakeDB()
+	s := New(nil, database, Options{})
+
+	for i := 0; i < 2; i++ {
+		_, _ = s.Get(context.Background(), "missing")
+	}
+	if reads := database.Reads(); reads != 2 {
+		t.Errorf("DB read %d times, want 2 without negative caching", reads)
+	}
+}
+
+func TestNegativeCacheForgetsSavedOrder(t *testing.T) {
+	database := newFakeDB()
+	s := New(nil, database, Options{NegativeTTL: time.Minute, NegativeMaxSize: 10})
+	ctx := context.Background()
+	order := testutil.Order("b563feb7b2b84b6test")
+
+	if _, err := s.Get(ctx, order.OrderUID); !errors.Is(err, db.ErrOrderNotFound) {
+		t.Fatalf("Get before Save = %v, want ErrOrderNotFound", err)
+	}
+	if err := s.Save(ctx, order); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, err := s.Get(ctx, order.OrderUID); err != nil || got != order {
+		t.Errorf("Get after Save = %v, %v; want the saved order", got, err)
+	}
+}
+
+func TestNegativeCacheTTL(t *testing.T) {
+	n := newNegativeCache(20*time.Millisecond, 10)
+	n.Add("missing")
+	if !n.Contains("missing") {
+		t.Fatal("Contains = false right after Add")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n.Contains("missing") {
+		t.Error("Contains = true after the TTL expired")
+	}
+}
+
+func TestNegativeCacheBounded(t *testing.T) {
+	n := newNegativeCache(time.Minute, 2)
+	n.Add("a")
+	n.Add("b")
+	n.Add("a") // обновление переносит "a" в начало
+	n.Add("c")
+
+	if n.Contains("b") {
+		t.Error("oldest entry b survived overflow")
+	}
+	if !n.Contains("a") || !n.Contains("c") {
+		t.Error("recent entries a and c were evicted")
+	}
+	if n.order.Len() != 2 || len(n.entries) != 2 {
+		t.Errorf("size = %d/%d, want 2", n.order.Len(), len(n.entries))
+	}
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestDeleteEvictsOrder(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := newCountingCache()
+	s := New(orderCache, newFakeDB(order), Options{})
+	ctx := context.Background()
+	if _, err := s.Get(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Get before Delete: %v", err)
+	}
+
+	if err := s.Delete(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := orderCache.Peek(order.OrderUID); ok {
+		t.Error("deleted order is still cached")
+	}
+	if _, err := s.Get(ctx, order.OrderUID); !errors.Is(err, db.ErrOrderNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrOrderNotFound", err)
+	}
+	if err := s.Delete(ctx, order.OrderUID); !errors.Is(err, db.ErrOrderNotFound) {
+		t.Errorf("second Delete error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestUndeleteCachesOrder(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	orderCache := newCountingCache()
+	s := New(orderCache, newFakeDB(order), Options{})
+	ctx := context.Background()
+	if err := s.Delete(ctx, order.OrderUID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	got, err := s.Undelete(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatalf("Undelete: %v", err)
+	}
+	if got.OrderUID != order.OrderUID {
+		t.Errorf("Undelete = %s, want %s", got.OrderUID, order.OrderUID)
+	}
+	if _, ok := orderCache.Peek(order.OrderUID); !ok {
+		t.Error("restored order is not cached")
+	}
+}
+
+func TestSaveDeletedOrderStaysHidden(t *testing.T) {
+	for _, upsert := range []bool{false, true} {
+		name := "insert"
+		if upsert {
+			name = "upsert"
+		}
+		t.Run(name, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			database := newFakeDB(order)
+			orderCache := newCountingCache()
+			s := New(orderCache, database, Options{Upsert: upsert, NegativeTTL: time.Minute, NegativeMaxSize: 10})
+			ctx := context.Background()
+			if err := s.Delete(ctx, order.OrderUID); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			// Промах запоминается негативным кэшем
+			if _, err := s.Get(ctx, order.OrderUID); !errors.Is(err, db.ErrOrderNotFound) {
+				t.Fatalf("Get after Delete error = %v, want ErrOrderNotFound", err)
+			}
+
+			// Повторная доставка удаленного заказа не ошибка, но и не восстановление
+			if err := s.Save(ctx, order); err != nil {
+				t.Fatalf("Save of a deleted order: %v", err)
+			}
+			if orderCache.sets != 0 {
+				t.Errorf("cache.Set called %d times for a deleted order", orderCache.sets)
+			}
+			reads := database.Reads()
+			if _, err := s.Get(ctx, order.OrderUID); !errors.Is(err, db.ErrOrderNotFound) {
+				t.Errorf("Get after redelivery error = %v, want ErrOrderNotFound", err)
+			}
+			if database.Reads() != reads {
+				t.Error("redelivery cleared the negative cache entry of a deleted order")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// staleCache хранит последние известные версии заказов дольше основного кэша,
+// чтобы отдавать их, когда БД недоступна. Размер ограничен, при переполнении
+// вытесняется давно не обновлявшаяся запись.
+type staleCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type staleEntry struct {
+	order   *model.Order
+	expires time.Time
+}
+
+func newStaleCache(ttl time.Duration, maxSize int) *staleCache {
+	return &staleCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get возвращает последнюю известную версию заказа, если она не устарела
+func (s *staleCache) Get(uid string) (*model.Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[uid]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*staleEntry)
+	if time.Now().After(entry.expires) {
+		s.order.Remove(elem)
+		delete(s.entries, uid)
+		return nil, false
+	}
+	return entry.order, true
+}
+
+// Set запоминает актуальную версию заказа
+func (s *staleCache) Set(order *model.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires := time.Now().Add(s.ttl)
+	if elem, ok := s.entries[order.OrderUID]; ok {
+		elem.Value = &staleEntry{order: order, expires: expires}
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	if s.order.Len() >= s.maxSize {
+		if oldest := s.order.Back(); oldest != nil {
+			s.order.Remove(oldest)
+			delete(s.entries, oldest.Value.(*staleEntry).order.OrderUID)
+		}
+	}
+
+	s.entries[order.OrderUID] = s.order.PushFront(&staleEntry{order: order, expires: expires})
+}
+
+// Remove удаляет заказ, которого больше нет в БД
+func (s *staleCache) Remove(uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[uid]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, uid)
+	}
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestStaleCacheTTL(t *testing.T) {
+	s := newStaleCache(20*time.Millisecond, 10)
+	s.Set(testutil.Order("a"))
+	if _, ok := s.Get("a"); !ok {
+		t.Fatal("Get = miss right after Set")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := s.Get("a"); ok {
+		t.Error("Get = hit after the TTL expired")
+	}
+}
+
+func TestStaleCacheBounded(t *testing.T) {
+	s := newStaleCache(time.Minute, 2)
+	s.Set(testutil.Order("a"))
+	s.Set(testutil.Order("b"))
+	s.Set(testutil.Order("a")) // обновление переносит "a" в начало
+	s.Set(testutil.Order("c"))
+
+	if _, ok := s.Get("b"); ok {
+		t.Error("oldest entry b survived overflow")
+	}
+	for _, uid := range []string{"a", "c"} {
+		if _, ok := s.Get(uid); !ok {
+			t.Errorf("recent entry %s was evicted", uid)
+		}
+	}
+	s.Remove("a")
+	if _, ok := s.Get("a"); ok {
+		t.Error("Get = hit after Remove")
+	}
+}
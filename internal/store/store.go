@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/logger"
+	"go-kafka-postgres/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// Options настройки хранилища заказов
+type Options struct {
+	// NegativeTTL время, в течение которого отсутствующий UID не запрашивается
+	// повторно из БД; 0 отключает негативное кэширование
+	NegativeTTL time.Duration
+	// NegativeMaxSize максимальное число запоминаемых отсутствующих UID
+	NegativeMaxSize int
+	// Upsert сохраняет повторно доставленные заказы как актуальную версию
+	// (UpsertOrder) вместо пропуска уже сохраненных строк (InsertOrder)
+	Upsert bool
+	// NoFillOnMiss не кладет в кэш заказы, прочитанные из БД при промахе:
+	// кэш содержит только записанные потребителем заказы и не вытесняет их
+	// при обращениях к старым заказам
+	NoFillOnMiss bool
+	// StaleTTL время хранения последних известных версий заказов для Stale;
+	// 0 отключает резервный кэш
+	StaleTTL time.Duration
+	// StaleMaxSize максимальное число заказов в резервном кэше
+	StaleMaxSize int
+}
+
+// ErrNoDatabase возвращается при записи в хранилище без БД
+var ErrNoDatabase = errors.New("database is not configured")
+
+// OrderStore объединяет кэш и БД и реализует для них политику write-through.
+// Кэш или БД могут быть nil: без кэша запросы идут напрямую в БД,
+// без БД хранилище работает только с кэшем.
+type OrderStore struct {
+	cache    cache.Cache
+	db       db.DatabaseInterface
+	negative *negativeCache
+	stale    *staleCache
+	upsert   bool
+	// fillOnMiss заполнять кэш заказами, прочитанными из БД в Get
+	fillOnMiss bool
+}
+
+// New создает хранилище заказов
+func New(cache cache.Cache, db db.DatabaseInterface, opts Options) *OrderStore {
+	s := &OrderStore{cache: cache, db: db, upsert: opts.Upsert, fillOnMiss: !opts.NoFillOnMiss}
+	if opts.NegativeTTL > 0 && opts.NegativeMaxSize > 0 {
+		s.negative = newNegativeCache(opts.NegativeTTL, opts.NegativeMaxSize)
+	}
+	if opts.StaleTTL > 0 && opts.StaleMaxSize > 0 {
+		s.stale = newStaleCache(opts.StaleTTL, opts.StaleMaxSize)
+	}
+	return s
+}
+
+// Get возвращает заказ из кэша, а при промахе — из БД с заполнением кэша
+// (если оно не отключено NoFillOnMiss)
+func (s *OrderStore) Get(ctx context.Context, uid string) (*model.Order, error) {
+	if s.cache != nil {
+		order, found, err := s.cache.GetCtx(ctx, uid)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			// Недоступный внешний кэш не должен мешать чтению из БД
+			logger.Warn("Cache read failed, falling back to DB", zap.String("order_uid", uid), zap.Error(err))
+		}
+		if found {
+			logger.Info("Order получен из кэша", zap.String("order_uid", uid))
+			s.rememberStale(order)
+			return order, nil
+		}
+	}
+
+	if s.db == nil {
+		return nil, db.ErrOrderNotFound
+	}
+
+	if s.negative != nil && s.negative.Contains(uid) {
+		return nil, db.ErrOrderNotFound
+	}
+
+	order, err := s.db.GetOrderByUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, db.ErrOrderNotFound) {
+			if s.negative != nil {
+				s.negative.Add(uid)
+			}
+			s.forgetStale(uid)
+		}
+		return nil, err
+	}
+
+	s.rememberStale(order)
+	if s.cache != nil && s.fillOnMiss {
+		s.cache.Set(order)
+	}
+	logger.Info("Order получен из базы данных", zap.String("order_uid", uid))
+	return order, nil
+}
+
+// Refresh перечитывает заказ из БД и перезаписывает его в кэше.
+// Если заказ отсутствует в БД, устаревшая запись удаляется из кэша.
+func (s *OrderStore) Refresh(ctx context.Context, uid string) (*model.Order, error) {
+	if s.db == nil {
+		return nil, ErrNoDatabase
+	}
+
+	order, err := s.db.GetOrderByUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, db.ErrOrderNotFound) {
+			if s.cache != nil {
+				s.cache.Delete(uid)
+			}
+			s.forgetStale(uid)
+		}
+		return nil, err
+	}
+
+	s.rememberStale(order)
+	if s.negative != nil {
+		s.negative.Remove(uid)
+	}
+	if s.cache != nil {
+		s.cache.Set(order)
+	}
+	return order, nil
+}
+
+// Save сохраняет заказ в БД и, при успехе, в кэш. Мягко удаленный заказ
+// повторная запись не восстанавливает, поэтому в кэш он не попадает.
+func (s *OrderStore) Save(ctx context.Context, order *model.Order) error {
+	if s.db == nil {
+		return ErrNoDatabase
+	}
+
+	write := s.db.InsertOrder
+	if s.upsert {
+		write = s.db.UpsertOrder
+	}
+	if err := write(ctx, order); err != nil {
+		if errors.Is(err, db.ErrOrderDeleted) {
+			logger.Info("Order is deleted, not caching", zap.String("order_uid", order.OrderUID))
+			return nil
+		}
+		return err
+	}
+
+	if s.negative != nil {
+		s.negative.Remove(order.OrderUID)
+	}
+	s.rememberStale(order)
+	if s.cache != nil {
+		s.cache.Set(order)
+	}
+	return nil
+}
+
+// Delete мягко удаляет заказ в БД и вытесняет его из кэшей, чтобы он
+// больше не отдавался при чтении
+func (s *OrderStore) Delete(ctx context.Context, uid string) error {
+	if s.db == nil {
+		return ErrNoDatabase
+	}
+	if err := s.db.SoftDeleteOrder(ctx, uid); err != nil {
+		return err
+	}
+
+	if s.cache != nil {
+		s.cache.Delete(uid)
+	}
+	s.forgetStale(uid)
+	return nil
+}
+
+// Undelete восстанавливает мягко удаленный заказ и возвращает его
+func (s *OrderStore) Undelete(ctx context.Context, uid string) (*model.Order, error) {
+	if s.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if err := s.db.UndeleteOrder(ctx, uid); err != nil {
+		return nil, err
+	}
+	return s.Refresh(ctx, uid)
+}
+
+// Stale возвращает последнюю известную версию заказа из резервного кэша.
+// Используется, когда БД недоступна; без StaleTTL всегда возвращает false.
+func (s *OrderStore) Stale(uid string) (*model.Order, bool) {
+	if s.stale == nil {
+		return nil, false
+	}
+	return s.stale.Get(uid)
+}
+
+// rememberStale запоминает актуальную версию заказа в резервном кэше
+func (s *OrderStore) rememberStale(order *model.Order) {
+	if s.stale != nil {
+		s.stale.Set(order)
+	}
+}
+
+// forgetStale удаляет из резервного кэша заказ, отсутствующий в БД
+func (s *OrderStore) forgetStale(uid string) {
+	if s.stale != nil {
+		s.stale.Remove(uid)
+	}
+}
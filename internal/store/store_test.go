@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-kafka-postgres/internal/cache"
+	"go-kafka-postgres/internal/db"
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+func TestGetFallsBackToDBAndFillsCache(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+	orderCache := newCountingCache()
+	s := New(orderCache, database, Options{})
+	ctx := context.Background()
+
+	got, err := s.Get(ctx, order.OrderUID)
+	if err != nil || got != order {
+		t.Fatalf("Get = %v, %v; want the order from DB", got, err)
+	}
+	if _, ok := orderCache.Peek(order.OrderUID); !ok {
+		t.Error("order was not cached after DB fallback")
+	}
+
+	got, err = s.Get(ctx, order.OrderUID)
+	if err != nil || got != order {
+		t.Fatalf("second Get = %v, %v; want the cached order", got, err)
+	}
+	if reads := database.Reads(); reads != 1 {
+		t.Errorf("DB read %d times, want 1: the second Get must be served from cache", reads)
+	}
+}
+
+func TestGetMissingOrder(t *testing.T) {
+	orderCache := newCountingCache()
+	s := New(orderCache, newFakeDB(), Options{})
+
+	if _, err := s.Get(context.Background(), "missing"); !errors.Is(err, db.ErrOrderNotFound) {
+		t.Errorf("Get = %v, want ErrOrderNotFound", err)
+	}
+	if orderCache.sets != 0 || orderCache.Size() != 0 {
+		t.Errorf("cache changed on a missing order: %d sets, size %d", orderCache.sets, orderCache.Size())
+	}
+}
+
+func TestGetDBError(t *testing.T) {
+	database := newFakeDB()
+	database.err = errors.New("connection refused")
+	s := New(newCountingCache(), database, Options{})
+
+	if _, err := s.Get(context.Background(), "any"); !errors.Is(err, database.err) {
+		t.Errorf("Get = %v, want the DB error", err)
+	}
+}
+
+func TestSaveWritesThrough(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB()
+	orderCache := newCountingCache()
+	s := New(orderCache, database, Options{})
+
+	if err := s.Save(context.Background(), order); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, ok := database.orders[order.OrderUID]; !ok {
+		t.Error("order was not written to DB")
+	}
+	if cached, ok := orderCache.Peek(order.OrderUID); !ok || cached != order {
+		t.Error("order was not cached after Save")
+	}
+}
+
+func TestSaveDBErrorSkipsCache(t *testing.T) {
+	database := newFakeDB()
+	database.err = errors.New("connection refused")
+	orderCache := newCountingCache()
+	s := New(orderCache, database, Options{})
+
+	if err := s.Save(context.Background(), testutil.Order("b563feb7b2b84b6test")); !errors.Is(err, database.err) {
+		t.Fatalf("Save = %v, want the DB error", err)
+	}
+	if orderCache.sets != 0 {
+		t.Errorf("cache Set called %d times after a failed write, want 0", orderCache.sets)
+	}
+}
+
+func TestSaveUpsert(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+	s := New(newCountingCache(), database, Options{Upsert: true})
+
+	updated := testutil.Order(order.OrderUID)
+	updated.Delivery.City = "Moscow"
+	if err := s.Save(context.Background(), updated); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if database.orders[order.OrderUID] != updated {
+		t.Error("upsert did not replace the stored order")
+	}
+}
+
+func TestGetNoFillOnMiss(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+	orderCache := newCountingCache()
+	s := New(orderCache, database, Options{NoFillOnMiss: true})
+	ctx := context.Background()
+
+	for range 2 {
+		if got, err := s.Get(ctx, order.OrderUID); err != nil || got != order {
+			t.Fatalf("Get = %v, %v; want the order from DB", got, err)
+		}
+	}
+	if orderCache.sets != 0 {
+		t.Errorf("cache Set called %d times on DB fallback, want 0", orderCache.sets)
+	}
+	if reads := database.Reads(); reads != 2 {
+		t.Errorf("DB read %d times, want 2: misses are not cached", reads)
+	}
+
+	// Заказы, записанные потребителем, по-прежнему попадают в кэш
+	written := testutil.Order("written")
+	if err := s.Save(ctx, written); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if orderCache.sets != 1 {
+		t.Errorf("cache Set called %d times after Save, want 1", orderCache.sets)
+	}
+}
+
+// failingCache кэш, чтение из которого завершается ошибкой, как у недоступного Redis
+type failingCache struct {
+	cache.Cache
+	err error
+}
+
+func (c failingCache) GetCtx(context.Context, string) (*model.Order, bool, error) {
+	return nil, false, c.err
+}
+
+func TestGetCanceledContext(t *testing.T) {
+	database := newFakeDB(testutil.Order("b563feb7b2b84b6test"))
+	s := New(newCountingCache(), database, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.Get(ctx, "b563feb7b2b84b6test"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get = %v, want context.Canceled", err)
+	}
+	if reads := database.Reads(); reads != 0 {
+		t.Errorf("DB read %d times after the context was canceled, want 0", reads)
+	}
+}
+
+func TestGetCacheErrorFallsBackToDB(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	database := newFakeDB(order)
+	s := New(failingCache{Cache: cache.New(10), err: errors.New("redis: connection refused")}, database, Options{})
+
+	got, err := s.Get(context.Background(), order.OrderUID)
+	if err != nil || got != order {
+		t.Fatalf("Get = %v, %v; want the order from DB", got, err)
+	}
+	if reads := database.Reads(); reads != 1 {
+		t.Errorf("DB read %d times, want 1", reads)
+	}
+}
@@ -0,0 +1,229 @@
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+)
+
+// DefaultAllowedSizes распространенные размеры товаров для строгого режима
+var DefaultAllowedSizes = []string{"0", "XS", "S", "M", "L", "XL", "XXL", "XXXL"}
+
+// DefaultAllowedCurrencies коды валют ISO-4217, допустимые в строгом режиме по умолчанию
+var DefaultAllowedCurrencies = []string{
+	"RUB", "USD", "EUR", "CNY", "KZT", "BYN", "UZS", "KGS", "AMD", "GEL",
+	"AZN", "TRY", "GBP", "CHF", "JPY", "AED",
+}
+
+// orderUIDPattern допустимый формат order_uid: латинские буквы и цифры
+// ограниченной длины (WB-идентификаторы имеют длину 19 символов)
+var orderUIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,64}$`)
+
+// ValidOrderUID проверяет формат order_uid
+func ValidOrderUID(uid string) bool {
+	return orderUIDPattern.MatchString(uid)
+}
+
+// trackNumberPattern допустимый формат трек-номера
+var trackNumberPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,255}$`)
+
+// ValidTrackNumber проверяет формат трек-номера
+func ValidTrackNumber(trackNumber string) bool {
+	return trackNumberPattern.MatchString(trackNumber)
+}
+
+// phonePattern номер телефона в формате, близком к E.164
+var phonePattern = regexp.MustCompile(`^\+?[1-9][0-9]{6,14}$`)
+
+// ValidationError ошибка валидации с машиночитаемым указанием поля,
+// не прошедшего проверку (например "track_number", "payment", "item.size")
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// fieldError создает ValidationError для поля с форматированным сообщением
+func fieldError(field, format string, args ...any) error {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// DefaultFutureSkew допустимое опережение date_created относительно текущего времени
+const DefaultFutureSkew = time.Minute
+
+// DefaultMaxItems максимальное число товаров в заказе по умолчанию
+const DefaultMaxItems = 1000
+
+// Options настройки валидации
+type Options struct {
+	// Strict включает дополнительные проверки формата данных
+	Strict bool
+	// AllowedSizes допустимые значения размера товара в строгом режиме
+	AllowedSizes []string
+	// AllowedCurrencies допустимые коды валют ISO-4217 в строгом режиме
+	AllowedCurrencies []string
+	// FutureSkew допустимое опережение date_created (расхождение часов продюсеров);
+	// 0 — без допуска, отрицательное значение означает DefaultFutureSkew
+	FutureSkew time.Duration
+	// MaxItems максимальное число товаров в заказе; 0 — без ограничения
+	MaxItems int
+	// MatchItemTrackNumbers требует, чтобы track_number каждого товара совпадал
+	// с track_number заказа; расхождение обычно означает ошибку продюсера
+	MatchItemTrackNumbers bool
+}
+
+// Validator проверяет заказы с заданными настройками
+type Validator struct {
+	opts              Options
+	allowedSizes      map[string]struct{}
+	allowedCurrencies map[string]struct{}
+}
+
+// New создает валидатор
+func New(opts Options) *Validator {
+	if opts.FutureSkew < 0 {
+		opts.FutureSkew = DefaultFutureSkew
+	}
+	v := &Validator{opts: opts, allowedSizes: make(map[string]struct{}, len(opts.AllowedSizes))}
+	for _, size := range opts.AllowedSizes {
+		v.allowedSizes[size] = struct{}{}
+	}
+	v.allowedCurrencies = make(map[string]struct{}, len(opts.AllowedCurrencies))
+	for _, currency := range opts.AllowedCurrencies {
+		v.allowedCurrencies[strings.ToUpper(currency)] = struct{}{}
+	}
+	return v
+}
+
+var defaultValidator = New(Options{FutureSkew: DefaultFutureSkew})
+
+// Validate проверяет заказ в нестрогом режиме
+func Validate(order *model.Order) error {
+	return defaultValidator.Validate(order)
+}
+
+// Validate проверяет обязательные поля и числовые значения заказа
+func (v *Validator) Validate(order *model.Order) error {
+	return v.validateAt(order, time.Now())
+}
+
+// validateAt проверяет заказ относительно момента now
+func (v *Validator) validateAt(order *model.Order, now time.Time) error {
+	if order.DateCreated.After(now.Add(v.opts.FutureSkew)) {
+		return fieldError("date_created", "date_created is in the future: %v", order.DateCreated)
+	}
+
+	if order.OrderUID == "" {
+		return fieldError("order_uid", "missing order_uid")
+	}
+	if !ValidOrderUID(order.OrderUID) {
+		return fieldError("order_uid", "invalid order_uid format: %q", order.OrderUID)
+	}
+	if order.TrackNumber == "" {
+		return fieldError("track_number", "missing track_number")
+	}
+	if order.Entry == "" {
+		return fieldError("entry", "missing entry")
+	}
+	if order.Locale == "" {
+		return fieldError("locale", "missing locale")
+	}
+	if order.CustomerID == "" {
+		return fieldError("customer_id", "missing customer_id")
+	}
+	if order.DeliveryService == "" {
+		return fieldError("delivery_service", "missing delivery_service")
+	}
+	if order.Shardkey == "" {
+		return fieldError("shardkey", "missing shardkey")
+	}
+	if order.OofShard == "" {
+		return fieldError("oof_shard", "missing oof_shard")
+	}
+
+	if order.Delivery.Name == "" || order.Delivery.Phone == "" || order.Delivery.Zip == "" ||
+		order.Delivery.City == "" || order.Delivery.Address == "" || order.Delivery.Region == "" ||
+		order.Delivery.Email == "" {
+		return fieldError("delivery", "missing fields in delivery")
+	}
+	if v.opts.Strict {
+		if addr, err := mail.ParseAddress(order.Delivery.Email); err != nil || addr.Address != order.Delivery.Email {
+			return fieldError("delivery.email", "invalid delivery email: %q", order.Delivery.Email)
+		}
+		if !phonePattern.MatchString(order.Delivery.Phone) {
+			return fieldError("delivery.phone", "invalid delivery phone: %q", order.Delivery.Phone)
+		}
+	}
+
+	if order.Payment.Transaction == "" || order.Payment.Currency == "" || order.Payment.Provider == "" ||
+		order.Payment.Bank == "" {
+		return fieldError("payment", "missing fields in payment")
+	}
+	if v.opts.Strict {
+		if _, ok := v.allowedCurrencies[strings.ToUpper(order.Payment.Currency)]; !ok {
+			return fieldError("payment.currency", "unknown payment currency: %q", order.Payment.Currency)
+		}
+	}
+	if order.Payment.Amount <= 0 || order.Payment.PaymentDt <= 0 || order.Payment.DeliveryCost < 0 ||
+		order.Payment.GoodsTotal <= 0 || order.Payment.CustomFee < 0 {
+		return fieldError("payment", "invalid numeric values in payment")
+	}
+
+	if len(order.Items) == 0 {
+		return fieldError("items", "no items")
+	}
+	if v.opts.MaxItems > 0 && len(order.Items) > v.opts.MaxItems {
+		return fieldError("items", "too many items: %d, at most %d allowed", len(order.Items), v.opts.MaxItems)
+	}
+	for i, item := range order.Items {
+		if item.ChrtID == 0 || item.TrackNumber == "" || item.Price <= 0 || item.Rid == "" ||
+			item.Name == "" || item.Sale < 0 || item.Size == "" || item.TotalPrice <= 0 ||
+			item.NmID == 0 || item.Brand == "" || item.Status <= 0 {
+			return fieldError("item", "missing/invalid fields in item #%d", i+1)
+		}
+		if v.opts.MatchItemTrackNumbers && item.TrackNumber != order.TrackNumber {
+			return fieldError("item.track_number", "track_number %q in item #%d does not match order track_number %q",
+				item.TrackNumber, i+1, order.TrackNumber)
+		}
+		if v.opts.Strict {
+			if _, ok := v.allowedSizes[item.Size]; !ok {
+				return fieldError("item.size", "invalid size %q in item #%d", item.Size, i+1)
+			}
+		}
+	}
+
+	if v.opts.Strict {
+		if err := validateTotals(order); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTotals проверяет согласованность сумм оплаты и товаров
+func validateTotals(order *model.Order) error {
+	expectedAmount := order.Payment.GoodsTotal + order.Payment.DeliveryCost
+	if order.Payment.Amount != expectedAmount {
+		return fieldError("payment.amount", "payment amount mismatch: expected %d (goods_total + delivery_cost), got %d",
+			expectedAmount, order.Payment.Amount)
+	}
+
+	itemsTotal := 0
+	for _, item := range order.Items {
+		itemsTotal += item.TotalPrice
+	}
+	if order.Payment.GoodsTotal != itemsTotal {
+		return fieldError("payment.goods_total", "goods_total mismatch: expected %d (sum of item total_price), got %d",
+			itemsTotal, order.Payment.GoodsTotal)
+	}
+
+	return nil
+}
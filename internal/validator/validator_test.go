@@ -0,0 +1,409 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-kafka-postgres/internal/model"
+	"go-kafka-postgres/internal/testutil"
+)
+
+// strictValidator валидатор строгого режима со списками по умолчанию
+func strictValidator() *Validator {
+	return New(Options{
+		Strict:            true,
+		AllowedSizes:      DefaultAllowedSizes,
+		AllowedCurrencies: DefaultAllowedCurrencies,
+	})
+}
+
+// wantField проверяет, что err — ValidationError для поля field
+func wantField(t *testing.T, err error, field string) {
+	t.Helper()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want *ValidationError for %q", err, field)
+	}
+	if validationErr.Field != field {
+		t.Errorf("field = %q, want %q (error: %v)", validationErr.Field, field, err)
+	}
+}
+
+func TestValidateSampleOrder(t *testing.T) {
+	order := testutil.Order("b563feb7b2b84b6test")
+	if err := Validate(order); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	if err := strictValidator().Validate(order); err != nil {
+		t.Errorf("strict Validate: %v", err)
+	}
+}
+
+func TestStrictItemSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		lenient bool
+		strict  bool
+	}{
+		{size: "0", lenient: true, strict: true},
+		{size: "M", lenient: true, strict: true},
+		{size: "XXXL", lenient: true, strict: true},
+		{size: "XLL", lenient: true, strict: false},
+		{size: "m", lenient: true, strict: false},
+		{size: "", lenient: false, strict: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			order.Items[0].Size = tt.size
+
+			if err := Validate(order); (err == nil) != tt.lenient {
+				t.Errorf("lenient Validate(%q) = %v, want valid %v", tt.size, err, tt.lenient)
+			}
+			err := strictValidator().Validate(order)
+			if (err == nil) != tt.strict {
+				t.Errorf("strict Validate(%q) = %v, want valid %v", tt.size, err, tt.strict)
+			}
+			if tt.size != "" && !tt.strict {
+				wantField(t, err, "item.size")
+			}
+		})
+	}
+}
+
+func TestStrictCustomAllowedSizes(t *testing.T) {
+	v := New(Options{Strict: true, AllowedSizes: []string{"42", "44"}, AllowedCurrencies: DefaultAllowedCurrencies})
+	order := testutil.Order("b563feb7b2b84b6test")
+	order.Items[0].Size = "42"
+
+	if err := v.Validate(order); err != nil {
+		t.Errorf("Validate with size 42: %v", err)
+	}
+	order.Items[0].Size = "0"
+	wantField(t, v.Validate(order), "item.size")
+}
+
+func TestStrictEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		{email: "test@gmail.com", valid: true},
+		{email: "first.last+tag@example.co.uk", valid: true},
+		{email: "test", valid: false},
+		{email: "test@", valid: false},
+		{email: "@gmail.com", valid: false},
+		{email: "Test <test@gmail.com>", valid: false},
+		{email: "test@gmail.com ", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			order.Delivery.Email = tt.email
+
+			if err := Validate(order); err != nil {
+				t.Errorf("lenient Validate(%q) = %v, want valid", tt.email, err)
+			}
+			err := strictValidator().Validate(order)
+			if (err == nil) != tt.valid {
+				t.Fatalf("strict Validate(%q) = %v, want valid %v", tt.email, err, tt.valid)
+			}
+			if !tt.valid {
+				wantField(t, err, "delivery.email")
+			}
+		})
+	}
+}
+
+func TestStrictPhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		valid bool
+	}{
+		{phone: "+9720000000", valid: true},
+		{phone: "+79161234567", valid: true},
+		{phone: "79161234567", valid: true},
+		{phone: "+123", valid: false},
+		{phone: "+0123456789", valid: false},
+		{phone: "+7 916 123-45-67", valid: false},
+		{phone: "+7916123456789012", valid: false},
+		{phone: "phone", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			order.Delivery.Phone = tt.phone
+
+			if err := Validate(order); err != nil {
+				t.Errorf("lenient Validate(%q) = %v, want valid", tt.phone, err)
+			}
+			err := strictValidator().Validate(order)
+			if (err == nil) != tt.valid {
+				t.Fatalf("strict Validate(%q) = %v, want valid %v", tt.phone, err, tt.valid)
+			}
+			if !tt.valid {
+				wantField(t, err, "delivery.phone")
+			}
+		})
+	}
+}
+
+func TestValidOrderUID(t *testing.T) {
+	tests := []struct {
+		uid   string
+		valid bool
+	}{
+		{uid: "b563feb7b2b84b6test", valid: true},
+		{uid: "ABC123", valid: true},
+		{uid: strings.Repeat("a", 64), valid: true},
+		{uid: "", valid: false},
+		{uid: strings.Repeat("a", 65), valid: false},
+		{uid: "b563feb7-b2b8", valid: false},
+		{uid: "b563 feb7", valid: false},
+		{uid: "../etc/passwd", valid: false},
+		{uid: "заказ", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			if got := ValidOrderUID(tt.uid); got != tt.valid {
+				t.Errorf("ValidOrderUID(%q) = %v, want %v", tt.uid, got, tt.valid)
+			}
+
+			order := testutil.Order(tt.uid)
+			err := Validate(order)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Validate with order_uid %q = %v, want valid %v", tt.uid, err, tt.valid)
+			}
+			if !tt.valid {
+				wantField(t, err, "order_uid")
+			}
+		})
+	}
+}
+
+func TestStrictTotals(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(order *model.Order)
+		wantField   string
+		wantMessage string
+	}{
+		{name: "matching", modify: func(*model.Order) {}},
+		{
+			name: "matching with several items",
+			modify: func(order *model.Order) {
+				item := order.Items[0]
+				item.ChrtID++
+				item.TotalPrice = 100
+				order.Items = append(order.Items, item)
+				order.Payment.GoodsTotal = 417
+				order.Payment.Amount = 1917
+			},
+		},
+		{
+			name:        "amount mismatch",
+			modify:      func(order *model.Order) { order.Payment.Amount = 1800 },
+			wantField:   "payment.amount",
+			wantMessage: "payment amount mismatch: expected 1817 (goods_total + delivery_cost), got 1800",
+		},
+		{
+			name: "goods total mismatch",
+			modify: func(order *model.Order) {
+				order.Payment.GoodsTotal = 300
+				order.Payment.Amount = 1800
+			},
+			wantField:   "payment.goods_total",
+			wantMessage: "goods_total mismatch: expected 317 (sum of item total_price), got 300",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			tt.modify(order)
+
+			if err := Validate(order); err != nil {
+				t.Errorf("lenient Validate = %v, want valid", err)
+			}
+			err := strictValidator().Validate(order)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("strict Validate = %v, want valid", err)
+				}
+				return
+			}
+			wantField(t, err, tt.wantField)
+			if err.Error() != tt.wantMessage {
+				t.Errorf("error = %q, want %q", err, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestFutureSkew(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		skew    time.Duration
+		created time.Time
+		wantErr bool
+	}{
+		{name: "past date", skew: time.Minute, created: now.Add(-time.Hour)},
+		{name: "at tolerance", skew: time.Minute, created: now.Add(time.Minute)},
+		{name: "just over tolerance", skew: time.Minute, created: now.Add(time.Minute + time.Nanosecond), wantErr: true},
+		{name: "zero tolerance at now", skew: 0, created: now},
+		{name: "zero tolerance just after now", skew: 0, created: now.Add(time.Nanosecond), wantErr: true},
+		{name: "negative means default", skew: -1, created: now.Add(DefaultFutureSkew)},
+		{name: "negative means default, over it", skew: -1, created: now.Add(DefaultFutureSkew + time.Nanosecond), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			// NewTimestamp округляет до микросекунд, поэтому время задается напрямую
+			order.DateCreated.Time = tt.created
+			err := New(Options{FutureSkew: tt.skew}).validateAt(order, now)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("validateAt: %v, want no error", err)
+				}
+				return
+			}
+			wantField(t, err, "date_created")
+		})
+	}
+}
+
+func TestStrictCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		valid    bool
+	}{
+		{currency: "USD", valid: true},
+		{currency: "rub", valid: true},
+		{currency: "Eur", valid: true},
+		{currency: "US$"},
+		{currency: "XXX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			order.Payment.Currency = tt.currency
+			err := strictValidator().Validate(order)
+			if tt.valid {
+				if err != nil {
+					t.Errorf("Validate: %v, want no error", err)
+				}
+			} else {
+				wantField(t, err, "payment.currency")
+			}
+
+			// Нестрогий режим проверяет только наличие валюты
+			if err := Validate(order); err != nil {
+				t.Errorf("lenient Validate: %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestStrictCustomCurrencies(t *testing.T) {
+	v := New(Options{Strict: true, AllowedSizes: DefaultAllowedSizes, AllowedCurrencies: []string{"kzt"}})
+	order := testutil.Order("b563feb7b2b84b6test")
+
+	order.Payment.Currency = "KZT"
+	if err := v.Validate(order); err != nil {
+		t.Errorf("Validate with configured currency: %v", err)
+	}
+	order.Payment.Currency = "USD"
+	wantField(t, v.Validate(order), "payment.currency")
+}
+
+func TestMaxItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxItems int
+		items    int
+		wantErr  bool
+	}{
+		{name: "below limit", maxItems: 3, items: 2},
+		{name: "at limit", maxItems: 3, items: 3},
+		{name: "over limit", maxItems: 3, items: 4, wantErr: true},
+		{name: "unlimited", maxItems: 0, items: 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			item := order.Items[0]
+			order.Items = make([]model.Item, tt.items)
+			for i := range order.Items {
+				order.Items[i] = item
+				order.Items[i].ChrtID = item.ChrtID + i
+			}
+
+			err := New(Options{MaxItems: tt.maxItems}).Validate(order)
+			if !tt.wantErr {
+				// Суммы оплаты не сходятся с товарами, но в нестрогом режиме это не проверяется
+				if err != nil {
+					t.Errorf("Validate: %v, want no error", err)
+				}
+				return
+			}
+			wantField(t, err, "items")
+			if !strings.Contains(err.Error(), "at most 3") {
+				t.Errorf("error %q does not name the limit", err)
+			}
+		})
+	}
+}
+
+func TestMatchItemTrackNumbers(t *testing.T) {
+	tests := []struct {
+		name         string
+		match        bool
+		trackNumbers []string
+		wantErr      bool
+		wantInErr    []string
+	}{
+		{name: "matching items", match: true, trackNumbers: []string{"WBILMTESTTRACK", "WBILMTESTTRACK"}},
+		{name: "mismatch allowed when off", trackNumbers: []string{"WBILMTESTTRACK", "WBILMOTHER"}},
+		{name: "second item mismatch", match: true, trackNumbers: []string{"WBILMTESTTRACK", "WBILMOTHER"}, wantErr: true,
+			wantInErr: []string{"item #2", `"WBILMOTHER"`, `"WBILMTESTTRACK"`}},
+		{name: "first item mismatch", match: true, trackNumbers: []string{"WBILMOTHER", "WBILMTESTTRACK"}, wantErr: true,
+			wantInErr: []string{"item #1", `"WBILMOTHER"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testutil.Order("b563feb7b2b84b6test")
+			item := order.Items[0]
+			order.Items = nil
+			for i, trackNumber := range tt.trackNumbers {
+				item.ChrtID += i
+				item.TrackNumber = trackNumber
+				order.Items = append(order.Items, item)
+			}
+
+			err := New(Options{MatchItemTrackNumbers: tt.match}).Validate(order)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Validate: %v, want no error", err)
+				}
+				return
+			}
+			wantField(t, err, "item.track_number")
+			for _, want := range tt.wantInErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %s", err, want)
+				}
+			}
+		})
+	}
+}
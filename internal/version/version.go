@@ -0,0 +1,30 @@
+package version
+
+import "runtime"
+
+// Значения подставляются при сборке:
+//
+//	go build -ldflags "-X go-kafka-postgres/internal/version.Commit=$(git rev-parse --short HEAD) \
+//		-X go-kafka-postgres/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	// Commit git-коммит, из которого собран бинарник
+	Commit = "unknown"
+	// BuildTime время сборки
+	BuildTime = "unknown"
+)
+
+// Info сведения о сборке
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает сведения о текущей сборке
+func Get() Info {
+	return Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
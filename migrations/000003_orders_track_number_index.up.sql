@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_orders_track_number ON orders(track_number);
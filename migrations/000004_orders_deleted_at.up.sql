@@ -0,0 +1,2 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_orders_not_deleted ON orders(date_created, order_uid) WHERE deleted_at IS NULL;
@@ -0,0 +1,9 @@
+// Package web содержит статические файлы веб-интерфейса
+package web
+
+import "embed"
+
+// FS встроенные в бинарник файлы веб-интерфейса
+//
+//go:embed index.html
+var FS embed.FS